@@ -12,3 +12,10 @@ func requireFlag[T comparable](name string, value T) {
 		os.Exit(1)
 	}
 }
+
+func checkFlag(name string, ok bool, message string) {
+	if !ok {
+		fmt.Printf("Error: -%s flag %s\n", name, message)
+		os.Exit(1)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -8,8 +9,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/handler"
+	"github.com/Quak1/gokei/internal/scheduler"
 	"github.com/Quak1/gokei/internal/service"
 )
 
@@ -18,6 +21,14 @@ type config struct {
 	db   struct {
 		dsn string
 	}
+	attachments struct {
+		dir string
+	}
+	scheduler struct {
+		enabled  bool
+		interval time.Duration
+		backfill time.Duration
+	}
 }
 
 type application struct {
@@ -29,9 +40,15 @@ func main() {
 
 	flag.IntVar(&cfg.port, "port", 4444, "Server port")
 	flag.StringVar(&cfg.db.dsn, "dsn", os.Getenv("GOKEI_DB_DSN"), "PostgreSQL DSN")
+	flag.StringVar(&cfg.attachments.dir, "attachments-dir", "data/attachments", "Directory where transaction attachments are stored")
+	flag.BoolVar(&cfg.scheduler.enabled, "scheduler", true, "Post due recurring transactions in the background")
+	flag.DurationVar(&cfg.scheduler.interval, "scheduler-interval", time.Hour, "Interval between recurring transaction runs")
+	flag.DurationVar(&cfg.scheduler.backfill, "scheduler-backfill", 30*24*time.Hour, "How far back missed recurring transactions are posted (0 disables catch-up)")
 	flag.Parse()
 
 	requireFlag("dsn", cfg.db.dsn)
+	checkFlag("scheduler-interval", cfg.scheduler.interval > 0, "must be greater than zero")
+	checkFlag("scheduler-backfill", cfg.scheduler.backfill >= 0, "must not be negative")
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
@@ -42,9 +59,13 @@ func main() {
 	}
 	defer db.Connection.Close()
 
-	svc := service.New(db)
+	svc := service.New(db, blob.NewLocalStore(cfg.attachments.dir))
 	h := handler.New(svc, logger)
 
+	if cfg.scheduler.enabled {
+		scheduler.New(svc.Recurring, logger, cfg.scheduler.interval, cfg.scheduler.backfill).Start(context.Background())
+	}
+
 	app := application{
 		handler: h,
 	}
@@ -22,10 +22,18 @@ func (app *application) routes(s *service.Service) http.Handler {
 
 	mux.Handle("GET /v1/categories", mw.Authenticate(http.HandlerFunc(app.handler.Category.GetAll)))
 	mux.Handle("POST /v1/categories", mw.Authenticate(http.HandlerFunc(app.handler.Category.Create)))
+	mux.Handle("GET /v1/categories/report", mw.Authenticate(http.HandlerFunc(app.handler.Category.Report)))
 	mux.Handle("GET /v1/categories/{categoryID}", mw.Authenticate(http.HandlerFunc(app.handler.Category.GetByID)))
 	mux.Handle("PUT /v1/categories/{categoryID}", mw.Authenticate(http.HandlerFunc(app.handler.Category.UpdateByID)))
 	mux.Handle("DELETE /v1/categories/{categoryID}", mw.Authenticate(http.HandlerFunc(app.handler.Category.DeleteByID)))
 
+	mux.Handle("GET /v1/tags", mw.Authenticate(http.HandlerFunc(app.handler.Tag.GetAll)))
+	mux.Handle("POST /v1/tags", mw.Authenticate(http.HandlerFunc(app.handler.Tag.Create)))
+	mux.Handle("GET /v1/tags/report", mw.Authenticate(http.HandlerFunc(app.handler.Tag.Report)))
+	mux.Handle("GET /v1/tags/{tagID}", mw.Authenticate(http.HandlerFunc(app.handler.Tag.GetByID)))
+	mux.Handle("PUT /v1/tags/{tagID}", mw.Authenticate(http.HandlerFunc(app.handler.Tag.UpdateByID)))
+	mux.Handle("DELETE /v1/tags/{tagID}", mw.Authenticate(http.HandlerFunc(app.handler.Tag.DeleteByID)))
+
 	mux.Handle("GET /v1/accounts", mw.Authenticate(http.HandlerFunc(app.handler.Account.GetAll)))
 	mux.Handle("POST /v1/accounts", mw.Authenticate(http.HandlerFunc(app.handler.Account.Create)))
 	mux.Handle("GET /v1/accounts/{accountID}", mw.Authenticate(http.HandlerFunc(app.handler.Account.GetByID)))
@@ -34,13 +42,62 @@ func (app *application) routes(s *service.Service) http.Handler {
 	mux.Handle("PUT /v1/accounts/{accountID}", mw.Authenticate(http.HandlerFunc(app.handler.Account.UpdateByID)))
 	mux.Handle("DELETE /v1/accounts/{accountID}", mw.Authenticate(http.HandlerFunc(app.handler.Account.DeleteByID)))
 	mux.Handle("POST /v1/accounts/{accountID}/transfer", mw.Authenticate(http.HandlerFunc(app.handler.Account.TransferByID)))
+	mux.Handle("GET /v1/accounts/{accountID}/reconciliations", mw.Authenticate(http.HandlerFunc(app.handler.Reconciliation.GetAll)))
+	mux.Handle("POST /v1/accounts/{accountID}/reconciliations", mw.Authenticate(http.HandlerFunc(app.handler.Reconciliation.Create)))
+	mux.Handle("GET /v1/accounts/{accountID}/reconciliations/{reconciliationID}", mw.Authenticate(http.HandlerFunc(app.handler.Reconciliation.GetByID)))
+	mux.Handle("DELETE /v1/accounts/{accountID}/reconciliations/{reconciliationID}", mw.Authenticate(http.HandlerFunc(app.handler.Reconciliation.DeleteByID)))
+	mux.Handle("POST /v1/accounts/{accountID}/import/csv", mw.Authenticate(http.HandlerFunc(app.handler.Import.ImportCSV)))
+	mux.Handle("POST /v1/accounts/{accountID}/import/ofx", mw.Authenticate(http.HandlerFunc(app.handler.Import.ImportOFX)))
+	mux.Handle("POST /v1/accounts/{accountID}/import/qif", mw.Authenticate(http.HandlerFunc(app.handler.Import.ImportQIF)))
+	mux.Handle("POST /v1/accounts/{accountID}/import/camt053", mw.Authenticate(http.HandlerFunc(app.handler.Import.ImportCAMT053)))
+	mux.Handle("POST /v1/accounts/{accountID}/import/mt940", mw.Authenticate(http.HandlerFunc(app.handler.Import.ImportMT940)))
+	mux.Handle("GET /v1/accounts/{accountID}/export/qif", mw.Authenticate(http.HandlerFunc(app.handler.Export.QIF)))
+	mux.Handle("GET /v1/accounts/forecast", mw.Authenticate(http.HandlerFunc(app.handler.Forecast.ForUser)))
+	mux.Handle("GET /v1/accounts/{accountID}/forecast", mw.Authenticate(http.HandlerFunc(app.handler.Forecast.ForAccount)))
+
+	mux.Handle("GET /v1/import-profiles", mw.Authenticate(http.HandlerFunc(app.handler.Import.GetProfiles)))
+	mux.Handle("POST /v1/import-profiles", mw.Authenticate(http.HandlerFunc(app.handler.Import.CreateProfile)))
+	mux.Handle("GET /v1/import-profiles/{profileID}", mw.Authenticate(http.HandlerFunc(app.handler.Import.GetProfileByID)))
+	mux.Handle("PUT /v1/import-profiles/{profileID}", mw.Authenticate(http.HandlerFunc(app.handler.Import.UpdateProfileByID)))
+	mux.Handle("DELETE /v1/import-profiles/{profileID}", mw.Authenticate(http.HandlerFunc(app.handler.Import.DeleteProfileByID)))
 
 	mux.Handle("GET /v1/transactions", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.GetAll)))
 	mux.Handle("POST /v1/transactions", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.Create)))
+	mux.Handle("POST /v1/transactions/bulk", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.Bulk)))
+	mux.Handle("GET /v1/transactions/search", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.Search)))
 	mux.Handle("GET /v1/transactions/{transactionID}", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.GetByID)))
 	mux.Handle("PUT /v1/transactions/{transactionID}", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.UpdateByID)))
 	mux.Handle("DELETE /v1/transactions/{transactionID}", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.DeleteByID)))
 	mux.Handle("POST /v1/transactions/{transactionID}/refund", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.RefundByID)))
+	mux.Handle("POST /v1/transactions/{transactionID}/unlock", mw.Authenticate(http.HandlerFunc(app.handler.Transaction.UnlockByID)))
+	mux.Handle("GET /v1/transactions/{transactionID}/attachments", mw.Authenticate(http.HandlerFunc(app.handler.Attachment.GetAll)))
+	mux.Handle("POST /v1/transactions/{transactionID}/attachments", mw.Authenticate(http.HandlerFunc(app.handler.Attachment.Create)))
+	mux.Handle("GET /v1/transactions/{transactionID}/attachments/{attachmentID}", mw.Authenticate(http.HandlerFunc(app.handler.Attachment.Download)))
+	mux.Handle("DELETE /v1/transactions/{transactionID}/attachments/{attachmentID}", mw.Authenticate(http.HandlerFunc(app.handler.Attachment.DeleteByID)))
+
+	mux.Handle("GET /v1/transfers", mw.Authenticate(http.HandlerFunc(app.handler.Transfer.GetAll)))
+	mux.Handle("POST /v1/transfers", mw.Authenticate(http.HandlerFunc(app.handler.Transfer.Create)))
+	mux.Handle("GET /v1/transfers/{transferID}", mw.Authenticate(http.HandlerFunc(app.handler.Transfer.GetByID)))
+	mux.Handle("PUT /v1/transfers/{transferID}", mw.Authenticate(http.HandlerFunc(app.handler.Transfer.UpdateByID)))
+	mux.Handle("DELETE /v1/transfers/{transferID}", mw.Authenticate(http.HandlerFunc(app.handler.Transfer.DeleteByID)))
+
+	mux.Handle("GET /v1/recurring-transactions", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.GetAll)))
+	mux.Handle("POST /v1/recurring-transactions", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.Create)))
+	mux.Handle("GET /v1/recurring-transactions/{recurringTransactionID}", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.GetByID)))
+	mux.Handle("GET /v1/recurring-transactions/{recurringTransactionID}/preview", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.Preview)))
+	mux.Handle("PUT /v1/recurring-transactions/{recurringTransactionID}", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.UpdateByID)))
+	mux.Handle("DELETE /v1/recurring-transactions/{recurringTransactionID}", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.DeleteByID)))
+	mux.Handle("POST /v1/recurring-transactions/{recurringTransactionID}/pause", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.PauseByID)))
+	mux.Handle("POST /v1/recurring-transactions/{recurringTransactionID}/resume", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.ResumeByID)))
+	mux.Handle("GET /v1/recurring-transactions/{recurringTransactionID}/exceptions", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.GetExceptions)))
+	mux.Handle("POST /v1/recurring-transactions/{recurringTransactionID}/exceptions", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.CreateException)))
+	mux.Handle("DELETE /v1/recurring-transactions/{recurringTransactionID}/exceptions/{exceptionID}", mw.Authenticate(http.HandlerFunc(app.handler.Recurring.DeleteException)))
+
+	mux.Handle("GET /v1/export", mw.Authenticate(http.HandlerFunc(app.handler.Export.Export)))
+	mux.Handle("POST /v1/import/gokei", mw.Authenticate(http.HandlerFunc(app.handler.Import.ImportGokei)))
+
+	mux.Handle("POST /v1/calendar/tokens", mw.Authenticate(http.HandlerFunc(app.handler.Calendar.CreateToken)))
+	mux.HandleFunc("GET /v1/calendar/{token}/recurring.ics", app.handler.Calendar.Feed)
 
 	return mux
 }
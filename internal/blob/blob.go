@@ -0,0 +1,25 @@
+// Package blob stores files, such as transaction attachments, outside the
+// database. Files are written once under a key chosen by the caller and are
+// never changed afterwards.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store is where files are kept. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Put stores the content of r under key, replacing any file already there.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the file stored under key. It returns ErrNotFound when there
+	// is none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key. Deleting a missing file is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,64 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir())
+
+	err := store.Put(ctx, "abcdef", strings.NewReader("receipt"))
+	assert.NilError(t, err)
+
+	r, err := store.Get(ctx, "abcdef")
+	assert.NilError(t, err)
+	if r != nil {
+		content, err := io.ReadAll(r)
+		assert.NilError(t, err)
+		assert.Equal(t, string(content), "receipt")
+		r.Close()
+	}
+
+	err = store.Put(ctx, "abcdef", strings.NewReader("replaced"))
+	assert.NilError(t, err)
+
+	r, err = store.Get(ctx, "abcdef")
+	assert.NilError(t, err)
+	if r != nil {
+		content, err := io.ReadAll(r)
+		assert.NilError(t, err)
+		assert.Equal(t, string(content), "replaced")
+		r.Close()
+	}
+
+	assert.NilError(t, store.Delete(ctx, "abcdef"))
+	assert.NilError(t, store.Delete(ctx, "abcdef"))
+
+	_, err = store.Get(ctx, "abcdef")
+	assert.Equal(t, errors.Is(err, ErrNotFound), true)
+}
+
+func TestLocalStore_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir())
+
+	for _, key := range []string{"", "ab", "../etc/passwd", `ab\cd`, "abc.def"} {
+		t.Run(key, func(t *testing.T) {
+			err := store.Put(ctx, key, strings.NewReader("x"))
+			assert.HasError(t, err)
+
+			_, err = store.Get(ctx, key)
+			assert.HasError(t, err)
+
+			err = store.Delete(ctx, key)
+			assert.HasError(t, err)
+		})
+	}
+}
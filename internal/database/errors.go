@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Quak1/gokei/pkg/validator"
 	"github.com/lib/pq"
 )
 
@@ -14,15 +15,16 @@ var (
 	ErrInvalidCategory       = errors.New("This category does not exist")
 	ErrInvalidAccount        = errors.New("This account does not exist")
 	ErrInvalidUser           = errors.New("This user does not exist")
+	ErrCategoryInUse         = errors.New("This category is used by split transactions")
 )
 
 func HandleForeignKeyError(err error) error {
 	if pqErr, ok := err.(*pq.Error); ok {
 		if pqErr.Code == "23503" {
 			switch pqErr.Constraint {
-			case "transactions_category_id_fkey":
+			case "transactions_category_id_fkey", "recurring_transactions_category_id_fkey", "transaction_splits_category_id_fkey":
 				return ErrInvalidCategory
-			case "transactions_account_id_fkey":
+			case "transactions_account_id_fkey", "recurring_transactions_account_id_fkey":
 				return ErrInvalidAccount
 			case "categories_user_id_fkey":
 				return ErrInvalidUser
@@ -35,6 +37,39 @@ func HandleForeignKeyError(err error) error {
 	return err
 }
 
+// HandleCheckConstraintError turns a CHECK constraint violation into a
+// validation error for the field the constraint guards.
+func HandleCheckConstraintError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		if pqErr.Code == "23514" {
+			v := validator.NewValidationError()
+			switch pqErr.Constraint {
+			case "valid_interval":
+				v.Add("interval", "Must be greater than zero")
+			case "valid_day_of_month":
+				v.Add("day_month", "Must be between 1 and 31")
+			case "valid_day_of_week":
+				v.Add("day_week", "Must be between 0 (Sunday) and 6 (Saturday)")
+			case "valid_exception":
+				v.Add("skip", "Must be set when neither new_date nor amount_cents are provided")
+			default:
+				return fmt.Errorf("check constraint violated: %s", pqErr.Constraint)
+			}
+			return v
+		}
+	}
+
+	return err
+}
+
+func IsForeignKeyViolation(err error, constraint string) bool {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code == "23503" && pqErr.Constraint == constraint
+	}
+
+	return false
+}
+
 func IsUniqueContraintViolation(err error) bool {
 	if pqErr, ok := err.(*pq.Error); ok {
 		return pqErr.Code == "23505"
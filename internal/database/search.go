@@ -0,0 +1,23 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PrefixTSQuery turns free text into a to_tsquery expression that matches
+// documents containing every word of text, either whole or as a prefix.
+// Anything that isn't a letter or digit separates words, so the result never
+// contains tsquery operators from the input. It returns "" if text has no
+// words.
+func PrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = strings.ToLower(word) + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Single word", "amazon", "amazon:*"},
+		{"Several words", "Amazon  Order", "amazon:* & order:*"},
+		{"Operators are separators", "amaz!|(order)&:*", "amaz:* & order:*"},
+		{"Digits and accents", "café 2025", "café:* & 2025:*"},
+		{"No words", " !& ", ""},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, PrefixTSQuery(tt.text), tt.expected)
+		})
+	}
+}
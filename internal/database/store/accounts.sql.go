@@ -12,14 +12,18 @@ import (
 
 const autoUpdateBalance = `-- name: AutoUpdateBalance :execrows
 WITH new_balance AS (
-  SELECT accounts.id, SUM(transactions.amount_cents) AS balance
+  SELECT accounts.id,
+    SUM(transactions.amount_cents) AS balance,
+    SUM(transactions.amount_cents) FILTER (WHERE transactions.status <> 'pending') AS cleared_balance
   FROM accounts
   LEFT JOIN transactions ON transactions.account_id = accounts.id
   WHERE accounts.id = $1 AND accounts.user_id = $2
   GROUP BY accounts.id
 )
 UPDATE accounts
-SET balance_cents = new_balance.balance, updated_at = NOW()
+SET balance_cents = new_balance.balance,
+    cleared_balance_cents = COALESCE(new_balance.cleared_balance, 0),
+    updated_at = NOW()
 FROM new_balance
 WHERE accounts.id = $1 AND accounts.user_id = $2
 `
@@ -38,9 +42,9 @@ func (q *Queries) AutoUpdateBalance(ctx context.Context, arg AutoUpdateBalancePa
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (type, name, user_id, balance_cents) 
-VALUES ($1, $2, $3, $4)
-RETURNING id, created_at, updated_at, type, name, balance_cents, version, user_id
+INSERT INTO accounts (type, name, user_id, balance_cents, cleared_balance_cents) 
+VALUES ($1, $2, $3, $4, $4)
+RETURNING id, created_at, updated_at, type, name, balance_cents, version, user_id, cleared_balance_cents
 `
 
 type CreateAccountParams struct {
@@ -67,6 +71,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.BalanceCents,
 		&i.Version,
 		&i.UserID,
+		&i.ClearedBalanceCents,
 	)
 	return i, err
 }
@@ -86,7 +91,7 @@ func (q *Queries) DeleteAccountById(ctx context.Context, arg DeleteAccountByIdPa
 }
 
 const getAccountByID = `-- name: GetAccountByID :one
-SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id FROM accounts
+SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id, cleared_balance_cents FROM accounts
 WHERE id = $1 AND user_id = $2
 `
 
@@ -107,6 +112,35 @@ func (q *Queries) GetAccountByID(ctx context.Context, arg GetAccountByIDParams)
 		&i.BalanceCents,
 		&i.Version,
 		&i.UserID,
+		&i.ClearedBalanceCents,
+	)
+	return i, err
+}
+
+const getAccountByIDForUpdate = `-- name: GetAccountByIDForUpdate :one
+SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id, cleared_balance_cents FROM accounts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE
+`
+
+type GetAccountByIDForUpdateParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) GetAccountByIDForUpdate(ctx context.Context, arg GetAccountByIDForUpdateParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByIDForUpdate, arg.ID, arg.UserID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.Name,
+		&i.BalanceCents,
+		&i.Version,
+		&i.UserID,
+		&i.ClearedBalanceCents,
 	)
 	return i, err
 }
@@ -137,7 +171,7 @@ func (q *Queries) GetAccountSumBalance(ctx context.Context, arg GetAccountSumBal
 }
 
 const getAllAccounts = `-- name: GetAllAccounts :many
-SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id FROM accounts
+SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id, cleared_balance_cents FROM accounts
 `
 
 func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
@@ -158,6 +192,7 @@ func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
 			&i.BalanceCents,
 			&i.Version,
 			&i.UserID,
+			&i.ClearedBalanceCents,
 		); err != nil {
 			return nil, err
 		}
@@ -173,7 +208,7 @@ func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
 }
 
 const getUserAccounts = `-- name: GetUserAccounts :many
-SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id FROM accounts
+SELECT id, created_at, updated_at, type, name, balance_cents, version, user_id, cleared_balance_cents FROM accounts
 WHERE user_id = $1
 `
 
@@ -195,6 +230,7 @@ func (q *Queries) GetUserAccounts(ctx context.Context, userID int32) ([]Account,
 			&i.BalanceCents,
 			&i.Version,
 			&i.UserID,
+			&i.ClearedBalanceCents,
 		); err != nil {
 			return nil, err
 		}
@@ -252,3 +288,23 @@ func (q *Queries) UpdateBalance(ctx context.Context, arg UpdateBalanceParams) (i
 	err := row.Scan(&balance_cents)
 	return balance_cents, err
 }
+
+const updateClearedBalance = `-- name: UpdateClearedBalance :one
+UPDATE accounts
+SET cleared_balance_cents = cleared_balance_cents + $1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+RETURNING cleared_balance_cents
+`
+
+type UpdateClearedBalanceParams struct {
+	ClearedBalanceCents int64 `json:"cleared_balance_cents"`
+	ID                  int32 `json:"id"`
+	UserID              int32 `json:"user_id"`
+}
+
+func (q *Queries) UpdateClearedBalance(ctx context.Context, arg UpdateClearedBalanceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, updateClearedBalance, arg.ClearedBalanceCents, arg.ID, arg.UserID)
+	var cleared_balance_cents int64
+	err := row.Scan(&cleared_balance_cents)
+	return cleared_balance_cents, err
+}
@@ -152,6 +152,87 @@ func (q *Queries) GetCategoryByName(ctx context.Context, arg GetCategoryByNamePa
 	return i, err
 }
 
+const getCategoryReport = `-- name: GetCategoryReport :many
+WITH lines AS (
+  SELECT transactions.category_id, transactions.amount_cents FROM transactions
+  INNER JOIN accounts ON transactions.account_id = accounts.id
+  WHERE accounts.user_id = $1
+    AND ($2::timestamp IS NULL OR transactions.date >= $2::timestamp)
+    AND ($3::timestamp IS NULL OR transactions.date < $3::timestamp)
+    AND NOT EXISTS (
+      SELECT 1 FROM transaction_splits WHERE transaction_splits.transaction_id = transactions.id
+    )
+  UNION ALL
+  SELECT transaction_splits.category_id, transaction_splits.amount_cents FROM transaction_splits
+  INNER JOIN transactions ON transaction_splits.transaction_id = transactions.id
+  INNER JOIN accounts ON transactions.account_id = accounts.id
+  WHERE accounts.user_id = $1
+    AND ($2::timestamp IS NULL OR transactions.date >= $2::timestamp)
+    AND ($3::timestamp IS NULL OR transactions.date < $3::timestamp)
+)
+SELECT categories.id AS category_id, categories.name,
+  COUNT(*) AS count,
+  COALESCE(SUM(lines.amount_cents) FILTER (WHERE lines.amount_cents > 0), 0)::bigint AS income_cents,
+  COALESCE(SUM(lines.amount_cents) FILTER (WHERE lines.amount_cents < 0), 0)::bigint AS expense_cents,
+  SUM(lines.amount_cents)::bigint AS total_cents
+FROM lines
+INNER JOIN categories ON lines.category_id = categories.id
+WHERE categories.id <> $4
+GROUP BY categories.id
+ORDER BY categories.name, categories.id
+`
+
+type GetCategoryReportParams struct {
+	UserID            int32        `json:"user_id"`
+	DateFrom          sql.NullTime `json:"date_from"`
+	DateTo            sql.NullTime `json:"date_to"`
+	InitialCategoryID int32        `json:"initial_category_id"`
+}
+
+type GetCategoryReportRow struct {
+	CategoryID   int32  `json:"category_id"`
+	Name         string `json:"name"`
+	Count        int64  `json:"count"`
+	IncomeCents  int64  `json:"income_cents"`
+	ExpenseCents int64  `json:"expense_cents"`
+	TotalCents   int64  `json:"total_cents"`
+}
+
+func (q *Queries) GetCategoryReport(ctx context.Context, arg GetCategoryReportParams) ([]GetCategoryReportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoryReport,
+		arg.UserID,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.InitialCategoryID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCategoryReportRow
+	for rows.Next() {
+		var i GetCategoryReportRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Name,
+			&i.Count,
+			&i.IncomeCents,
+			&i.ExpenseCents,
+			&i.TotalCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateCategoryById = `-- name: UpdateCategoryById :execresult
 UPDATE categories
 SET name = $1, color = $2, icon = $3, version = version + 1, updated_at = NOW()
@@ -5,7 +5,6 @@
 package store
 
 import (
-	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"time"
@@ -98,15 +97,59 @@ func (ns NullRecurrenceFrequency) Value() (driver.Value, error) {
 	return string(ns.RecurrenceFrequency), nil
 }
 
+type TransactionStatus string
+
+const (
+	TransactionStatusPending    TransactionStatus = "pending"
+	TransactionStatusCleared    TransactionStatus = "cleared"
+	TransactionStatusReconciled TransactionStatus = "reconciled"
+)
+
+func (e *TransactionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TransactionStatus(s)
+	case string:
+		*e = TransactionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TransactionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTransactionStatus struct {
+	TransactionStatus TransactionStatus `json:"transaction_status"`
+	Valid             bool              `json:"valid"` // Valid is true if TransactionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTransactionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TransactionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TransactionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTransactionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TransactionStatus), nil
+}
+
 type Account struct {
-	ID           int32       `json:"id"`
-	CreatedAt    time.Time   `json:"-"`
-	UpdatedAt    time.Time   `json:"-"`
-	Type         AccountType `json:"type"`
-	Name         string      `json:"name"`
-	BalanceCents int64       `json:"balance_cents"`
-	Version      int32       `json:"-"`
-	UserID       int32       `json:"user_id"`
+	ID                  int32       `json:"id"`
+	CreatedAt           time.Time   `json:"-"`
+	UpdatedAt           time.Time   `json:"-"`
+	Type                AccountType `json:"type"`
+	Name                string      `json:"name"`
+	BalanceCents        int64       `json:"balance_cents"`
+	Version             int32       `json:"-"`
+	UserID              int32       `json:"user_id"`
+	ClearedBalanceCents int64       `json:"cleared_balance_cents"`
 }
 
 type Category struct {
@@ -120,6 +163,50 @@ type Category struct {
 	UserID    int32     `json:"user_id"`
 }
 
+type ImportProfile struct {
+	ID               int32     `json:"id"`
+	CreatedAt        time.Time `json:"-"`
+	UpdatedAt        time.Time `json:"-"`
+	Version          int32     `json:"-"`
+	UserID           int32     `json:"user_id"`
+	Name             string    `json:"name"`
+	Delimiter        string    `json:"delimiter"`
+	HasHeader        bool      `json:"has_header"`
+	SkipRows         int32     `json:"skip_rows"`
+	DateColumn       string    `json:"date_column"`
+	DateFormat       string    `json:"date_format"`
+	AmountColumn     string    `json:"amount_column"`
+	DebitColumn      string    `json:"debit_column"`
+	CreditColumn     string    `json:"credit_column"`
+	TitleColumn      string    `json:"title_column"`
+	NoteColumn       string    `json:"note_column"`
+	DecimalSeparator string    `json:"decimal_separator"`
+	SignConvention   string    `json:"sign_convention"`
+}
+
+type ImportedTransaction struct {
+	TransactionID int32     `json:"transaction_id"`
+	CreatedAt     time.Time `json:"-"`
+	AccountID     int32     `json:"account_id"`
+	ExternalID    string    `json:"external_id"`
+}
+
+type Reconciliation struct {
+	ID                      int32     `json:"id"`
+	CreatedAt               time.Time `json:"-"`
+	AccountID               int32     `json:"account_id"`
+	StatementDate           time.Time `json:"statement_date"`
+	StatementBalanceCents   int64     `json:"statement_balance_cents"`
+	ReconciledBalanceCents  int64     `json:"reconciled_balance_cents"`
+	DifferenceCents         int64     `json:"difference_cents"`
+	AdjustmentTransactionID *int32    `json:"adjustment_transaction_id"`
+}
+
+type ReconciliationTransaction struct {
+	ReconciliationID int32 `json:"reconciliation_id"`
+	TransactionID    int32 `json:"transaction_id"`
+}
+
 type RecurringTransaction struct {
 	ID             int32               `json:"id"`
 	CreatedAt      time.Time           `json:"-"`
@@ -133,39 +220,101 @@ type RecurringTransaction struct {
 	Frequency      RecurrenceFrequency `json:"frequency"`
 	Interval       int32               `json:"interval"`
 	StartDate      time.Time           `json:"start_date"`
-	EndDate        sql.NullTime        `json:"end_date"`
-	DayMonth       sql.NullInt32       `json:"day_month"`
-	DayWeek        sql.NullInt32       `json:"day_week"`
-	MaxOccurrences sql.NullInt32       `json:"max_occurrences"`
+	EndDate        *time.Time          `json:"end_date"`
+	DayMonth       *int32              `json:"day_month"`
+	DayWeek        *int32              `json:"day_week"`
+	MaxOccurrences *int32              `json:"max_occurrences"`
 	IsActive       bool                `json:"is_active"`
+	ResumedAt      *time.Time          `json:"resumed_at"`
+}
+
+type RecurringTransactionException struct {
+	ID                     int32      `json:"id"`
+	CreatedAt              time.Time  `json:"-"`
+	RecurringTransactionID int32      `json:"recurring_transaction_id"`
+	OccurrenceDate         time.Time  `json:"occurrence_date"`
+	Skip                   bool       `json:"skip"`
+	NewDate                *time.Time `json:"new_date"`
+	AmountCents            *int32     `json:"amount_cents"`
 }
 
 type RecurringTransactionOccurrence struct {
 	ID                     int32     `json:"id"`
 	CreatedAt              time.Time `json:"-"`
 	RecurringTransactionID int32     `json:"recurring_transaction_id"`
-	TransactionID          int32     `json:"transaction_id"`
+	TransactionID          *int32    `json:"transaction_id"`
 	OccurrenceDate         time.Time `json:"occurrence_date"`
 }
 
+type Tag struct {
+	ID        int32     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+	Version   int32     `json:"-"`
+	UserID    int32     `json:"user_id"`
+	Name      string    `json:"name"`
+}
+
 type Token struct {
 	Hash   []byte    `json:"hash"`
 	UserID int32     `json:"user_id"`
 	Expiry time.Time `json:"expiry"`
+	Scope  string    `json:"scope"`
 }
 
 type Transaction struct {
-	ID          int32     `json:"id"`
-	CreatedAt   time.Time `json:"-"`
-	UpdatedAt   time.Time `json:"-"`
-	AmountCents int64     `json:"amount_cents"`
-	AccountID   int32     `json:"account_id"`
-	CategoryID  int32     `json:"category_id"`
-	Title       string    `json:"title"`
-	Date        time.Time `json:"date"`
-	Attachment  string    `json:"attachment"`
-	Note        string    `json:"note"`
-	Version     int32     `json:"-"`
+	ID          int32             `json:"id"`
+	CreatedAt   time.Time         `json:"-"`
+	UpdatedAt   time.Time         `json:"-"`
+	AmountCents int64             `json:"amount_cents"`
+	AccountID   int32             `json:"account_id"`
+	CategoryID  int32             `json:"category_id"`
+	Title       string            `json:"title"`
+	Date        time.Time         `json:"date"`
+	Attachment  string            `json:"attachment"`
+	Note        string            `json:"note"`
+	Version     int32             `json:"-"`
+	Status      TransactionStatus `json:"status"`
+}
+
+type TransactionAttachment struct {
+	ID            int32     `json:"id"`
+	CreatedAt     time.Time `json:"-"`
+	TransactionID int32     `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	SizeBytes     int64     `json:"size_bytes"`
+	StorageKey    string    `json:"-"`
+}
+
+type TransactionRefund struct {
+	ID                  int32     `json:"id"`
+	CreatedAt           time.Time `json:"-"`
+	TransactionID       int32     `json:"transaction_id"`
+	RefundTransactionID int32     `json:"refund_transaction_id"`
+	Reason              string    `json:"reason"`
+}
+
+type TransactionSplit struct {
+	ID            int32     `json:"id"`
+	CreatedAt     time.Time `json:"-"`
+	TransactionID int32     `json:"transaction_id"`
+	CategoryID    int32     `json:"category_id"`
+	AmountCents   int64     `json:"amount_cents"`
+	Note          string    `json:"note"`
+}
+
+type TransactionTag struct {
+	TransactionID int32 `json:"transaction_id"`
+	TagID         int32 `json:"tag_id"`
+}
+
+type Transfer struct {
+	ID                int32     `json:"id"`
+	CreatedAt         time.Time `json:"-"`
+	FromTransactionID int32     `json:"from_transaction_id"`
+	ToTransactionID   int32     `json:"to_transaction_id"`
+	FeeTransactionID  *int32    `json:"fee_transaction_id"`
 }
 
 type User struct {
@@ -10,20 +10,41 @@ import (
 )
 
 type Querier interface {
+	AddReconciliationTransactions(ctx context.Context, arg AddReconciliationTransactionsParams) error
+	AddTransactionTag(ctx context.Context, arg AddTransactionTagParams) error
 	AutoUpdateBalance(ctx context.Context, arg AutoUpdateBalanceParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateImportProfile(ctx context.Context, arg CreateImportProfileParams) (ImportProfile, error)
+	CreateImportedTransaction(ctx context.Context, arg CreateImportedTransactionParams) error
 	CreateOccurrence(ctx context.Context, arg CreateOccurrenceParams) (RecurringTransactionOccurrence, error)
+	CreateReconciliation(ctx context.Context, arg CreateReconciliationParams) (Reconciliation, error)
 	CreateRecurringTransaction(ctx context.Context, arg CreateRecurringTransactionParams) (RecurringTransaction, error)
+	CreateRecurringTransactionException(ctx context.Context, arg CreateRecurringTransactionExceptionParams) (RecurringTransactionException, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
 	CreateToken(ctx context.Context, arg CreateTokenParams) (Token, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
+	CreateTransactionAttachment(ctx context.Context, arg CreateTransactionAttachmentParams) (TransactionAttachment, error)
+	CreateTransactionRefund(ctx context.Context, arg CreateTransactionRefundParams) (TransactionRefund, error)
+	CreateTransactionSplit(ctx context.Context, arg CreateTransactionSplitParams) (TransactionSplit, error)
+	CreateTransactionWithDate(ctx context.Context, arg CreateTransactionWithDateParams) (Transaction, error)
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccountById(ctx context.Context, arg DeleteAccountByIdParams) (sql.Result, error)
 	DeleteCategoryById(ctx context.Context, arg DeleteCategoryByIdParams) (sql.Result, error)
+	DeleteImportProfileByID(ctx context.Context, arg DeleteImportProfileByIDParams) (sql.Result, error)
+	DeleteReconciliation(ctx context.Context, id int32) error
 	DeleteRecurringTransaction(ctx context.Context, arg DeleteRecurringTransactionParams) (sql.Result, error)
+	DeleteRecurringTransactionException(ctx context.Context, arg DeleteRecurringTransactionExceptionParams) (sql.Result, error)
+	DeleteTagByID(ctx context.Context, arg DeleteTagByIDParams) (sql.Result, error)
+	DeleteTransactionAttachment(ctx context.Context, arg DeleteTransactionAttachmentParams) (string, error)
 	DeleteTransactionByID(ctx context.Context, arg DeleteTransactionByIDParams) (sql.Result, error)
+	DeleteTransactionSplits(ctx context.Context, transactionID int32) error
+	DeleteTransactionTags(ctx context.Context, transactionID int32) error
 	DeleteUserById(ctx context.Context, id int32) (sql.Result, error)
+	GetAccountAttachmentKeys(ctx context.Context, arg GetAccountAttachmentKeysParams) ([]string, error)
 	GetAccountByID(ctx context.Context, arg GetAccountByIDParams) (Account, error)
+	GetAccountByIDForUpdate(ctx context.Context, arg GetAccountByIDForUpdateParams) (Account, error)
 	GetAccountSumBalance(ctx context.Context, arg GetAccountSumBalanceParams) (GetAccountSumBalanceRow, error)
 	GetActiveRecurringTransactions(ctx context.Context, arg GetActiveRecurringTransactionsParams) ([]RecurringTransaction, error)
 	GetAllAccounts(ctx context.Context) ([]Account, error)
@@ -32,23 +53,66 @@ type Querier interface {
 	GetAllUsers(ctx context.Context) ([]User, error)
 	GetCategoryByID(ctx context.Context, arg GetCategoryByIDParams) (Category, error)
 	GetCategoryByName(ctx context.Context, arg GetCategoryByNameParams) (Category, error)
+	GetCategoryReport(ctx context.Context, arg GetCategoryReportParams) ([]GetCategoryReportRow, error)
+	GetDuplicateCandidates(ctx context.Context, arg GetDuplicateCandidatesParams) ([]Transaction, error)
+	GetImportProfileByID(ctx context.Context, arg GetImportProfileByIDParams) (ImportProfile, error)
+	GetImportProfiles(ctx context.Context, userID int32) ([]ImportProfile, error)
+	GetImportedExternalIDs(ctx context.Context, arg GetImportedExternalIDsParams) ([]string, error)
 	GetLastOccurrence(ctx context.Context, recurringTransactionID int32) (RecurringTransactionOccurrence, error)
 	GetOccurrenceForDate(ctx context.Context, arg GetOccurrenceForDateParams) (RecurringTransactionOccurrence, error)
 	GetOccurrences(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionOccurrence, error)
+	GetReconciledBalance(ctx context.Context, accountID int32) (int64, error)
+	GetReconciliationByID(ctx context.Context, arg GetReconciliationByIDParams) (Reconciliation, error)
+	GetReconciliationTransactionIDs(ctx context.Context, reconciliationID int32) ([]int32, error)
 	GetRecurringTransactionByID(ctx context.Context, arg GetRecurringTransactionByIDParams) (RecurringTransaction, error)
+	GetRecurringTransactionExceptions(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionException, error)
+	GetTagByID(ctx context.Context, arg GetTagByIDParams) (Tag, error)
+	GetTagReport(ctx context.Context, arg GetTagReportParams) ([]GetTagReportRow, error)
+	GetTagsForTransactions(ctx context.Context, transactionIds []int32) ([]GetTagsForTransactionsRow, error)
+	GetTransactionAttachmentByID(ctx context.Context, arg GetTransactionAttachmentByIDParams) (TransactionAttachment, error)
+	GetTransactionAttachmentKeys(ctx context.Context, transactionID int32) ([]string, error)
+	GetTransactionAttachments(ctx context.Context, transactionID int32) ([]TransactionAttachment, error)
 	GetTransactionByID(ctx context.Context, arg GetTransactionByIDParams) (GetTransactionByIDRow, error)
+	GetTransactionByIDForUpdate(ctx context.Context, arg GetTransactionByIDForUpdateParams) (GetTransactionByIDForUpdateRow, error)
+	GetTransactionRefundOf(ctx context.Context, refundTransactionID int32) (TransactionRefund, error)
+	GetTransactionRefunds(ctx context.Context, transactionID int32) ([]GetTransactionRefundsRow, error)
+	GetTransactionSplits(ctx context.Context, transactionID int32) ([]TransactionSplit, error)
+	GetTransactionSplitsForTransactions(ctx context.Context, transactionIds []int32) ([]TransactionSplit, error)
 	GetTransactionsByAccountID(ctx context.Context, arg GetTransactionsByAccountIDParams) ([]GetTransactionsByAccountIDRow, error)
+	GetTransactionsByIDs(ctx context.Context, arg GetTransactionsByIDsParams) ([]GetTransactionsByIDsRow, error)
+	GetTransferAccountNames(ctx context.Context, arg GetTransferAccountNamesParams) ([]GetTransferAccountNamesRow, error)
+	GetTransferByID(ctx context.Context, arg GetTransferByIDParams) (Transfer, error)
+	GetTransferByTransactionID(ctx context.Context, transactionID int32) (Transfer, error)
+	GetTransferIDsForTransactions(ctx context.Context, transactionIds []int32) ([]GetTransferIDsForTransactionsRow, error)
 	GetUserAccounts(ctx context.Context, userID int32) ([]Account, error)
+	GetUserAttachmentKeys(ctx context.Context, userID int32) ([]string, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 	GetUserFromToken(ctx context.Context, arg GetUserFromTokenParams) (GetUserFromTokenRow, error)
+	GetUserReconciliationTransactions(ctx context.Context, userID int32) ([]ReconciliationTransaction, error)
+	GetUserReconciliations(ctx context.Context, userID int32) ([]Reconciliation, error)
 	GetUserRecurringTransactions(ctx context.Context, userID int32) ([]RecurringTransaction, error)
+	GetUserTags(ctx context.Context, userID int32) ([]Tag, error)
+	GetUserTransactionRefunds(ctx context.Context, userID int32) ([]TransactionRefund, error)
+	GetUserTransfers(ctx context.Context, userID int32) ([]Transfer, error)
+	ListReconciliations(ctx context.Context, arg ListReconciliationsParams) ([]Reconciliation, error)
+	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error)
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	LockRecurringTransaction(ctx context.Context, id int32) (RecurringTransaction, error)
+	ReconcileTransactions(ctx context.Context, arg ReconcileTransactionsParams) (int64, error)
+	SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error)
+	SetTransferFee(ctx context.Context, arg SetTransferFeeParams) error
+	UnreconcileTransactions(ctx context.Context, arg UnreconcileTransactionsParams) error
 	UpdateAccountById(ctx context.Context, arg UpdateAccountByIdParams) (sql.Result, error)
 	UpdateBalance(ctx context.Context, arg UpdateBalanceParams) (int64, error)
 	UpdateCategoryById(ctx context.Context, arg UpdateCategoryByIdParams) (sql.Result, error)
+	UpdateClearedBalance(ctx context.Context, arg UpdateClearedBalanceParams) (int64, error)
+	UpdateImportProfileByID(ctx context.Context, arg UpdateImportProfileByIDParams) (sql.Result, error)
 	UpdateRecurringTransaction(ctx context.Context, arg UpdateRecurringTransactionParams) (sql.Result, error)
+	UpdateTagByID(ctx context.Context, arg UpdateTagByIDParams) (sql.Result, error)
 	UpdateTransactionById(ctx context.Context, arg UpdateTransactionByIdParams) (sql.Result, error)
 	UpdateUserById(ctx context.Context, arg UpdateUserByIdParams) (sql.Result, error)
+	UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -6,45 +6,109 @@ import (
 )
 
 type MockQuerierTx struct {
-	AutoUpdateBalanceFunc              func(ctx context.Context, arg AutoUpdateBalanceParams) (int64, error)
-	CreateAccountFunc                  func(ctx context.Context, arg CreateAccountParams) (Account, error)
-	CreateCategoryFunc                 func(ctx context.Context, arg CreateCategoryParams) (Category, error)
-	CreateOccurrenceFunc               func(ctx context.Context, arg CreateOccurrenceParams) (RecurringTransactionOccurrence, error)
-	CreateRecurringTransactionFunc     func(ctx context.Context, arg CreateRecurringTransactionParams) (RecurringTransaction, error)
-	CreateTokenFunc                    func(ctx context.Context, arg CreateTokenParams) (Token, error)
-	CreateTransactionFunc              func(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
-	CreateUserFunc                     func(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccountByIdFunc              func(ctx context.Context, arg DeleteAccountByIdParams) (sql.Result, error)
-	DeleteCategoryByIdFunc             func(ctx context.Context, arg DeleteCategoryByIdParams) (sql.Result, error)
-	DeleteRecurringTransactionFunc     func(ctx context.Context, arg DeleteRecurringTransactionParams) (sql.Result, error)
-	DeleteTransactionByIDFunc          func(ctx context.Context, arg DeleteTransactionByIDParams) (sql.Result, error)
-	DeleteUserByIdFunc                 func(ctx context.Context, id int32) (sql.Result, error)
-	GetAccountByIDFunc                 func(ctx context.Context, arg GetAccountByIDParams) (Account, error)
-	GetAccountSumBalanceFunc           func(ctx context.Context, arg GetAccountSumBalanceParams) (GetAccountSumBalanceRow, error)
-	GetActiveRecurringTransactionsFunc func(ctx context.Context, arg GetActiveRecurringTransactionsParams) ([]RecurringTransaction, error)
-	GetAllAccountsFunc                 func(ctx context.Context) ([]Account, error)
-	GetAllCategoriesFunc               func(ctx context.Context, arg GetAllCategoriesParams) ([]Category, error)
-	GetAllTransactionsFunc             func(ctx context.Context, userID int32) ([]GetAllTransactionsRow, error)
-	GetAllUsersFunc                    func(ctx context.Context) ([]User, error)
-	GetCategoryByIDFunc                func(ctx context.Context, arg GetCategoryByIDParams) (Category, error)
-	GetCategoryByNameFunc              func(ctx context.Context, arg GetCategoryByNameParams) (Category, error)
-	GetLastOccurrenceFunc              func(ctx context.Context, recurringTransactionID int32) (RecurringTransactionOccurrence, error)
-	GetOccurrenceForDateFunc           func(ctx context.Context, arg GetOccurrenceForDateParams) (RecurringTransactionOccurrence, error)
-	GetOccurrencesFunc                 func(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionOccurrence, error)
-	GetRecurringTransactionByIDFunc    func(ctx context.Context, arg GetRecurringTransactionByIDParams) (RecurringTransaction, error)
-	GetTransactionByIDFunc             func(ctx context.Context, arg GetTransactionByIDParams) (GetTransactionByIDRow, error)
-	GetTransactionsByAccountIDFunc     func(ctx context.Context, arg GetTransactionsByAccountIDParams) ([]GetTransactionsByAccountIDRow, error)
-	GetUserAccountsFunc                func(ctx context.Context, userID int32) ([]Account, error)
-	GetUserByIDFunc                    func(ctx context.Context, id int32) (User, error)
-	GetUserByUsernameFunc              func(ctx context.Context, username string) (User, error)
-	GetUserFromTokenFunc               func(ctx context.Context, arg GetUserFromTokenParams) (GetUserFromTokenRow, error)
-	GetUserRecurringTransactionsFunc   func(ctx context.Context, userID int32) ([]RecurringTransaction, error)
-	UpdateAccountByIdFunc              func(ctx context.Context, arg UpdateAccountByIdParams) (sql.Result, error)
-	UpdateBalanceFunc                  func(ctx context.Context, arg UpdateBalanceParams) (int64, error)
-	UpdateCategoryByIdFunc             func(ctx context.Context, arg UpdateCategoryByIdParams) (sql.Result, error)
-	UpdateRecurringTransactionFunc     func(ctx context.Context, arg UpdateRecurringTransactionParams) (sql.Result, error)
-	UpdateTransactionByIdFunc          func(ctx context.Context, arg UpdateTransactionByIdParams) (sql.Result, error)
-	UpdateUserByIdFunc                 func(ctx context.Context, arg UpdateUserByIdParams) (sql.Result, error)
+	AddReconciliationTransactionsFunc       func(ctx context.Context, arg AddReconciliationTransactionsParams) error
+	AddTransactionTagFunc                   func(ctx context.Context, arg AddTransactionTagParams) error
+	AutoUpdateBalanceFunc                   func(ctx context.Context, arg AutoUpdateBalanceParams) (int64, error)
+	CreateAccountFunc                       func(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateCategoryFunc                      func(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateImportProfileFunc                 func(ctx context.Context, arg CreateImportProfileParams) (ImportProfile, error)
+	CreateImportedTransactionFunc           func(ctx context.Context, arg CreateImportedTransactionParams) error
+	CreateOccurrenceFunc                    func(ctx context.Context, arg CreateOccurrenceParams) (RecurringTransactionOccurrence, error)
+	CreateReconciliationFunc                func(ctx context.Context, arg CreateReconciliationParams) (Reconciliation, error)
+	CreateRecurringTransactionFunc          func(ctx context.Context, arg CreateRecurringTransactionParams) (RecurringTransaction, error)
+	CreateRecurringTransactionExceptionFunc func(ctx context.Context, arg CreateRecurringTransactionExceptionParams) (RecurringTransactionException, error)
+	CreateTagFunc                           func(ctx context.Context, arg CreateTagParams) (Tag, error)
+	CreateTokenFunc                         func(ctx context.Context, arg CreateTokenParams) (Token, error)
+	CreateTransactionFunc                   func(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
+	CreateTransactionAttachmentFunc         func(ctx context.Context, arg CreateTransactionAttachmentParams) (TransactionAttachment, error)
+	CreateTransactionRefundFunc             func(ctx context.Context, arg CreateTransactionRefundParams) (TransactionRefund, error)
+	CreateTransactionSplitFunc              func(ctx context.Context, arg CreateTransactionSplitParams) (TransactionSplit, error)
+	CreateTransactionWithDateFunc           func(ctx context.Context, arg CreateTransactionWithDateParams) (Transaction, error)
+	CreateTransferFunc                      func(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateUserFunc                          func(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteAccountByIdFunc                   func(ctx context.Context, arg DeleteAccountByIdParams) (sql.Result, error)
+	DeleteCategoryByIdFunc                  func(ctx context.Context, arg DeleteCategoryByIdParams) (sql.Result, error)
+	DeleteImportProfileByIDFunc             func(ctx context.Context, arg DeleteImportProfileByIDParams) (sql.Result, error)
+	DeleteReconciliationFunc                func(ctx context.Context, id int32) error
+	DeleteRecurringTransactionFunc          func(ctx context.Context, arg DeleteRecurringTransactionParams) (sql.Result, error)
+	DeleteRecurringTransactionExceptionFunc func(ctx context.Context, arg DeleteRecurringTransactionExceptionParams) (sql.Result, error)
+	DeleteTagByIDFunc                       func(ctx context.Context, arg DeleteTagByIDParams) (sql.Result, error)
+	DeleteTransactionAttachmentFunc         func(ctx context.Context, arg DeleteTransactionAttachmentParams) (string, error)
+	DeleteTransactionByIDFunc               func(ctx context.Context, arg DeleteTransactionByIDParams) (sql.Result, error)
+	DeleteTransactionSplitsFunc             func(ctx context.Context, transactionID int32) error
+	DeleteTransactionTagsFunc               func(ctx context.Context, transactionID int32) error
+	DeleteUserByIdFunc                      func(ctx context.Context, id int32) (sql.Result, error)
+	GetAccountAttachmentKeysFunc            func(ctx context.Context, arg GetAccountAttachmentKeysParams) ([]string, error)
+	GetAccountByIDFunc                      func(ctx context.Context, arg GetAccountByIDParams) (Account, error)
+	GetAccountByIDForUpdateFunc             func(ctx context.Context, arg GetAccountByIDForUpdateParams) (Account, error)
+	GetAccountSumBalanceFunc                func(ctx context.Context, arg GetAccountSumBalanceParams) (GetAccountSumBalanceRow, error)
+	GetActiveRecurringTransactionsFunc      func(ctx context.Context, arg GetActiveRecurringTransactionsParams) ([]RecurringTransaction, error)
+	GetAllAccountsFunc                      func(ctx context.Context) ([]Account, error)
+	GetAllCategoriesFunc                    func(ctx context.Context, arg GetAllCategoriesParams) ([]Category, error)
+	GetAllTransactionsFunc                  func(ctx context.Context, userID int32) ([]GetAllTransactionsRow, error)
+	GetAllUsersFunc                         func(ctx context.Context) ([]User, error)
+	GetCategoryByIDFunc                     func(ctx context.Context, arg GetCategoryByIDParams) (Category, error)
+	GetCategoryByNameFunc                   func(ctx context.Context, arg GetCategoryByNameParams) (Category, error)
+	GetCategoryReportFunc                   func(ctx context.Context, arg GetCategoryReportParams) ([]GetCategoryReportRow, error)
+	GetDuplicateCandidatesFunc              func(ctx context.Context, arg GetDuplicateCandidatesParams) ([]Transaction, error)
+	GetImportProfileByIDFunc                func(ctx context.Context, arg GetImportProfileByIDParams) (ImportProfile, error)
+	GetImportProfilesFunc                   func(ctx context.Context, userID int32) ([]ImportProfile, error)
+	GetImportedExternalIDsFunc              func(ctx context.Context, arg GetImportedExternalIDsParams) ([]string, error)
+	GetLastOccurrenceFunc                   func(ctx context.Context, recurringTransactionID int32) (RecurringTransactionOccurrence, error)
+	GetOccurrenceForDateFunc                func(ctx context.Context, arg GetOccurrenceForDateParams) (RecurringTransactionOccurrence, error)
+	GetOccurrencesFunc                      func(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionOccurrence, error)
+	GetReconciledBalanceFunc                func(ctx context.Context, accountID int32) (int64, error)
+	GetReconciliationByIDFunc               func(ctx context.Context, arg GetReconciliationByIDParams) (Reconciliation, error)
+	GetReconciliationTransactionIDsFunc     func(ctx context.Context, reconciliationID int32) ([]int32, error)
+	GetRecurringTransactionByIDFunc         func(ctx context.Context, arg GetRecurringTransactionByIDParams) (RecurringTransaction, error)
+	GetRecurringTransactionExceptionsFunc   func(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionException, error)
+	GetTagByIDFunc                          func(ctx context.Context, arg GetTagByIDParams) (Tag, error)
+	GetTagReportFunc                        func(ctx context.Context, arg GetTagReportParams) ([]GetTagReportRow, error)
+	GetTagsForTransactionsFunc              func(ctx context.Context, transactionIds []int32) ([]GetTagsForTransactionsRow, error)
+	GetTransactionAttachmentByIDFunc        func(ctx context.Context, arg GetTransactionAttachmentByIDParams) (TransactionAttachment, error)
+	GetTransactionAttachmentKeysFunc        func(ctx context.Context, transactionID int32) ([]string, error)
+	GetTransactionAttachmentsFunc           func(ctx context.Context, transactionID int32) ([]TransactionAttachment, error)
+	GetTransactionByIDFunc                  func(ctx context.Context, arg GetTransactionByIDParams) (GetTransactionByIDRow, error)
+	GetTransactionByIDForUpdateFunc         func(ctx context.Context, arg GetTransactionByIDForUpdateParams) (GetTransactionByIDForUpdateRow, error)
+	GetTransactionRefundOfFunc              func(ctx context.Context, refundTransactionID int32) (TransactionRefund, error)
+	GetTransactionRefundsFunc               func(ctx context.Context, transactionID int32) ([]GetTransactionRefundsRow, error)
+	GetTransactionSplitsFunc                func(ctx context.Context, transactionID int32) ([]TransactionSplit, error)
+	GetTransactionSplitsForTransactionsFunc func(ctx context.Context, transactionIds []int32) ([]TransactionSplit, error)
+	GetTransactionsByAccountIDFunc          func(ctx context.Context, arg GetTransactionsByAccountIDParams) ([]GetTransactionsByAccountIDRow, error)
+	GetTransactionsByIDsFunc                func(ctx context.Context, arg GetTransactionsByIDsParams) ([]GetTransactionsByIDsRow, error)
+	GetTransferAccountNamesFunc             func(ctx context.Context, arg GetTransferAccountNamesParams) ([]GetTransferAccountNamesRow, error)
+	GetTransferByIDFunc                     func(ctx context.Context, arg GetTransferByIDParams) (Transfer, error)
+	GetTransferByTransactionIDFunc          func(ctx context.Context, transactionID int32) (Transfer, error)
+	GetTransferIDsForTransactionsFunc       func(ctx context.Context, transactionIds []int32) ([]GetTransferIDsForTransactionsRow, error)
+	GetUserAccountsFunc                     func(ctx context.Context, userID int32) ([]Account, error)
+	GetUserAttachmentKeysFunc               func(ctx context.Context, userID int32) ([]string, error)
+	GetUserByIDFunc                         func(ctx context.Context, id int32) (User, error)
+	GetUserByUsernameFunc                   func(ctx context.Context, username string) (User, error)
+	GetUserFromTokenFunc                    func(ctx context.Context, arg GetUserFromTokenParams) (GetUserFromTokenRow, error)
+	GetUserReconciliationTransactionsFunc   func(ctx context.Context, userID int32) ([]ReconciliationTransaction, error)
+	GetUserReconciliationsFunc              func(ctx context.Context, userID int32) ([]Reconciliation, error)
+	GetUserRecurringTransactionsFunc        func(ctx context.Context, userID int32) ([]RecurringTransaction, error)
+	GetUserTagsFunc                         func(ctx context.Context, userID int32) ([]Tag, error)
+	GetUserTransactionRefundsFunc           func(ctx context.Context, userID int32) ([]TransactionRefund, error)
+	GetUserTransfersFunc                    func(ctx context.Context, userID int32) ([]Transfer, error)
+	ListReconciliationsFunc                 func(ctx context.Context, arg ListReconciliationsParams) ([]Reconciliation, error)
+	ListTransactionsFunc                    func(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error)
+	ListTransfersFunc                       func(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	LockRecurringTransactionFunc            func(ctx context.Context, id int32) (RecurringTransaction, error)
+	ReconcileTransactionsFunc               func(ctx context.Context, arg ReconcileTransactionsParams) (int64, error)
+	SearchTransactionsFunc                  func(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error)
+	SetTransferFeeFunc                      func(ctx context.Context, arg SetTransferFeeParams) error
+	UnreconcileTransactionsFunc             func(ctx context.Context, arg UnreconcileTransactionsParams) error
+	UpdateAccountByIdFunc                   func(ctx context.Context, arg UpdateAccountByIdParams) (sql.Result, error)
+	UpdateBalanceFunc                       func(ctx context.Context, arg UpdateBalanceParams) (int64, error)
+	UpdateCategoryByIdFunc                  func(ctx context.Context, arg UpdateCategoryByIdParams) (sql.Result, error)
+	UpdateClearedBalanceFunc                func(ctx context.Context, arg UpdateClearedBalanceParams) (int64, error)
+	UpdateImportProfileByIDFunc             func(ctx context.Context, arg UpdateImportProfileByIDParams) (sql.Result, error)
+	UpdateRecurringTransactionFunc          func(ctx context.Context, arg UpdateRecurringTransactionParams) (sql.Result, error)
+	UpdateTagByIDFunc                       func(ctx context.Context, arg UpdateTagByIDParams) (sql.Result, error)
+	UpdateTransactionByIdFunc               func(ctx context.Context, arg UpdateTransactionByIdParams) (sql.Result, error)
+	UpdateUserByIdFunc                      func(ctx context.Context, arg UpdateUserByIdParams) (sql.Result, error)
+	UpsertTagFunc                           func(ctx context.Context, arg UpsertTagParams) (Tag, error)
 
 	WithTxFunc func(tx *sql.Tx) QuerierTx
 }
@@ -52,6 +116,20 @@ type MockQuerierTx struct {
 var _ QuerierTx = (*MockQuerierTx)(nil)
 
 // Account queries
+func (m *MockQuerierTx) AddReconciliationTransactions(ctx context.Context, arg AddReconciliationTransactionsParams) error {
+	if m.AddReconciliationTransactionsFunc != nil {
+		return m.AddReconciliationTransactionsFunc(ctx, arg)
+	}
+	return nil
+}
+
+func (m *MockQuerierTx) AddTransactionTag(ctx context.Context, arg AddTransactionTagParams) error {
+	if m.AddTransactionTagFunc != nil {
+		return m.AddTransactionTagFunc(ctx, arg)
+	}
+	return nil
+}
+
 func (m *MockQuerierTx) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
 	if m.CreateAccountFunc != nil {
 		return m.CreateAccountFunc(ctx, arg)
@@ -59,6 +137,132 @@ func (m *MockQuerierTx) CreateAccount(ctx context.Context, arg CreateAccountPara
 	return Account{}, nil
 }
 
+func (m *MockQuerierTx) CreateImportProfile(ctx context.Context, arg CreateImportProfileParams) (ImportProfile, error) {
+	if m.CreateImportProfileFunc != nil {
+		return m.CreateImportProfileFunc(ctx, arg)
+	}
+	return ImportProfile{}, nil
+}
+
+func (m *MockQuerierTx) CreateImportedTransaction(ctx context.Context, arg CreateImportedTransactionParams) error {
+	if m.CreateImportedTransactionFunc != nil {
+		return m.CreateImportedTransactionFunc(ctx, arg)
+	}
+	return nil
+}
+
+func (m *MockQuerierTx) CreateReconciliation(ctx context.Context, arg CreateReconciliationParams) (Reconciliation, error) {
+	if m.CreateReconciliationFunc != nil {
+		return m.CreateReconciliationFunc(ctx, arg)
+	}
+	return Reconciliation{}, nil
+}
+
+func (m *MockQuerierTx) CreateRecurringTransactionException(ctx context.Context, arg CreateRecurringTransactionExceptionParams) (RecurringTransactionException, error) {
+	if m.CreateRecurringTransactionExceptionFunc != nil {
+		return m.CreateRecurringTransactionExceptionFunc(ctx, arg)
+	}
+	return RecurringTransactionException{}, nil
+}
+
+func (m *MockQuerierTx) CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error) {
+	if m.CreateTagFunc != nil {
+		return m.CreateTagFunc(ctx, arg)
+	}
+	return Tag{}, nil
+}
+
+func (m *MockQuerierTx) CreateTransactionAttachment(ctx context.Context, arg CreateTransactionAttachmentParams) (TransactionAttachment, error) {
+	if m.CreateTransactionAttachmentFunc != nil {
+		return m.CreateTransactionAttachmentFunc(ctx, arg)
+	}
+	return TransactionAttachment{}, nil
+}
+
+func (m *MockQuerierTx) CreateTransactionRefund(ctx context.Context, arg CreateTransactionRefundParams) (TransactionRefund, error) {
+	if m.CreateTransactionRefundFunc != nil {
+		return m.CreateTransactionRefundFunc(ctx, arg)
+	}
+	return TransactionRefund{}, nil
+}
+
+func (m *MockQuerierTx) CreateTransactionSplit(ctx context.Context, arg CreateTransactionSplitParams) (TransactionSplit, error) {
+	if m.CreateTransactionSplitFunc != nil {
+		return m.CreateTransactionSplitFunc(ctx, arg)
+	}
+	return TransactionSplit{}, nil
+}
+
+func (m *MockQuerierTx) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	if m.CreateTransferFunc != nil {
+		return m.CreateTransferFunc(ctx, arg)
+	}
+	return Transfer{}, nil
+}
+
+func (m *MockQuerierTx) DeleteImportProfileByID(ctx context.Context, arg DeleteImportProfileByIDParams) (sql.Result, error) {
+	if m.DeleteImportProfileByIDFunc != nil {
+		return m.DeleteImportProfileByIDFunc(ctx, arg)
+	}
+	return nil, nil
+}
+
+func (m *MockQuerierTx) DeleteReconciliation(ctx context.Context, id int32) error {
+	if m.DeleteReconciliationFunc != nil {
+		return m.DeleteReconciliationFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockQuerierTx) DeleteRecurringTransactionException(ctx context.Context, arg DeleteRecurringTransactionExceptionParams) (sql.Result, error) {
+	if m.DeleteRecurringTransactionExceptionFunc != nil {
+		return m.DeleteRecurringTransactionExceptionFunc(ctx, arg)
+	}
+	return NewMockResult(1), nil
+}
+
+func (m *MockQuerierTx) DeleteTagByID(ctx context.Context, arg DeleteTagByIDParams) (sql.Result, error) {
+	if m.DeleteTagByIDFunc != nil {
+		return m.DeleteTagByIDFunc(ctx, arg)
+	}
+	return nil, nil
+}
+
+func (m *MockQuerierTx) DeleteTransactionAttachment(ctx context.Context, arg DeleteTransactionAttachmentParams) (string, error) {
+	if m.DeleteTransactionAttachmentFunc != nil {
+		return m.DeleteTransactionAttachmentFunc(ctx, arg)
+	}
+	return "", nil
+}
+
+func (m *MockQuerierTx) DeleteTransactionSplits(ctx context.Context, transactionID int32) error {
+	if m.DeleteTransactionSplitsFunc != nil {
+		return m.DeleteTransactionSplitsFunc(ctx, transactionID)
+	}
+	return nil
+}
+
+func (m *MockQuerierTx) DeleteTransactionTags(ctx context.Context, transactionID int32) error {
+	if m.DeleteTransactionTagsFunc != nil {
+		return m.DeleteTransactionTagsFunc(ctx, transactionID)
+	}
+	return nil
+}
+
+func (m *MockQuerierTx) GetAccountAttachmentKeys(ctx context.Context, arg GetAccountAttachmentKeysParams) ([]string, error) {
+	if m.GetAccountAttachmentKeysFunc != nil {
+		return m.GetAccountAttachmentKeysFunc(ctx, arg)
+	}
+	return []string{}, nil
+}
+
+func (m *MockQuerierTx) GetAccountByIDForUpdate(ctx context.Context, arg GetAccountByIDForUpdateParams) (Account, error) {
+	if m.GetAccountByIDForUpdateFunc != nil {
+		return m.GetAccountByIDForUpdateFunc(ctx, arg)
+	}
+	return Account{}, nil
+}
+
 func (m *MockQuerierTx) GetAllAccounts(ctx context.Context) ([]Account, error) {
 	if m.GetAllAccountsFunc != nil {
 		return m.GetAllAccountsFunc(ctx)
@@ -66,6 +270,181 @@ func (m *MockQuerierTx) GetAllAccounts(ctx context.Context) ([]Account, error) {
 	return []Account{}, nil
 }
 
+func (m *MockQuerierTx) GetCategoryReport(ctx context.Context, arg GetCategoryReportParams) ([]GetCategoryReportRow, error) {
+	if m.GetCategoryReportFunc != nil {
+		return m.GetCategoryReportFunc(ctx, arg)
+	}
+	return []GetCategoryReportRow{}, nil
+}
+
+func (m *MockQuerierTx) GetDuplicateCandidates(ctx context.Context, arg GetDuplicateCandidatesParams) ([]Transaction, error) {
+	if m.GetDuplicateCandidatesFunc != nil {
+		return m.GetDuplicateCandidatesFunc(ctx, arg)
+	}
+	return []Transaction{}, nil
+}
+
+func (m *MockQuerierTx) GetImportProfileByID(ctx context.Context, arg GetImportProfileByIDParams) (ImportProfile, error) {
+	if m.GetImportProfileByIDFunc != nil {
+		return m.GetImportProfileByIDFunc(ctx, arg)
+	}
+	return ImportProfile{}, nil
+}
+
+func (m *MockQuerierTx) GetImportProfiles(ctx context.Context, userID int32) ([]ImportProfile, error) {
+	if m.GetImportProfilesFunc != nil {
+		return m.GetImportProfilesFunc(ctx, userID)
+	}
+	return []ImportProfile{}, nil
+}
+
+func (m *MockQuerierTx) GetImportedExternalIDs(ctx context.Context, arg GetImportedExternalIDsParams) ([]string, error) {
+	if m.GetImportedExternalIDsFunc != nil {
+		return m.GetImportedExternalIDsFunc(ctx, arg)
+	}
+	return []string{}, nil
+}
+
+func (m *MockQuerierTx) GetReconciledBalance(ctx context.Context, accountID int32) (int64, error) {
+	if m.GetReconciledBalanceFunc != nil {
+		return m.GetReconciledBalanceFunc(ctx, accountID)
+	}
+	return 0, nil
+}
+
+func (m *MockQuerierTx) GetReconciliationByID(ctx context.Context, arg GetReconciliationByIDParams) (Reconciliation, error) {
+	if m.GetReconciliationByIDFunc != nil {
+		return m.GetReconciliationByIDFunc(ctx, arg)
+	}
+	return Reconciliation{}, nil
+}
+
+func (m *MockQuerierTx) GetReconciliationTransactionIDs(ctx context.Context, reconciliationID int32) ([]int32, error) {
+	if m.GetReconciliationTransactionIDsFunc != nil {
+		return m.GetReconciliationTransactionIDsFunc(ctx, reconciliationID)
+	}
+	return []int32{}, nil
+}
+
+func (m *MockQuerierTx) GetRecurringTransactionExceptions(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionException, error) {
+	if m.GetRecurringTransactionExceptionsFunc != nil {
+		return m.GetRecurringTransactionExceptionsFunc(ctx, recurringTransactionID)
+	}
+	return []RecurringTransactionException{}, nil
+}
+
+func (m *MockQuerierTx) GetTagByID(ctx context.Context, arg GetTagByIDParams) (Tag, error) {
+	if m.GetTagByIDFunc != nil {
+		return m.GetTagByIDFunc(ctx, arg)
+	}
+	return Tag{}, nil
+}
+
+func (m *MockQuerierTx) GetTagReport(ctx context.Context, arg GetTagReportParams) ([]GetTagReportRow, error) {
+	if m.GetTagReportFunc != nil {
+		return m.GetTagReportFunc(ctx, arg)
+	}
+	return []GetTagReportRow{}, nil
+}
+
+func (m *MockQuerierTx) GetTagsForTransactions(ctx context.Context, transactionIds []int32) ([]GetTagsForTransactionsRow, error) {
+	if m.GetTagsForTransactionsFunc != nil {
+		return m.GetTagsForTransactionsFunc(ctx, transactionIds)
+	}
+	return []GetTagsForTransactionsRow{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionAttachmentByID(ctx context.Context, arg GetTransactionAttachmentByIDParams) (TransactionAttachment, error) {
+	if m.GetTransactionAttachmentByIDFunc != nil {
+		return m.GetTransactionAttachmentByIDFunc(ctx, arg)
+	}
+	return TransactionAttachment{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionAttachmentKeys(ctx context.Context, transactionID int32) ([]string, error) {
+	if m.GetTransactionAttachmentKeysFunc != nil {
+		return m.GetTransactionAttachmentKeysFunc(ctx, transactionID)
+	}
+	return []string{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionAttachments(ctx context.Context, transactionID int32) ([]TransactionAttachment, error) {
+	if m.GetTransactionAttachmentsFunc != nil {
+		return m.GetTransactionAttachmentsFunc(ctx, transactionID)
+	}
+	return []TransactionAttachment{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionByIDForUpdate(ctx context.Context, arg GetTransactionByIDForUpdateParams) (GetTransactionByIDForUpdateRow, error) {
+	if m.GetTransactionByIDForUpdateFunc != nil {
+		return m.GetTransactionByIDForUpdateFunc(ctx, arg)
+	}
+	return GetTransactionByIDForUpdateRow{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionRefundOf(ctx context.Context, refundTransactionID int32) (TransactionRefund, error) {
+	if m.GetTransactionRefundOfFunc != nil {
+		return m.GetTransactionRefundOfFunc(ctx, refundTransactionID)
+	}
+	return TransactionRefund{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionRefunds(ctx context.Context, transactionID int32) ([]GetTransactionRefundsRow, error) {
+	if m.GetTransactionRefundsFunc != nil {
+		return m.GetTransactionRefundsFunc(ctx, transactionID)
+	}
+	return []GetTransactionRefundsRow{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionSplits(ctx context.Context, transactionID int32) ([]TransactionSplit, error) {
+	if m.GetTransactionSplitsFunc != nil {
+		return m.GetTransactionSplitsFunc(ctx, transactionID)
+	}
+	return []TransactionSplit{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionSplitsForTransactions(ctx context.Context, transactionIds []int32) ([]TransactionSplit, error) {
+	if m.GetTransactionSplitsForTransactionsFunc != nil {
+		return m.GetTransactionSplitsForTransactionsFunc(ctx, transactionIds)
+	}
+	return []TransactionSplit{}, nil
+}
+
+func (m *MockQuerierTx) GetTransactionsByIDs(ctx context.Context, arg GetTransactionsByIDsParams) ([]GetTransactionsByIDsRow, error) {
+	if m.GetTransactionsByIDsFunc != nil {
+		return m.GetTransactionsByIDsFunc(ctx, arg)
+	}
+	return []GetTransactionsByIDsRow{}, nil
+}
+
+func (m *MockQuerierTx) GetTransferAccountNames(ctx context.Context, arg GetTransferAccountNamesParams) ([]GetTransferAccountNamesRow, error) {
+	if m.GetTransferAccountNamesFunc != nil {
+		return m.GetTransferAccountNamesFunc(ctx, arg)
+	}
+	return []GetTransferAccountNamesRow{}, nil
+}
+
+func (m *MockQuerierTx) GetTransferByID(ctx context.Context, arg GetTransferByIDParams) (Transfer, error) {
+	if m.GetTransferByIDFunc != nil {
+		return m.GetTransferByIDFunc(ctx, arg)
+	}
+	return Transfer{}, nil
+}
+
+func (m *MockQuerierTx) GetTransferByTransactionID(ctx context.Context, transactionID int32) (Transfer, error) {
+	if m.GetTransferByTransactionIDFunc != nil {
+		return m.GetTransferByTransactionIDFunc(ctx, transactionID)
+	}
+	return Transfer{}, nil
+}
+
+func (m *MockQuerierTx) GetTransferIDsForTransactions(ctx context.Context, transactionIds []int32) ([]GetTransferIDsForTransactionsRow, error) {
+	if m.GetTransferIDsForTransactionsFunc != nil {
+		return m.GetTransferIDsForTransactionsFunc(ctx, transactionIds)
+	}
+	return []GetTransferIDsForTransactionsRow{}, nil
+}
+
 func (m *MockQuerierTx) GetUserAccounts(ctx context.Context, userID int32) ([]Account, error) {
 	if m.GetUserAccountsFunc != nil {
 		return m.GetUserAccountsFunc(ctx, userID)
@@ -73,6 +452,97 @@ func (m *MockQuerierTx) GetUserAccounts(ctx context.Context, userID int32) ([]Ac
 	return []Account{}, nil
 }
 
+func (m *MockQuerierTx) GetUserAttachmentKeys(ctx context.Context, userID int32) ([]string, error) {
+	if m.GetUserAttachmentKeysFunc != nil {
+		return m.GetUserAttachmentKeysFunc(ctx, userID)
+	}
+	return []string{}, nil
+}
+
+func (m *MockQuerierTx) GetUserReconciliationTransactions(ctx context.Context, userID int32) ([]ReconciliationTransaction, error) {
+	if m.GetUserReconciliationTransactionsFunc != nil {
+		return m.GetUserReconciliationTransactionsFunc(ctx, userID)
+	}
+	return []ReconciliationTransaction{}, nil
+}
+
+func (m *MockQuerierTx) GetUserReconciliations(ctx context.Context, userID int32) ([]Reconciliation, error) {
+	if m.GetUserReconciliationsFunc != nil {
+		return m.GetUserReconciliationsFunc(ctx, userID)
+	}
+	return []Reconciliation{}, nil
+}
+
+func (m *MockQuerierTx) GetUserTags(ctx context.Context, userID int32) ([]Tag, error) {
+	if m.GetUserTagsFunc != nil {
+		return m.GetUserTagsFunc(ctx, userID)
+	}
+	return []Tag{}, nil
+}
+
+func (m *MockQuerierTx) GetUserTransactionRefunds(ctx context.Context, userID int32) ([]TransactionRefund, error) {
+	if m.GetUserTransactionRefundsFunc != nil {
+		return m.GetUserTransactionRefundsFunc(ctx, userID)
+	}
+	return []TransactionRefund{}, nil
+}
+
+func (m *MockQuerierTx) GetUserTransfers(ctx context.Context, userID int32) ([]Transfer, error) {
+	if m.GetUserTransfersFunc != nil {
+		return m.GetUserTransfersFunc(ctx, userID)
+	}
+	return []Transfer{}, nil
+}
+
+func (m *MockQuerierTx) ListReconciliations(ctx context.Context, arg ListReconciliationsParams) ([]Reconciliation, error) {
+	if m.ListReconciliationsFunc != nil {
+		return m.ListReconciliationsFunc(ctx, arg)
+	}
+	return []Reconciliation{}, nil
+}
+
+func (m *MockQuerierTx) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error) {
+	if m.ListTransactionsFunc != nil {
+		return m.ListTransactionsFunc(ctx, arg)
+	}
+	return []ListTransactionsRow{}, nil
+}
+
+func (m *MockQuerierTx) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	if m.ListTransfersFunc != nil {
+		return m.ListTransfersFunc(ctx, arg)
+	}
+	return []Transfer{}, nil
+}
+
+func (m *MockQuerierTx) ReconcileTransactions(ctx context.Context, arg ReconcileTransactionsParams) (int64, error) {
+	if m.ReconcileTransactionsFunc != nil {
+		return m.ReconcileTransactionsFunc(ctx, arg)
+	}
+	return 0, nil
+}
+
+func (m *MockQuerierTx) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error) {
+	if m.SearchTransactionsFunc != nil {
+		return m.SearchTransactionsFunc(ctx, arg)
+	}
+	return []SearchTransactionsRow{}, nil
+}
+
+func (m *MockQuerierTx) SetTransferFee(ctx context.Context, arg SetTransferFeeParams) error {
+	if m.SetTransferFeeFunc != nil {
+		return m.SetTransferFeeFunc(ctx, arg)
+	}
+	return nil
+}
+
+func (m *MockQuerierTx) UnreconcileTransactions(ctx context.Context, arg UnreconcileTransactionsParams) error {
+	if m.UnreconcileTransactionsFunc != nil {
+		return m.UnreconcileTransactionsFunc(ctx, arg)
+	}
+	return nil
+}
+
 func (m *MockQuerierTx) UpdateBalance(ctx context.Context, arg UpdateBalanceParams) (int64, error) {
 	if m.UpdateBalanceFunc != nil {
 		return m.UpdateBalanceFunc(ctx, arg)
@@ -174,6 +644,13 @@ func (m *MockQuerierTx) CreateTransaction(ctx context.Context, arg CreateTransac
 	return Transaction{}, nil
 }
 
+func (m *MockQuerierTx) CreateTransactionWithDate(ctx context.Context, arg CreateTransactionWithDateParams) (Transaction, error) {
+	if m.CreateTransactionWithDateFunc != nil {
+		return m.CreateTransactionWithDateFunc(ctx, arg)
+	}
+	return Transaction{}, nil
+}
+
 func (m *MockQuerierTx) GetAllTransactions(ctx context.Context, userID int32) ([]GetAllTransactionsRow, error) {
 	if m.GetAllTransactionsFunc != nil {
 		return m.GetAllTransactionsFunc(ctx, userID)
@@ -202,6 +679,27 @@ func (m *MockQuerierTx) DeleteTransactionByID(ctx context.Context, arg DeleteTra
 	return NewMockResult(1), nil
 }
 
+func (m *MockQuerierTx) UpdateClearedBalance(ctx context.Context, arg UpdateClearedBalanceParams) (int64, error) {
+	if m.UpdateClearedBalanceFunc != nil {
+		return m.UpdateClearedBalanceFunc(ctx, arg)
+	}
+	return 0, nil
+}
+
+func (m *MockQuerierTx) UpdateImportProfileByID(ctx context.Context, arg UpdateImportProfileByIDParams) (sql.Result, error) {
+	if m.UpdateImportProfileByIDFunc != nil {
+		return m.UpdateImportProfileByIDFunc(ctx, arg)
+	}
+	return nil, nil
+}
+
+func (m *MockQuerierTx) UpdateTagByID(ctx context.Context, arg UpdateTagByIDParams) (sql.Result, error) {
+	if m.UpdateTagByIDFunc != nil {
+		return m.UpdateTagByIDFunc(ctx, arg)
+	}
+	return nil, nil
+}
+
 func (m *MockQuerierTx) UpdateTransactionById(ctx context.Context, arg UpdateTransactionByIdParams) (sql.Result, error) {
 	if m.UpdateTransactionByIdFunc != nil {
 		return m.UpdateTransactionByIdFunc(ctx, arg)
@@ -278,7 +776,7 @@ func (m *MockQuerierTx) DeleteRecurringTransaction(ctx context.Context, arg Dele
 	if m.DeleteRecurringTransactionFunc != nil {
 		return m.DeleteRecurringTransactionFunc(ctx, arg)
 	}
-	return nil, nil
+	return NewMockResult(1), nil
 }
 
 func (m *MockQuerierTx) GetActiveRecurringTransactions(ctx context.Context, arg GetActiveRecurringTransactionsParams) ([]RecurringTransaction, error) {
@@ -323,11 +821,25 @@ func (m *MockQuerierTx) GetUserRecurringTransactions(ctx context.Context, userID
 	return []RecurringTransaction{}, nil
 }
 
+func (m *MockQuerierTx) LockRecurringTransaction(ctx context.Context, id int32) (RecurringTransaction, error) {
+	if m.LockRecurringTransactionFunc != nil {
+		return m.LockRecurringTransactionFunc(ctx, id)
+	}
+	return RecurringTransaction{}, nil
+}
+
 func (m *MockQuerierTx) UpdateRecurringTransaction(ctx context.Context, arg UpdateRecurringTransactionParams) (sql.Result, error) {
 	if m.UpdateRecurringTransactionFunc != nil {
 		return m.UpdateRecurringTransactionFunc(ctx, arg)
 	}
-	return nil, nil
+	return NewMockResult(1), nil
+}
+
+func (m *MockQuerierTx) UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error) {
+	if m.UpsertTagFunc != nil {
+		return m.UpsertTagFunc(ctx, arg)
+	}
+	return Tag{}, nil
 }
 
 // Tx
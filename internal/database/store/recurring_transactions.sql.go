@@ -22,7 +22,7 @@ RETURNING id, created_at, recurring_transaction_id, transaction_id, occurrence_d
 
 type CreateOccurrenceParams struct {
 	RecurringTransactionID int32     `json:"recurring_transaction_id"`
-	TransactionID          int32     `json:"transaction_id"`
+	TransactionID          *int32    `json:"transaction_id"`
 	OccurrenceDate         time.Time `json:"occurrence_date"`
 }
 
@@ -53,9 +53,10 @@ INSERT INTO recurring_transactions (
     day_month,
     day_week,
     max_occurrences,
-    is_active
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-RETURNING id, created_at, updated_at, version, account_id, amount_cents, category_id, title, note, frequency, interval, start_date, end_date, day_month, day_week, max_occurrences, is_active
+    is_active,
+    resumed_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id, created_at, updated_at, version, account_id, amount_cents, category_id, title, note, frequency, interval, start_date, end_date, day_month, day_week, max_occurrences, is_active, resumed_at
 `
 
 type CreateRecurringTransactionParams struct {
@@ -67,11 +68,12 @@ type CreateRecurringTransactionParams struct {
 	Frequency      RecurrenceFrequency `json:"frequency"`
 	Interval       int32               `json:"interval"`
 	StartDate      time.Time           `json:"start_date"`
-	EndDate        sql.NullTime        `json:"end_date"`
-	DayMonth       sql.NullInt32       `json:"day_month"`
-	DayWeek        sql.NullInt32       `json:"day_week"`
-	MaxOccurrences sql.NullInt32       `json:"max_occurrences"`
+	EndDate        *time.Time          `json:"end_date"`
+	DayMonth       *int32              `json:"day_month"`
+	DayWeek        *int32              `json:"day_week"`
+	MaxOccurrences *int32              `json:"max_occurrences"`
 	IsActive       bool                `json:"is_active"`
+	ResumedAt      *time.Time          `json:"resumed_at"`
 }
 
 func (q *Queries) CreateRecurringTransaction(ctx context.Context, arg CreateRecurringTransactionParams) (RecurringTransaction, error) {
@@ -89,6 +91,7 @@ func (q *Queries) CreateRecurringTransaction(ctx context.Context, arg CreateRecu
 		arg.DayWeek,
 		arg.MaxOccurrences,
 		arg.IsActive,
+		arg.ResumedAt,
 	)
 	var i RecurringTransaction
 	err := row.Scan(
@@ -109,6 +112,47 @@ func (q *Queries) CreateRecurringTransaction(ctx context.Context, arg CreateRecu
 		&i.DayWeek,
 		&i.MaxOccurrences,
 		&i.IsActive,
+		&i.ResumedAt,
+	)
+	return i, err
+}
+
+const createRecurringTransactionException = `-- name: CreateRecurringTransactionException :one
+INSERT INTO recurring_transaction_exceptions (
+    recurring_transaction_id,
+    occurrence_date,
+    skip,
+    new_date,
+    amount_cents
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, recurring_transaction_id, occurrence_date, skip, new_date, amount_cents
+`
+
+type CreateRecurringTransactionExceptionParams struct {
+	RecurringTransactionID int32      `json:"recurring_transaction_id"`
+	OccurrenceDate         time.Time  `json:"occurrence_date"`
+	Skip                   bool       `json:"skip"`
+	NewDate                *time.Time `json:"new_date"`
+	AmountCents            *int32     `json:"amount_cents"`
+}
+
+func (q *Queries) CreateRecurringTransactionException(ctx context.Context, arg CreateRecurringTransactionExceptionParams) (RecurringTransactionException, error) {
+	row := q.db.QueryRowContext(ctx, createRecurringTransactionException,
+		arg.RecurringTransactionID,
+		arg.OccurrenceDate,
+		arg.Skip,
+		arg.NewDate,
+		arg.AmountCents,
+	)
+	var i RecurringTransactionException
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.RecurringTransactionID,
+		&i.OccurrenceDate,
+		&i.Skip,
+		&i.NewDate,
+		&i.AmountCents,
 	)
 	return i, err
 }
@@ -130,22 +174,37 @@ func (q *Queries) DeleteRecurringTransaction(ctx context.Context, arg DeleteRecu
 	return q.db.ExecContext(ctx, deleteRecurringTransaction, arg.ID, arg.UserID)
 }
 
+const deleteRecurringTransactionException = `-- name: DeleteRecurringTransactionException :execresult
+DELETE FROM recurring_transaction_exceptions
+WHERE id = $1 AND recurring_transaction_id = $2
+`
+
+type DeleteRecurringTransactionExceptionParams struct {
+	ID                     int32 `json:"id"`
+	RecurringTransactionID int32 `json:"recurring_transaction_id"`
+}
+
+func (q *Queries) DeleteRecurringTransactionException(ctx context.Context, arg DeleteRecurringTransactionExceptionParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteRecurringTransactionException, arg.ID, arg.RecurringTransactionID)
+}
+
 const getActiveRecurringTransactions = `-- name: GetActiveRecurringTransactions :many
-SELECT rt.id, rt.created_at, rt.updated_at, rt.version, rt.account_id, rt.amount_cents, rt.category_id, rt.title, rt.note, rt.frequency, rt.interval, rt.start_date, rt.end_date, rt.day_month, rt.day_week, rt.max_occurrences, rt.is_active FROM recurring_transactions rt
+SELECT rt.id, rt.created_at, rt.updated_at, rt.version, rt.account_id, rt.amount_cents, rt.category_id, rt.title, rt.note, rt.frequency, rt.interval, rt.start_date, rt.end_date, rt.day_month, rt.day_week, rt.max_occurrences, rt.is_active, rt.resumed_at FROM recurring_transactions rt
 INNER JOIN accounts ON rt.account_id = accounts.id
 WHERE accounts.user_id = $1 
   AND rt.is_active = true
-  AND rt.start_date <= $2
-  AND (rt.end_date IS NULL OR rt.end_date >= $2)
+  AND rt.start_date <= $2::timestamp
+  AND (rt.end_date IS NULL OR rt.end_date >= $3::timestamp)
 `
 
 type GetActiveRecurringTransactionsParams struct {
-	UserID    int32     `json:"user_id"`
-	StartDate time.Time `json:"start_date"`
+	UserID     int32     `json:"user_id"`
+	Now        time.Time `json:"now"`
+	EndedAfter time.Time `json:"ended_after"`
 }
 
 func (q *Queries) GetActiveRecurringTransactions(ctx context.Context, arg GetActiveRecurringTransactionsParams) ([]RecurringTransaction, error) {
-	rows, err := q.db.QueryContext(ctx, getActiveRecurringTransactions, arg.UserID, arg.StartDate)
+	rows, err := q.db.QueryContext(ctx, getActiveRecurringTransactions, arg.UserID, arg.Now, arg.EndedAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -171,6 +230,7 @@ func (q *Queries) GetActiveRecurringTransactions(ctx context.Context, arg GetAct
 			&i.DayWeek,
 			&i.MaxOccurrences,
 			&i.IsActive,
+			&i.ResumedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -186,9 +246,15 @@ func (q *Queries) GetActiveRecurringTransactions(ctx context.Context, arg GetAct
 }
 
 const getLastOccurrence = `-- name: GetLastOccurrence :one
-SELECT id, created_at, recurring_transaction_id, transaction_id, occurrence_date FROM recurring_transaction_occurrences
-WHERE recurring_transaction_id = $1
-ORDER BY occurrence_date DESC
+SELECT o.id, o.created_at, o.recurring_transaction_id, o.transaction_id, o.occurrence_date FROM recurring_transaction_occurrences o
+WHERE o.recurring_transaction_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM recurring_transaction_exceptions e
+    WHERE e.recurring_transaction_id = o.recurring_transaction_id
+      AND e.occurrence_date = o.occurrence_date
+      AND e.new_date IS NOT NULL
+  )
+ORDER BY o.occurrence_date DESC
 LIMIT 1
 `
 
@@ -263,7 +329,7 @@ func (q *Queries) GetOccurrences(ctx context.Context, recurringTransactionID int
 }
 
 const getRecurringTransactionByID = `-- name: GetRecurringTransactionByID :one
-SELECT rt.id, rt.created_at, rt.updated_at, rt.version, rt.account_id, rt.amount_cents, rt.category_id, rt.title, rt.note, rt.frequency, rt.interval, rt.start_date, rt.end_date, rt.day_month, rt.day_week, rt.max_occurrences, rt.is_active FROM recurring_transactions rt
+SELECT rt.id, rt.created_at, rt.updated_at, rt.version, rt.account_id, rt.amount_cents, rt.category_id, rt.title, rt.note, rt.frequency, rt.interval, rt.start_date, rt.end_date, rt.day_month, rt.day_week, rt.max_occurrences, rt.is_active, rt.resumed_at FROM recurring_transactions rt
 INNER JOIN accounts ON rt.account_id = accounts.id
 WHERE rt.id = $1 AND accounts.user_id = $2
 `
@@ -294,12 +360,50 @@ func (q *Queries) GetRecurringTransactionByID(ctx context.Context, arg GetRecurr
 		&i.DayWeek,
 		&i.MaxOccurrences,
 		&i.IsActive,
+		&i.ResumedAt,
 	)
 	return i, err
 }
 
+const getRecurringTransactionExceptions = `-- name: GetRecurringTransactionExceptions :many
+SELECT id, created_at, recurring_transaction_id, occurrence_date, skip, new_date, amount_cents FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1
+ORDER BY occurrence_date
+`
+
+func (q *Queries) GetRecurringTransactionExceptions(ctx context.Context, recurringTransactionID int32) ([]RecurringTransactionException, error) {
+	rows, err := q.db.QueryContext(ctx, getRecurringTransactionExceptions, recurringTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecurringTransactionException
+	for rows.Next() {
+		var i RecurringTransactionException
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.RecurringTransactionID,
+			&i.OccurrenceDate,
+			&i.Skip,
+			&i.NewDate,
+			&i.AmountCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserRecurringTransactions = `-- name: GetUserRecurringTransactions :many
-SELECT rt.id, rt.created_at, rt.updated_at, rt.version, rt.account_id, rt.amount_cents, rt.category_id, rt.title, rt.note, rt.frequency, rt.interval, rt.start_date, rt.end_date, rt.day_month, rt.day_week, rt.max_occurrences, rt.is_active FROM recurring_transactions rt
+SELECT rt.id, rt.created_at, rt.updated_at, rt.version, rt.account_id, rt.amount_cents, rt.category_id, rt.title, rt.note, rt.frequency, rt.interval, rt.start_date, rt.end_date, rt.day_month, rt.day_week, rt.max_occurrences, rt.is_active, rt.resumed_at FROM recurring_transactions rt
 INNER JOIN accounts ON rt.account_id = accounts.id
 WHERE accounts.user_id = $1
 `
@@ -331,6 +435,7 @@ func (q *Queries) GetUserRecurringTransactions(ctx context.Context, userID int32
 			&i.DayWeek,
 			&i.MaxOccurrences,
 			&i.IsActive,
+			&i.ResumedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -345,6 +450,38 @@ func (q *Queries) GetUserRecurringTransactions(ctx context.Context, userID int32
 	return items, nil
 }
 
+const lockRecurringTransaction = `-- name: LockRecurringTransaction :one
+SELECT id, created_at, updated_at, version, account_id, amount_cents, category_id, title, note, frequency, interval, start_date, end_date, day_month, day_week, max_occurrences, is_active, resumed_at FROM recurring_transactions
+WHERE id = $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) LockRecurringTransaction(ctx context.Context, id int32) (RecurringTransaction, error) {
+	row := q.db.QueryRowContext(ctx, lockRecurringTransaction, id)
+	var i RecurringTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.AccountID,
+		&i.AmountCents,
+		&i.CategoryID,
+		&i.Title,
+		&i.Note,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartDate,
+		&i.EndDate,
+		&i.DayMonth,
+		&i.DayWeek,
+		&i.MaxOccurrences,
+		&i.IsActive,
+		&i.ResumedAt,
+	)
+	return i, err
+}
+
 const updateRecurringTransaction = `-- name: UpdateRecurringTransaction :execresult
 UPDATE recurring_transactions
 SET amount_cents = $1,
@@ -358,13 +495,14 @@ SET amount_cents = $1,
     day_week = $9,
     max_occurrences = $10,
     is_active = $11,
-    version = version + 1,
+    resumed_at = $12,
+    version = recurring_transactions.version + 1,
     updated_at = NOW()
 FROM accounts
 WHERE recurring_transactions.account_id = accounts.id
-  AND recurring_transactions.id = $12
-  AND accounts.user_id = $13
-  AND recurring_transactions.version = $14
+  AND recurring_transactions.id = $13
+  AND accounts.user_id = $14
+  AND recurring_transactions.version = $15
 `
 
 type UpdateRecurringTransactionParams struct {
@@ -374,11 +512,12 @@ type UpdateRecurringTransactionParams struct {
 	Note           string              `json:"note"`
 	Frequency      RecurrenceFrequency `json:"frequency"`
 	Interval       int32               `json:"interval"`
-	EndDate        sql.NullTime        `json:"end_date"`
-	DayMonth       sql.NullInt32       `json:"day_month"`
-	DayWeek        sql.NullInt32       `json:"day_week"`
-	MaxOccurrences sql.NullInt32       `json:"max_occurrences"`
+	EndDate        *time.Time          `json:"end_date"`
+	DayMonth       *int32              `json:"day_month"`
+	DayWeek        *int32              `json:"day_week"`
+	MaxOccurrences *int32              `json:"max_occurrences"`
 	IsActive       bool                `json:"is_active"`
+	ResumedAt      *time.Time          `json:"resumed_at"`
 	ID             int32               `json:"id"`
 	UserID         int32               `json:"user_id"`
 	Version        int32               `json:"-"`
@@ -397,6 +536,7 @@ func (q *Queries) UpdateRecurringTransaction(ctx context.Context, arg UpdateRecu
 		arg.DayWeek,
 		arg.MaxOccurrences,
 		arg.IsActive,
+		arg.ResumedAt,
 		arg.ID,
 		arg.UserID,
 		arg.Version,
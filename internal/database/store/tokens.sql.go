@@ -11,20 +11,31 @@ import (
 )
 
 const createToken = `-- name: CreateToken :one
-INSERT INTO tokens (hash, user_id, expiry)
-VALUES ($1, $2, $3)
-RETURNING hash, user_id, expiry
+INSERT INTO tokens (hash, user_id, expiry, scope)
+VALUES ($1, $2, $3, $4)
+RETURNING hash, user_id, expiry, scope
 `
 
 type CreateTokenParams struct {
 	Hash   []byte    `json:"hash"`
 	UserID int32     `json:"user_id"`
 	Expiry time.Time `json:"expiry"`
+	Scope  string    `json:"scope"`
 }
 
 func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (Token, error) {
-	row := q.db.QueryRowContext(ctx, createToken, arg.Hash, arg.UserID, arg.Expiry)
+	row := q.db.QueryRowContext(ctx, createToken,
+		arg.Hash,
+		arg.UserID,
+		arg.Expiry,
+		arg.Scope,
+	)
 	var i Token
-	err := row.Scan(&i.Hash, &i.UserID, &i.Expiry)
+	err := row.Scan(
+		&i.Hash,
+		&i.UserID,
+		&i.Expiry,
+		&i.Scope,
+	)
 	return i, err
 }
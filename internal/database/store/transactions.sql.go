@@ -9,21 +9,24 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 const createTransaction = `-- name: CreateTransaction :one
-INSERT INTO transactions (account_id, amount_cents, category_id, title, attachment, note)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_at, updated_at, amount_cents, account_id, category_id, title, date, attachment, note, version
+INSERT INTO transactions (account_id, amount_cents, category_id, title, attachment, note, status)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::transaction_status, 'cleared'))
+RETURNING id, created_at, updated_at, amount_cents, account_id, category_id, title, date, attachment, note, version, status
 `
 
 type CreateTransactionParams struct {
-	AccountID   int32  `json:"account_id"`
-	AmountCents int64  `json:"amount_cents"`
-	CategoryID  int32  `json:"category_id"`
-	Title       string `json:"title"`
-	Attachment  string `json:"attachment"`
-	Note        string `json:"note"`
+	AccountID   int32                 `json:"account_id"`
+	AmountCents int64                 `json:"amount_cents"`
+	CategoryID  int32                 `json:"category_id"`
+	Title       string                `json:"title"`
+	Attachment  string                `json:"attachment"`
+	Note        string                `json:"note"`
+	Status      NullTransactionStatus `json:"status"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -34,6 +37,53 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Title,
 		arg.Attachment,
 		arg.Note,
+		arg.Status,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AmountCents,
+		&i.AccountID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Date,
+		&i.Attachment,
+		&i.Note,
+		&i.Version,
+		&i.Status,
+	)
+	return i, err
+}
+
+const createTransactionWithDate = `-- name: CreateTransactionWithDate :one
+INSERT INTO transactions (account_id, amount_cents, category_id, title, attachment, note, date, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::transaction_status, 'cleared'))
+RETURNING id, created_at, updated_at, amount_cents, account_id, category_id, title, date, attachment, note, version, status
+`
+
+type CreateTransactionWithDateParams struct {
+	AccountID   int32                 `json:"account_id"`
+	AmountCents int64                 `json:"amount_cents"`
+	CategoryID  int32                 `json:"category_id"`
+	Title       string                `json:"title"`
+	Attachment  string                `json:"attachment"`
+	Note        string                `json:"note"`
+	Date        time.Time             `json:"date"`
+	Status      NullTransactionStatus `json:"status"`
+}
+
+func (q *Queries) CreateTransactionWithDate(ctx context.Context, arg CreateTransactionWithDateParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, createTransactionWithDate,
+		arg.AccountID,
+		arg.AmountCents,
+		arg.CategoryID,
+		arg.Title,
+		arg.Attachment,
+		arg.Note,
+		arg.Date,
+		arg.Status,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -48,6 +98,7 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.Attachment,
 		&i.Note,
 		&i.Version,
+		&i.Status,
 	)
 	return i, err
 }
@@ -70,7 +121,7 @@ func (q *Queries) DeleteTransactionByID(ctx context.Context, arg DeleteTransacti
 }
 
 const getAllTransactions = `-- name: GetAllTransactions :many
-SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version FROM transactions
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status FROM transactions
 INNER JOIN accounts ON transactions.account_id = accounts.id
 WHERE accounts.user_id = $1
 `
@@ -100,6 +151,64 @@ func (q *Queries) GetAllTransactions(ctx context.Context, userID int32) ([]GetAl
 			&i.Transaction.Attachment,
 			&i.Transaction.Note,
 			&i.Transaction.Version,
+			&i.Transaction.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDuplicateCandidates = `-- name: GetDuplicateCandidates :many
+SELECT id, created_at, updated_at, amount_cents, account_id, category_id, title, date, attachment, note, version, status FROM transactions
+WHERE account_id = $1
+  AND date >= $2::timestamp
+  AND date <= $3::timestamp
+  AND amount_cents = ANY($4::bigint[])
+ORDER BY date, id
+`
+
+type GetDuplicateCandidatesParams struct {
+	AccountID int32     `json:"account_id"`
+	DateFrom  time.Time `json:"date_from"`
+	DateTo    time.Time `json:"date_to"`
+	Amounts   []int64   `json:"amounts"`
+}
+
+func (q *Queries) GetDuplicateCandidates(ctx context.Context, arg GetDuplicateCandidatesParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, getDuplicateCandidates,
+		arg.AccountID,
+		arg.DateFrom,
+		arg.DateTo,
+		pq.Array(arg.Amounts),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AmountCents,
+			&i.AccountID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Date,
+			&i.Attachment,
+			&i.Note,
+			&i.Version,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -115,7 +224,7 @@ func (q *Queries) GetAllTransactions(ctx context.Context, userID int32) ([]GetAl
 }
 
 const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version FROM transactions
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status FROM transactions
 INNER JOIN accounts ON transactions.account_id = accounts.id
 WHERE transactions.id = $1 AND accounts.user_id = $2
 `
@@ -144,12 +253,49 @@ func (q *Queries) GetTransactionByID(ctx context.Context, arg GetTransactionByID
 		&i.Transaction.Attachment,
 		&i.Transaction.Note,
 		&i.Transaction.Version,
+		&i.Transaction.Status,
+	)
+	return i, err
+}
+
+const getTransactionByIDForUpdate = `-- name: GetTransactionByIDForUpdate :one
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE transactions.id = $1 AND accounts.user_id = $2
+FOR UPDATE OF transactions
+`
+
+type GetTransactionByIDForUpdateParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+type GetTransactionByIDForUpdateRow struct {
+	Transaction Transaction `json:"transaction"`
+}
+
+func (q *Queries) GetTransactionByIDForUpdate(ctx context.Context, arg GetTransactionByIDForUpdateParams) (GetTransactionByIDForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, getTransactionByIDForUpdate, arg.ID, arg.UserID)
+	var i GetTransactionByIDForUpdateRow
+	err := row.Scan(
+		&i.Transaction.ID,
+		&i.Transaction.CreatedAt,
+		&i.Transaction.UpdatedAt,
+		&i.Transaction.AmountCents,
+		&i.Transaction.AccountID,
+		&i.Transaction.CategoryID,
+		&i.Transaction.Title,
+		&i.Transaction.Date,
+		&i.Transaction.Attachment,
+		&i.Transaction.Note,
+		&i.Transaction.Version,
+		&i.Transaction.Status,
 	)
 	return i, err
 }
 
 const getTransactionsByAccountID = `-- name: GetTransactionsByAccountID :many
-SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version FROM transactions
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status FROM transactions
 INNER JOIN accounts ON transactions.account_id = accounts.id
 WHERE transactions.account_id = $1 AND accounts.user_id = $2
 `
@@ -184,6 +330,249 @@ func (q *Queries) GetTransactionsByAccountID(ctx context.Context, arg GetTransac
 			&i.Transaction.Attachment,
 			&i.Transaction.Note,
 			&i.Transaction.Version,
+			&i.Transaction.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsByIDs = `-- name: GetTransactionsByIDs :many
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE transactions.id = ANY($1::int[]) AND accounts.user_id = $2
+`
+
+type GetTransactionsByIDsParams struct {
+	Ids    []int32 `json:"ids"`
+	UserID int32   `json:"user_id"`
+}
+
+type GetTransactionsByIDsRow struct {
+	Transaction Transaction `json:"transaction"`
+}
+
+func (q *Queries) GetTransactionsByIDs(ctx context.Context, arg GetTransactionsByIDsParams) ([]GetTransactionsByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionsByIDs, pq.Array(arg.Ids), arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTransactionsByIDsRow
+	for rows.Next() {
+		var i GetTransactionsByIDsRow
+		if err := rows.Scan(
+			&i.Transaction.ID,
+			&i.Transaction.CreatedAt,
+			&i.Transaction.UpdatedAt,
+			&i.Transaction.AmountCents,
+			&i.Transaction.AccountID,
+			&i.Transaction.CategoryID,
+			&i.Transaction.Title,
+			&i.Transaction.Date,
+			&i.Transaction.Attachment,
+			&i.Transaction.Note,
+			&i.Transaction.Version,
+			&i.Transaction.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactions = `-- name: ListTransactions :many
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = $1
+  AND (coalesce(cardinality($2::int[]), 0) = 0 OR transactions.account_id = ANY($2::int[]))
+  AND (coalesce(cardinality($3::int[]), 0) = 0 OR transactions.category_id = ANY($3::int[]))
+  AND (coalesce(cardinality($4::int[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM transaction_tags
+    WHERE transaction_tags.transaction_id = transactions.id
+      AND transaction_tags.tag_id = ANY($4::int[])
+  ))
+  AND ($5::timestamp IS NULL OR transactions.date >= $5::timestamp)
+  AND ($6::timestamp IS NULL OR transactions.date < $6::timestamp)
+  AND ($7::bigint IS NULL OR transactions.amount_cents >= $7::bigint)
+  AND ($8::bigint IS NULL OR transactions.amount_cents <= $8::bigint)
+  AND ($9::int IS NULL OR (sign(transactions.amount_cents) = $9::int AND NOT EXISTS (
+    SELECT 1 FROM transfers
+    WHERE transactions.id IN (transfers.from_transaction_id, transfers.to_transaction_id)
+  )))
+  AND ($10::transaction_status IS NULL OR transactions.status = $10::transaction_status)
+  AND ($11::int IS NULL OR CASE
+    WHEN $12::text = 'amount' AND $13::bool
+      THEN (transactions.amount_cents, transactions.id) < ($14::bigint, $11::int)
+    WHEN $12::text = 'amount'
+      THEN (transactions.amount_cents, transactions.id) > ($14::bigint, $11::int)
+    WHEN $13::bool
+      THEN (transactions.date, transactions.id) < ($15::timestamp, $11::int)
+    ELSE (transactions.date, transactions.id) > ($15::timestamp, $11::int)
+  END)
+ORDER BY
+  CASE WHEN $12::text = 'amount' AND NOT $13::bool THEN transactions.amount_cents END ASC,
+  CASE WHEN $12::text = 'amount' AND $13::bool THEN transactions.amount_cents END DESC,
+  CASE WHEN $12::text <> 'amount' AND NOT $13::bool THEN transactions.date END ASC,
+  CASE WHEN $12::text <> 'amount' AND $13::bool THEN transactions.date END DESC,
+  CASE WHEN NOT $13::bool THEN transactions.id END ASC,
+  CASE WHEN $13::bool THEN transactions.id END DESC
+LIMIT $16
+`
+
+type ListTransactionsParams struct {
+	UserID            int32                 `json:"user_id"`
+	AccountIds        []int32               `json:"account_ids"`
+	CategoryIds       []int32               `json:"category_ids"`
+	TagIds            []int32               `json:"tag_ids"`
+	DateFrom          sql.NullTime          `json:"date_from"`
+	DateTo            sql.NullTime          `json:"date_to"`
+	MinAmountCents    sql.NullInt64         `json:"min_amount_cents"`
+	MaxAmountCents    sql.NullInt64         `json:"max_amount_cents"`
+	Sign              sql.NullInt32         `json:"sign"`
+	Status            NullTransactionStatus `json:"status"`
+	CursorID          sql.NullInt32         `json:"cursor_id"`
+	Sort              string                `json:"sort"`
+	Descending        bool                  `json:"descending"`
+	CursorAmountCents sql.NullInt64         `json:"cursor_amount_cents"`
+	CursorDate        sql.NullTime          `json:"cursor_date"`
+	PageSize          int32                 `json:"page_size"`
+}
+
+type ListTransactionsRow struct {
+	Transaction Transaction `json:"transaction"`
+}
+
+func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactions,
+		arg.UserID,
+		pq.Array(arg.AccountIds),
+		pq.Array(arg.CategoryIds),
+		pq.Array(arg.TagIds),
+		arg.DateFrom,
+		arg.DateTo,
+		arg.MinAmountCents,
+		arg.MaxAmountCents,
+		arg.Sign,
+		arg.Status,
+		arg.CursorID,
+		arg.Sort,
+		arg.Descending,
+		arg.CursorAmountCents,
+		arg.CursorDate,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransactionsRow
+	for rows.Next() {
+		var i ListTransactionsRow
+		if err := rows.Scan(
+			&i.Transaction.ID,
+			&i.Transaction.CreatedAt,
+			&i.Transaction.UpdatedAt,
+			&i.Transaction.AmountCents,
+			&i.Transaction.AccountID,
+			&i.Transaction.CategoryID,
+			&i.Transaction.Title,
+			&i.Transaction.Date,
+			&i.Transaction.Attachment,
+			&i.Transaction.Note,
+			&i.Transaction.Version,
+			&i.Transaction.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransactions = `-- name: SearchTransactions :many
+SELECT transactions.id, transactions.created_at, transactions.updated_at, transactions.amount_cents, transactions.account_id, transactions.category_id, transactions.title, transactions.date, transactions.attachment, transactions.note, transactions.version, transactions.status,
+  ts_rank(
+    setweight(to_tsvector('english', transactions.title), 'A') ||
+    setweight(to_tsvector('english', transactions.note), 'B'),
+    to_tsquery('english', $1)
+  )::real AS rank
+FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = $2
+  AND to_tsvector('english', transactions.title || ' ' || transactions.note) @@ to_tsquery('english', $1)
+  AND (coalesce(cardinality($3::int[]), 0) = 0 OR transactions.account_id = ANY($3::int[]))
+  AND ($4::timestamp IS NULL OR transactions.date >= $4::timestamp)
+  AND ($5::timestamp IS NULL OR transactions.date < $5::timestamp)
+ORDER BY rank DESC, transactions.date DESC, transactions.id DESC
+LIMIT $6
+`
+
+type SearchTransactionsParams struct {
+	Query      string       `json:"query"`
+	UserID     int32        `json:"user_id"`
+	AccountIds []int32      `json:"account_ids"`
+	DateFrom   sql.NullTime `json:"date_from"`
+	DateTo     sql.NullTime `json:"date_to"`
+	MaxResults int32        `json:"max_results"`
+}
+
+type SearchTransactionsRow struct {
+	Transaction Transaction `json:"transaction"`
+	Rank        float32     `json:"rank"`
+}
+
+func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransactions,
+		arg.Query,
+		arg.UserID,
+		pq.Array(arg.AccountIds),
+		arg.DateFrom,
+		arg.DateTo,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchTransactionsRow
+	for rows.Next() {
+		var i SearchTransactionsRow
+		if err := rows.Scan(
+			&i.Transaction.ID,
+			&i.Transaction.CreatedAt,
+			&i.Transaction.UpdatedAt,
+			&i.Transaction.AmountCents,
+			&i.Transaction.AccountID,
+			&i.Transaction.CategoryID,
+			&i.Transaction.Title,
+			&i.Transaction.Date,
+			&i.Transaction.Attachment,
+			&i.Transaction.Note,
+			&i.Transaction.Version,
+			&i.Transaction.Status,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -207,26 +596,28 @@ SET amount_cents = $1,
     date = $5,
     attachment = $6,
     note = $7,
+    status = $8,
     version = transactions.version + 1,
     updated_at = NOW()
 FROM accounts
 WHERE transactions.account_id = accounts.id
-  AND transactions.id = $8
-  AND accounts.user_id = $9
-  AND transactions.version = $10
+  AND transactions.id = $9
+  AND accounts.user_id = $10
+  AND transactions.version = $11
 `
 
 type UpdateTransactionByIdParams struct {
-	AmountCents int64     `json:"amount_cents"`
-	AccountID   int32     `json:"account_id"`
-	CategoryID  int32     `json:"category_id"`
-	Title       string    `json:"title"`
-	Date        time.Time `json:"date"`
-	Attachment  string    `json:"attachment"`
-	Note        string    `json:"note"`
-	ID          int32     `json:"id"`
-	UserID      int32     `json:"user_id"`
-	Version     int32     `json:"-"`
+	AmountCents int64             `json:"amount_cents"`
+	AccountID   int32             `json:"account_id"`
+	CategoryID  int32             `json:"category_id"`
+	Title       string            `json:"title"`
+	Date        time.Time         `json:"date"`
+	Attachment  string            `json:"attachment"`
+	Note        string            `json:"note"`
+	Status      TransactionStatus `json:"status"`
+	ID          int32             `json:"id"`
+	UserID      int32             `json:"user_id"`
+	Version     int32             `json:"-"`
 }
 
 func (q *Queries) UpdateTransactionById(ctx context.Context, arg UpdateTransactionByIdParams) (sql.Result, error) {
@@ -238,6 +629,7 @@ func (q *Queries) UpdateTransactionById(ctx context.Context, arg UpdateTransacti
 		arg.Date,
 		arg.Attachment,
 		arg.Note,
+		arg.Status,
 		arg.ID,
 		arg.UserID,
 		arg.Version,
@@ -129,11 +129,13 @@ INNER JOIN tokens
 ON users.id = tokens.user_id
 WHERE tokens.hash = $1
 AND tokens.expiry > $2
+AND tokens.scope = $3
 `
 
 type GetUserFromTokenParams struct {
 	Hash   []byte    `json:"hash"`
 	Expiry time.Time `json:"expiry"`
+	Scope  string    `json:"scope"`
 }
 
 type GetUserFromTokenRow struct {
@@ -142,7 +144,7 @@ type GetUserFromTokenRow struct {
 }
 
 func (q *Queries) GetUserFromToken(ctx context.Context, arg GetUserFromTokenParams) (GetUserFromTokenRow, error) {
-	row := q.db.QueryRowContext(ctx, getUserFromToken, arg.Hash, arg.Expiry)
+	row := q.db.QueryRowContext(ctx, getUserFromToken, arg.Hash, arg.Expiry, arg.Scope)
 	var i GetUserFromTokenRow
 	err := row.Scan(&i.ID, &i.Username)
 	return i, err
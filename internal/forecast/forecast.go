@@ -0,0 +1,113 @@
+// Package forecast projects account balances into the future from their
+// recurring transactions.
+package forecast
+
+import (
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/recurrence"
+)
+
+// Day is the projected state at the end of a single day.
+type Day struct {
+	Date         time.Time `json:"date"`
+	ChangeCents  int64     `json:"change_cents"`
+	BalanceCents int64     `json:"balance_cents"`
+}
+
+type Account struct {
+	AccountID         int32             `json:"account_id"`
+	Name              string            `json:"name"`
+	Type              store.AccountType `json:"type"`
+	BalanceCents      int64             `json:"balance_cents"`
+	FirstNegativeDate *time.Time        `json:"first_negative_date"`
+	Days              []Day             `json:"days"`
+}
+
+// Forecast is the projection for several accounts, with Days holding the total
+// across all of them.
+type Forecast struct {
+	Accounts          []*Account `json:"accounts"`
+	FirstNegativeDate *time.Time `json:"first_negative_date"`
+	Days              []Day      `json:"days"`
+}
+
+// Rule is a recurring transaction together with its exceptions.
+type Rule struct {
+	Transaction *store.RecurringTransaction
+	Exceptions  []store.RecurringTransactionException
+}
+
+// canOverdraw reports whether a negative balance is expected for the account
+// type, in which case it isn't flagged.
+func canOverdraw(accountType store.AccountType) bool {
+	return accountType == store.AccountTypeCredit
+}
+
+// ForAccount projects the balance of account for every day in the inclusive
+// range [from, until], applying the rules that belong to it. The projection
+// starts from the account's current balance.
+func ForAccount(account *store.Account, rules []Rule, from, until time.Time) *Account {
+	from, until = recurrence.Date(from), recurrence.Date(until)
+
+	changes := make(map[time.Time]int64)
+	for _, rule := range rules {
+		if rule.Transaction.AccountID != account.ID {
+			continue
+		}
+		for _, occurrence := range recurrence.OccurrencesBetween(rule.Transaction, rule.Exceptions, from, until) {
+			changes[occurrence.Date] += int64(occurrence.AmountCents)
+		}
+	}
+
+	forecast := &Account{
+		AccountID:    account.ID,
+		Name:         account.Name,
+		Type:         account.Type,
+		BalanceCents: account.BalanceCents,
+		Days:         []Day{},
+	}
+
+	balance := account.BalanceCents
+	for date := from; !date.After(until); date = date.AddDate(0, 0, 1) {
+		balance += changes[date]
+		forecast.Days = append(forecast.Days, Day{
+			Date:         date,
+			ChangeCents:  changes[date],
+			BalanceCents: balance,
+		})
+
+		if balance < 0 && forecast.FirstNegativeDate == nil && !canOverdraw(account.Type) {
+			negative := date
+			forecast.FirstNegativeDate = &negative
+		}
+	}
+
+	return forecast
+}
+
+// Combine adds up account forecasts that cover the same range of days.
+func Combine(accounts []*Account) *Forecast {
+	forecast := &Forecast{
+		Accounts: accounts,
+		Days:     []Day{},
+	}
+
+	for _, account := range accounts {
+		for i, day := range account.Days {
+			if i == len(forecast.Days) {
+				forecast.Days = append(forecast.Days, Day{Date: day.Date})
+			}
+			forecast.Days[i].ChangeCents += day.ChangeCents
+			forecast.Days[i].BalanceCents += day.BalanceCents
+		}
+
+		date := account.FirstNegativeDate
+		if date != nil && (forecast.FirstNegativeDate == nil || date.Before(*forecast.FirstNegativeDate)) {
+			forecast.FirstNegativeDate = date
+		}
+	}
+
+	return forecast
+}
@@ -0,0 +1,148 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestForAccount(t *testing.T) {
+	account := &store.Account{ID: 1, Name: "Checking", Type: store.AccountTypeDebit, BalanceCents: 1000}
+
+	rules := []Rule{
+		{
+			Transaction: &store.RecurringTransaction{
+				AccountID:   1,
+				AmountCents: -400,
+				Frequency:   store.RecurrenceFrequencyDaily,
+				Interval:    2,
+				StartDate:   date("2025-01-01"),
+			},
+			Exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-03"), AmountCents: ptr[int32](-100)},
+			},
+		},
+		{
+			Transaction: &store.RecurringTransaction{
+				AccountID:   1,
+				AmountCents: 50,
+				Frequency:   store.RecurrenceFrequencyDaily,
+				Interval:    1,
+				StartDate:   date("2025-01-04"),
+			},
+		},
+		{
+			Transaction: &store.RecurringTransaction{
+				AccountID:   2,
+				AmountCents: -99999,
+				Frequency:   store.RecurrenceFrequencyDaily,
+				Interval:    1,
+				StartDate:   date("2025-01-01"),
+			},
+		},
+	}
+
+	forecast := ForAccount(account, rules, date("2025-01-02"), date("2025-01-07"))
+
+	want := []struct {
+		date    string
+		change  int64
+		balance int64
+	}{
+		{"2025-01-02", 0, 1000},
+		{"2025-01-03", -100, 900},
+		{"2025-01-04", 50, 950},
+		{"2025-01-05", -350, 600},
+		{"2025-01-06", 50, 650},
+		{"2025-01-07", -350, 300},
+	}
+
+	assert.Equal(t, len(forecast.Days), len(want))
+	for i, day := range forecast.Days {
+		assert.Equal(t, day.Date.Format(time.DateOnly), want[i].date)
+		assert.Equal(t, day.ChangeCents, want[i].change)
+		assert.Equal(t, day.BalanceCents, want[i].balance)
+	}
+	assert.Equal(t, forecast.BalanceCents, 1000)
+	assert.Equal(t, forecast.FirstNegativeDate == nil, true)
+}
+
+func TestForAccount_FirstNegativeDate(t *testing.T) {
+	rules := []Rule{
+		{
+			Transaction: &store.RecurringTransaction{
+				AccountID:   1,
+				AmountCents: -300,
+				Frequency:   store.RecurrenceFrequencyWeekly,
+				Interval:    1,
+				StartDate:   date("2025-01-01"),
+				DayWeek:     ptr[int32](5),
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		accountType store.AccountType
+		want        string
+	}{
+		{"debit", store.AccountTypeDebit, "2025-01-10"},
+		{"cash", store.AccountTypeCash, "2025-01-10"},
+		{"credit is not flagged", store.AccountTypeCredit, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &store.Account{ID: 1, Type: tt.accountType, BalanceCents: 500}
+			forecast := ForAccount(account, rules, date("2025-01-01"), date("2025-01-31"))
+
+			got := ""
+			if forecast.FirstNegativeDate != nil {
+				got = forecast.FirstNegativeDate.Format(time.DateOnly)
+			}
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	debit := &Account{
+		AccountID:         1,
+		FirstNegativeDate: ptr(date("2025-01-02")),
+		Days: []Day{
+			{Date: date("2025-01-01"), ChangeCents: -10, BalanceCents: 5},
+			{Date: date("2025-01-02"), ChangeCents: -10, BalanceCents: -5},
+		},
+	}
+	cash := &Account{
+		AccountID:         2,
+		FirstNegativeDate: ptr(date("2025-01-01")),
+		Days: []Day{
+			{Date: date("2025-01-01"), ChangeCents: 20, BalanceCents: -1},
+			{Date: date("2025-01-02"), ChangeCents: 0, BalanceCents: -1},
+		},
+	}
+
+	forecast := Combine([]*Account{debit, cash})
+
+	assert.Equal(t, len(forecast.Accounts), 2)
+	assert.Equal(t, len(forecast.Days), 2)
+	assert.Equal(t, forecast.Days[0].ChangeCents, 10)
+	assert.Equal(t, forecast.Days[0].BalanceCents, 4)
+	assert.Equal(t, forecast.Days[1].BalanceCents, -6)
+	assert.Equal(t, forecast.FirstNegativeDate.Format(time.DateOnly), "2025-01-01")
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/internal/service"
 	"github.com/Quak1/gokei/internal/testutils"
@@ -22,7 +23,7 @@ func setupTestAccountHandler(t *testing.T) (*AccountHandler, *service.Service, f
 		t.Fatalf("test db setup failed: %v", err)
 	}
 
-	svc := service.New(db)
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
 	handler := NewAccountHandler(svc.Account)
 
 	return handler, svc, cleanup
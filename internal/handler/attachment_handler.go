@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+// maxAttachmentRequestBytes leaves room for a few files of the largest size
+// allowed in one upload, along with the multipart overhead.
+const maxAttachmentRequestBytes = 5*service.MaxAttachmentBytes + 1<<20
+
+// uploadTimeout replaces the server's timeouts for an upload, which can't
+// arrive within them at the sizes allowed.
+const uploadTimeout = 10 * time.Minute
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+}
+
+func NewAttachmentHandler(svc *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: svc,
+	}
+}
+
+func (h *AttachmentHandler) attachmentError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *validator.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response.FailedValidationResponse(w, r, validationErr)
+	case errors.Is(err, database.ErrRecordNotFound):
+		response.NotFoundResponse(w, r)
+	default:
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+// Create attaches the files sent in the "file" parts of a
+// multipart/form-data body. Several files can be sent at once by repeating
+// the part.
+func (h *AttachmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := readIntParam(r, "transactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	// The write deadline runs from the start of the request too, so it is
+	// extended along with the read one.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(uploadTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(uploadTimeout))
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentRequestBytes)
+
+	err = r.ParseMultipartForm(service.MaxAttachmentBytes)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			response.BadRequestResponse(w, r, fmt.Errorf("Body must not be larger than %d bytes", maxBytesError.Limit))
+			return
+		}
+		response.BadRequestResponse(w, r, errors.New("Body must be multipart/form-data"))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	headers := r.MultipartForm.File["file"]
+	uploads := make([]service.AttachmentUpload, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			response.ServerErrorResponse(w, r, err)
+			return
+		}
+		defer file.Close()
+
+		uploads = append(uploads, service.AttachmentUpload{
+			Filename: header.Filename,
+			Size:     header.Size,
+			Content:  file,
+		})
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	attachments, err := h.attachmentService.Create(ctxUser.ID, int32(transactionID), uploads)
+	if err != nil {
+		h.attachmentError(w, r, err)
+		return
+	}
+
+	err = response.Created(w, response.Envelope{"attachments": attachments}, nil)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *AttachmentHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := readIntParam(r, "transactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	attachments, err := h.attachmentService.GetAll(ctxUser.ID, int32(transactionID))
+	if err != nil {
+		h.attachmentError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"attachments": attachments})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+// Download serves the content of an attachment. It is always sent as a
+// download with the sniffed type, so browsers neither render it inline nor
+// guess another type for it.
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := readIntParam(r, "transactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+	attachmentID, err := readIntParam(r, "attachmentID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	attachment, content, err := h.attachmentService.Open(ctxUser.ID, int32(transactionID), int32(attachmentID))
+	if err != nil {
+		h.attachmentError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	_, err = io.Copy(w, content)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *AttachmentHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := readIntParam(r, "transactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+	attachmentID, err := readIntParam(r, "attachmentID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.attachmentService.DeleteByID(ctxUser.ID, int32(transactionID), int32(attachmentID))
+	if err != nil {
+		h.attachmentError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "attachment successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+type testAttachmentFile struct {
+	name    string
+	content []byte
+}
+
+func setupTestAttachmentHandler(t *testing.T) (*AttachmentHandler, *service.Service, string, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	svc := service.New(db, blob.NewLocalStore(dir))
+	handler := NewAttachmentHandler(svc.Attachment)
+
+	return handler, svc, dir, cleanup
+}
+
+func createAttachmentRequest(t *testing.T, transactionID int32, files []testAttachmentFile, user *store.User) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, file := range files {
+		part, err := writer.CreateFormFile("file", file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write(file.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/transactions/%d/attachments", transactionID), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetPathValue("transactionID", strconv.Itoa(int(transactionID)))
+
+	return appcontext.SetContextUser(req, &store.GetUserFromTokenRow{
+		ID:       user.ID,
+		Username: user.Username,
+	})
+}
+
+// countBlobs counts the files in the blob store directory.
+func countBlobs(t *testing.T, dir string) int {
+	t.Helper()
+
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return err
+	})
+	assert.NilError(t, err)
+
+	return count
+}
+
+func TestAttachmentHandler_Create(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, dir, cleanup := setupTestAttachmentHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	transaction := testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+
+	pdf := []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<<>>\nendobj\n")
+
+	tests := []struct {
+		name             string
+		user             *store.User
+		files            []testAttachmentFile
+		expectedStatus   int
+		expectedTypes    []string
+		expectedStored   int
+		expectedFilename string
+	}{
+		{
+			name:             "Image and PDF",
+			user:             user,
+			files:            []testAttachmentFile{{name: "receipt.png", content: testPNG}, {name: `C:\scans\invoice.pdf`, content: pdf}},
+			expectedStatus:   http.StatusCreated,
+			expectedTypes:    []string{"image/png", "application/pdf"},
+			expectedStored:   2,
+			expectedFilename: "receipt.png",
+		},
+		{
+			name:           "Type is sniffed, not taken from the name",
+			user:           user,
+			files:          []testAttachmentFile{{name: "receipt.png", content: []byte("<html><script>alert(1)</script></html>")}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedStored: 2,
+		},
+		{
+			name:           "No file",
+			user:           user,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedStored: 2,
+		},
+		{
+			name:           "Empty file",
+			user:           user,
+			files:          []testAttachmentFile{{name: "empty.png"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedStored: 2,
+		},
+		{
+			name:           "Other user's transaction",
+			user:           otherUser,
+			files:          []testAttachmentFile{{name: "receipt.png", content: testPNG}},
+			expectedStatus: http.StatusNotFound,
+			expectedStored: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createAttachmentRequest(t, transaction.ID, tt.files, tt.user)
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+			assert.Equal(t, countBlobs(t, dir), tt.expectedStored)
+
+			if res.StatusCode != http.StatusCreated {
+				return
+			}
+
+			var resBody map[string][]store.TransactionAttachment
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			attachments := resBody["attachments"]
+			assert.Equal(t, len(attachments), len(tt.expectedTypes))
+			for i := range min(len(attachments), len(tt.expectedTypes)) {
+				assert.Equal(t, attachments[i].ContentType, tt.expectedTypes[i])
+				assert.Equal(t, attachments[i].TransactionID, transaction.ID)
+			}
+			if len(attachments) == 2 {
+				assert.Equal(t, attachments[0].Filename, tt.expectedFilename)
+				assert.Equal(t, attachments[1].Filename, "invoice.pdf")
+			}
+		})
+	}
+}
+
+func TestAttachmentHandler_DownloadAndDelete(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, dir, cleanup := setupTestAttachmentHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	transaction := testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+
+	attachments, err := svc.Attachment.Create(user.ID, transaction.ID, []service.AttachmentUpload{
+		{Filename: "first.png", Size: int64(len(testPNG)), Content: bytes.NewReader(testPNG)},
+		{Filename: "second.png", Size: int64(len(testPNG)), Content: bytes.NewReader(testPNG)},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, countBlobs(t, dir), 2)
+
+	route := fmt.Sprintf("/v1/transactions/%d/attachments/%d", transaction.ID, attachments[0].ID)
+	setPath := func(req *http.Request, attachmentID int32) {
+		req.SetPathValue("transactionID", strconv.Itoa(int(transaction.ID)))
+		req.SetPathValue("attachmentID", strconv.Itoa(int(attachmentID)))
+	}
+
+	t.Run("Download", func(t *testing.T) {
+		req := testutils.CreateGetRequest(t, route, user)
+		setPath(req, attachments[0].ID)
+
+		rr := httptest.NewRecorder()
+		handler.Download(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Content-Type"), "image/png")
+		assert.Equal(t, res.Header.Get("Content-Disposition"), `attachment; filename=first.png`)
+		assert.Equal(t, res.Header.Get("X-Content-Type-Options"), "nosniff")
+
+		body, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+		assert.Equal(t, bytes.Equal(body, testPNG), true)
+	})
+
+	t.Run("Download other user's attachment", func(t *testing.T) {
+		req := testutils.CreateGetRequest(t, route, otherUser)
+		setPath(req, attachments[0].ID)
+
+		rr := httptest.NewRecorder()
+		handler.Download(rr, req)
+
+		assert.Equal(t, rr.Result().StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Delete attachment", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, route, nil)
+		req = appcontext.SetContextUser(req, &store.GetUserFromTokenRow{ID: user.ID, Username: user.Username})
+		setPath(req, attachments[0].ID)
+
+		rr := httptest.NewRecorder()
+		handler.DeleteByID(rr, req)
+
+		assert.Equal(t, rr.Result().StatusCode, http.StatusOK)
+		assert.Equal(t, countBlobs(t, dir), 1)
+
+		remaining, err := svc.Attachment.GetAll(user.ID, transaction.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(remaining), 1)
+	})
+
+	t.Run("Delete transaction", func(t *testing.T) {
+		err := svc.Transaction.DeleteByID(transaction.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, countBlobs(t, dir), 0)
+	})
+
+	upload := func(t *testing.T, u *store.User, transactionID int32) {
+		t.Helper()
+
+		_, err := svc.Attachment.Create(u.ID, transactionID, []service.AttachmentUpload{
+			{Filename: "receipt.png", Size: int64(len(testPNG)), Content: bytes.NewReader(testPNG)},
+		})
+		assert.NilError(t, err)
+	}
+
+	t.Run("Delete account", func(t *testing.T) {
+		other := testutils.CreateTestAccount(t, svc.Account, user.ID, "Other")
+		upload(t, user, testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID).ID)
+		upload(t, user, testutils.CreateTestTransaction(t, svc.Transaction, user.ID, other.ID, category.ID).ID)
+		assert.Equal(t, countBlobs(t, dir), 2)
+
+		err := svc.Account.DeleteByID(other.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, countBlobs(t, dir), 1)
+	})
+
+	t.Run("Delete user", func(t *testing.T) {
+		otherAccount := testutils.CreateTestAccount(t, svc.Account, otherUser.ID)
+		otherCategory := testutils.CreateTestCategory(t, svc.Category, otherUser.ID)
+		upload(t, otherUser, testutils.CreateTestTransaction(t, svc.Transaction, otherUser.ID, otherAccount.ID, otherCategory.ID).ID)
+		assert.Equal(t, countBlobs(t, dir), 2)
+
+		err := svc.User.DeleteByID(user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, countBlobs(t, dir), 1)
+	})
+}
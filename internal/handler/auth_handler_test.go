@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/service"
 	"github.com/Quak1/gokei/internal/testutils"
 	"github.com/Quak1/gokei/pkg/assert"
@@ -25,7 +26,7 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 	defer cleanup()
 
-	svc := service.New(db)
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
 	handler := NewAuthHandler(svc.Auth)
 	user := testutils.CreateTestUser(t, svc.User, "testuser")
 	route := "/v1/auth/login"
@@ -48,7 +49,7 @@ func TestAuthHandler_Login(t *testing.T) {
 				json.NewDecoder(r.Body).Decode(&resBody)
 				token := resBody["authentication_token"]
 
-				tokenUser, err := svc.User.GetForToken(token.Plaintext)
+				tokenUser, err := svc.User.GetForToken(service.ScopeAuthentication, token.Plaintext)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+const defaultCalendarDays = 90
+
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+func NewCalendarHandler(svc *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: svc,
+	}
+}
+
+func (h *CalendarHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	ctxUser := appcontext.GetContextUser(r)
+
+	token, err := h.calendarService.CreateToken(ctxUser.ID)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = response.Created(w, response.Envelope{
+		"calendar_token": token,
+		"url":            fmt.Sprintf("/v1/calendar/%s/recurring.ics", token.Plaintext),
+	}, nil)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+// Feed serves the iCalendar feed. Calendar clients can't send an Authorization
+// header, so the calendar token in the URL authenticates the request.
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	days, err := readIntQuery(r, "days", defaultCalendarDays)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	calendar, err := h.calendarService.Feed(r.PathValue("token"), time.Now(), days)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="recurring.ics"`)
+
+	err = calendar.Encode(w)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestCalendarHandler(t *testing.T) (*CalendarHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewCalendarHandler(svc.Calendar)
+
+	return handler, svc, cleanup
+}
+
+func TestCalendarHandler_CreateToken(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestCalendarHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+
+	req := testutils.CreatePostRequest(t, "/v1/calendar/tokens", nil, user)
+
+	rr := httptest.NewRecorder()
+	handler.CreateToken(rr, req)
+
+	res := rr.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	var resBody struct {
+		Token service.Token `json:"calendar_token"`
+		URL   string        `json:"url"`
+	}
+	json.NewDecoder(res.Body).Decode(&resBody)
+
+	assert.Equal(t, len(resBody.Token.Plaintext), 26)
+	assert.Equal(t, resBody.URL, "/v1/calendar/"+resBody.Token.Plaintext+"/recurring.ics")
+
+	feedUser, err := svc.User.GetForToken(service.ScopeCalendar, resBody.Token.Plaintext)
+	assert.NilError(t, err)
+	assert.Equal(t, feedUser.ID, user.ID)
+
+	_, err = svc.User.GetForToken(service.ScopeAuthentication, resBody.Token.Plaintext)
+	assert.HasError(t, err)
+}
+
+func TestCalendarHandler_Feed(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestCalendarHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	recurring := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+
+	paused := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+	_, err := svc.Recurring.SetActiveByID(user.ID, paused.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calendarToken, err := svc.Calendar.CreateToken(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authToken, err := svc.Token.New(int(user.ID), time.Hour, service.ScopeAuthentication)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		query          string
+		expectedStatus int
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Valid token",
+			token:          calendarToken.Plaintext,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				assert.Equal(t, r.Header.Get("Content-Type"), "text/calendar; charset=utf-8")
+
+				body, err := io.ReadAll(r.Body)
+				assert.NilError(t, err)
+
+				calendar := string(body)
+				assert.StringContains(t, calendar, "BEGIN:VCALENDAR\r\n")
+				assert.StringContains(t, calendar, "SUMMARY:"+recurring.Title+"\r\n")
+				assert.StringContains(t, calendar, "Amount: -15.00")
+				assert.StringContains(t, calendar, "Account: "+account.Name)
+				assert.StringContains(t, calendar, "Category: "+category.Name)
+				// A monthly rule over the default 90 days, paused rule excluded.
+				assert.Equal(t, strings.Count(calendar, "BEGIN:VEVENT") >= 2, true)
+				assert.Equal(t, strings.Count(calendar, "BEGIN:VEVENT") <= 4, true)
+			},
+		},
+		{
+			name:           "Custom range",
+			token:          calendarToken.Plaintext,
+			query:          "?days=366",
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				body, err := io.ReadAll(r.Body)
+				assert.NilError(t, err)
+
+				assert.Equal(t, strings.Count(string(body), "BEGIN:VEVENT") >= 12, true)
+			},
+		},
+		{
+			name:           "Range too long",
+			token:          calendarToken.Plaintext,
+			query:          "?days=400",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid days",
+			token:          calendarToken.Plaintext,
+			query:          "?days=soon",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Authentication token",
+			token:          authToken.Plaintext,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Unknown token",
+			token:          strings.Repeat("A", 26),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Malformed token",
+			token:          "test",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/calendar/"+tt.token+"/recurring.ics"+tt.query, nil)
+			req.SetPathValue("token", tt.token)
+
+			rr := httptest.NewRecorder()
+			handler.Feed(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
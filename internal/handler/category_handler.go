@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/Quak1/gokei/internal/appcontext"
 	"github.com/Quak1/gokei/internal/database"
@@ -126,6 +127,8 @@ func (h *CategoryHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
 			response.NotFoundResponse(w, r)
 		case errors.Is(err, database.ErrUpdateInitialCategory):
 			response.ForbiddenResponse(w, r, err)
+		case errors.Is(err, database.ErrCategoryInUse):
+			response.ConflictResponse(w, r)
 		default:
 			response.ServerErrorResponse(w, r, err)
 		}
@@ -177,3 +180,36 @@ func (h *CategoryHandler) UpdateByID(w http.ResponseWriter, r *http.Request) {
 		response.ServerErrorResponse(w, r, err)
 	}
 }
+
+func (h *CategoryHandler) Report(w http.ResponseWriter, r *http.Request) {
+	from, err := readDateQuery(r, "date_from", time.Time{})
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	to, err := readDateQuery(r, "date_to", time.Time{})
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	report, err := h.categoryService.Report(ctxUser.ID, from, to)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"report": report})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/internal/service"
 	"github.com/Quak1/gokei/internal/testutils"
@@ -20,7 +21,7 @@ func setupTestCategoryHandler(t *testing.T) (*CategoryHandler, *service.Service,
 		t.Fatalf("test db setup failed: %v", err)
 	}
 
-	svc := service.New(db)
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
 	handler := NewCategoryHandler(svc.Category)
 
 	return handler, svc, cleanup
@@ -536,3 +537,114 @@ func TestCategoryHandler_UpdateByID(t *testing.T) {
 		})
 	}
 }
+
+func TestCategoryHandler_Report(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestCategoryHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	groceries := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	household, err := svc.Category.Create(&store.CreateCategoryParams{
+		Name:   "Household",
+		Color:  "#000000",
+		Icon:   "H",
+		UserID: user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactions := []service.CreateTransactionParams{
+		{Title: "Salary", AmountCents: 100000, CategoryID: groceries.ID},
+		{Title: "Market", AmountCents: -2500, CategoryID: groceries.ID},
+		{Title: "Supermarket", AmountCents: -5000, Splits: []service.TransactionSplitParams{
+			{CategoryID: groceries.ID, AmountCents: -3000},
+			{CategoryID: household.ID, AmountCents: -2000},
+		}},
+	}
+	for _, transaction := range transactions {
+		transaction.AccountID = account.ID
+		_, err := svc.Transaction.Create(user.ID, &transaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       []store.GetCategoryReportRow
+	}{
+		{
+			name:           "Splits count per line",
+			expectedStatus: http.StatusOK,
+			expected: []store.GetCategoryReportRow{
+				{CategoryID: household.ID, Name: "Household", Count: 1, IncomeCents: 0, ExpenseCents: -2000, TotalCents: -2000},
+				{CategoryID: groceries.ID, Name: "Test Category", Count: 3, IncomeCents: 100000, ExpenseCents: -5500, TotalCents: 94500},
+			},
+		},
+		{
+			name:           "Date range",
+			query:          "?date_from=2020-01-01&date_to=2020-12-31",
+			expectedStatus: http.StatusOK,
+			expected:       []store.GetCategoryReportRow{},
+		},
+		{
+			name:           "date_to before date_from",
+			query:          "?date_from=2020-12-31&date_to=2020-01-01",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid date",
+			query:          "?date_from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateGetRequest(t, "/v1/categories/report"+tt.query, user)
+
+			rr := httptest.NewRecorder()
+			handler.Report(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resBody map[string][]store.GetCategoryReportRow
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			report := resBody["report"]
+			assert.Equal(t, len(report), len(tt.expected))
+			for i, row := range report {
+				assert.Equal(t, row, tt.expected[i])
+			}
+		})
+	}
+
+	t.Run("Can't delete category used by a split", func(t *testing.T) {
+		req := testutils.CreatePostRequest(t, "/v1/categories", nil, user)
+		req.SetPathValue("categoryID", strconv.Itoa(int(household.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.DeleteByID(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusConflict)
+	})
+}
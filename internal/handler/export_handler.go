@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+// downloadTimeout replaces the server's write timeout for downloads, which
+// are streamed and can take much longer than a regular response.
+const downloadTimeout = 30 * time.Minute
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(svc *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: svc,
+	}
+}
+
+func (h *ExportHandler) QIF(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	qif, err := h.exportService.QIF(ctxUser.ID, int32(accountID))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	download(w, r, "application/qif; charset=utf-8", fmt.Sprintf("account-%d.qif", accountID), qif.Encode)
+}
+
+// Export streams all of the user's data as a JSON document or a zip of CSV
+// files.
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	var params service.ExportParams
+	var err error
+
+	params.Format = readStringQuery(r, "format", service.ExportFormatJSON)
+	if params.DateFrom, err = readDateQuery(r, "date_from", time.Time{}); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	if params.DateTo, err = readDateQuery(r, "date_to", time.Time{}); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	export, err := h.exportService.Export(ctxUser.ID, &params)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	filename := "gokei-export-" + export.ExportedAt.Format(time.DateOnly)
+	switch export.Format {
+	case service.ExportFormatCSV:
+		download(w, r, "application/zip", filename+".zip", export.Encode)
+	default:
+		download(w, r, "application/json", filename+".json", export.Encode)
+	}
+}
+
+// download streams a file written by encode. The headers of the download are
+// only set once the first bytes are written, so an error before that is sent
+// as a regular error response. After that the status has been sent, and the
+// response is aborted instead.
+func download(w http.ResponseWriter, r *http.Request, contentType, filename string, encode func(io.Writer) error) {
+	// Not every ResponseWriter supports deadlines, and those that don't have
+	// none to extend.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(downloadTimeout))
+
+	dw := &downloadWriter{
+		w:           w,
+		contentType: contentType,
+		filename:    filename,
+	}
+
+	err := encode(dw)
+	switch {
+	case err == nil:
+		return
+	case !dw.started:
+		response.ServerErrorResponse(w, r, err)
+	default:
+		response.AbortResponse(r, err)
+	}
+}
+
+// downloadWriter sets the headers of a download on the first write.
+type downloadWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (dw *downloadWriter) Write(p []byte) (int, error) {
+	if !dw.started {
+		dw.started = true
+		dw.w.Header().Set("Content-Type", dw.contentType)
+		dw.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, dw.filename))
+	}
+	return dw.w.Write(p)
+}
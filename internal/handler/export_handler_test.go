@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+	"github.com/Quak1/gokei/pkg/response"
+)
+
+func setupTestExportHandler(t *testing.T) (*ExportHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewExportHandler(svc.Export)
+
+	return handler, svc, cleanup
+}
+
+func Test_Download(t *testing.T) {
+	response.SetLogger(slog.New(slog.DiscardHandler))
+
+	errEncode := errors.New("encode failed")
+
+	t.Run("Error before writing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		download(rr, httptest.NewRequest(http.MethodGet, "/", nil), "application/zip", "export.zip", func(w io.Writer) error {
+			return errEncode
+		})
+
+		res := rr.Result()
+		assert.Equal(t, res.StatusCode, http.StatusInternalServerError)
+		assert.Equal(t, res.Header.Get("Content-Type"), "application/json")
+		assert.Equal(t, res.Header.Get("Content-Disposition"), "")
+	})
+
+	t.Run("Error after writing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		defer func() {
+			assert.Equal(t, recover(), any(http.ErrAbortHandler))
+			assert.Equal(t, rr.Body.String(), "partial")
+			assert.Equal(t, rr.Result().Header.Get("Content-Disposition"), `attachment; filename="export.zip"`)
+		}()
+
+		download(rr, httptest.NewRequest(http.MethodGet, "/", nil), "application/zip", "export.zip", func(w io.Writer) error {
+			io.WriteString(w, "partial")
+			return errEncode
+		})
+	})
+}
+
+func Test_DownloadWriteTimeout(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		download(w, r, "application/json", "export.json", func(w io.Writer) error {
+			io.WriteString(w, "first")
+			time.Sleep(100 * time.Millisecond)
+			_, err := io.WriteString(w, " second")
+			return err
+		})
+	}))
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(body), "first second")
+}
+
+func TestExportHandler_QIF(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestExportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID, "Checking")
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	_, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		AccountID:   account.ID,
+		CategoryID:  category.ID,
+		AmountCents: -4520,
+		Title:       "Supermarket",
+		Note:        "Weekly shop",
+	})
+	assert.NilError(t, err)
+
+	savings := testutils.CreateTestAccount(t, svc.Account, user.ID, "Savings")
+	_, err = svc.Transfer.Create(user.ID, &service.CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   savings.ID,
+		AmountCents:   2000,
+	})
+	assert.NilError(t, err)
+
+	_, err = svc.Reconciliation.Create(user.ID, account.ID, &service.CreateReconciliationParams{
+		StatementDate:         time.Now().UTC().Truncate(24 * time.Hour),
+		StatementBalanceCents: 500,
+		CreateAdjustment:      true,
+	})
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name           string
+		user           *store.User
+		expectedStatus int
+	}{
+		{
+			name:           "Own account",
+			user:           user,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Other user's account",
+			user:           otherUser,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateGetRequest(t, "/v1/accounts/"+strconv.Itoa(int(account.ID))+"/export/qif", tt.user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+			rr := httptest.NewRecorder()
+			handler.QIF(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, res.Header.Get("Content-Type"), "application/qif; charset=utf-8")
+
+			body, err := io.ReadAll(res.Body)
+			assert.NilError(t, err)
+
+			assert.StringContains(t, string(body), "!Type:Bank\n")
+			assert.StringContains(t, string(body), "T100.00\nPInitial balance\nL[Checking]\n^\n")
+			assert.StringContains(t, string(body), "T-45.20\nPSupermarket\nMWeekly shop\nL"+category.Name+"\n^\n")
+			assert.StringContains(t, string(body), "T-20.00\nP[TRANSFER] FROM 'Checking' TO 'Savings'\nL[Savings]\n^\n")
+			assert.StringContains(t, string(body), "T5.00\nP[RECONCILIATION] STATEMENT OF "+time.Now().UTC().Format(time.DateOnly)+"\n^\n")
+		})
+	}
+}
+
+func TestExportHandler_Export(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestExportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID, "Checking")
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	testutils.CreateTestAccount(t, svc.Account, otherUser.ID, "Other")
+	testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+
+	transaction, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		AccountID:   account.ID,
+		CategoryID:  category.ID,
+		AmountCents: -4520,
+		Title:       "Supermarket, downtown",
+		Splits: []service.TransactionSplitParams{
+			{CategoryID: category.ID, AmountCents: -4000},
+			{CategoryID: category.ID, AmountCents: -520, Note: "Snacks"},
+		},
+		Tags: []string{"groceries"},
+	})
+	assert.NilError(t, err)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+
+	t.Run("JSON", func(t *testing.T) {
+		res := serveExport(t, handler, "/v1/export", user)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Content-Type"), "application/json")
+		assert.StringContains(t, res.Header.Get("Content-Disposition"), ".json")
+
+		var document struct {
+			Version               int                          `json:"version"`
+			DateFrom              *string                      `json:"date_from"`
+			Accounts              []store.Account              `json:"accounts"`
+			Categories            []store.Category             `json:"categories"`
+			Tags                  []store.Tag                  `json:"tags"`
+			RecurringTransactions []store.RecurringTransaction `json:"recurring_transactions"`
+			Transactions          []struct {
+				store.Transaction
+				Splits []store.TransactionSplit `json:"splits"`
+				TagIDs []int32                  `json:"tag_ids"`
+			} `json:"transactions"`
+		}
+		err := json.NewDecoder(res.Body).Decode(&document)
+		assert.NilError(t, err)
+
+		assert.Equal(t, document.Version, service.ExportVersion)
+		assert.Equal(t, document.DateFrom == nil, true)
+		assert.Equal(t, len(document.Accounts), 1)
+		assert.Equal(t, len(document.Tags), 1)
+		assert.Equal(t, len(document.RecurringTransactions), 1)
+
+		var found bool
+		for _, c := range document.Categories {
+			found = found || c.ID == category.ID
+		}
+		assert.Equal(t, found, true)
+
+		found = false
+		for i, tr := range document.Transactions {
+			assert.Equal(t, tr.AccountID, account.ID)
+			if i > 0 {
+				assert.Equal(t, tr.Date.Before(document.Transactions[i-1].Date), false)
+			}
+			if tr.ID != transaction.ID {
+				continue
+			}
+			found = true
+			assert.Equal(t, len(tr.Splits), 2)
+			assert.Equal(t, len(tr.TagIDs), 1)
+			if len(tr.TagIDs) == 1 && len(document.Tags) == 1 {
+				assert.Equal(t, tr.TagIDs[0], document.Tags[0].ID)
+			}
+		}
+		assert.Equal(t, found, true)
+	})
+
+	t.Run("JSON date range", func(t *testing.T) {
+		res := serveExport(t, handler, "/v1/export?date_from="+tomorrow, user)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var document struct {
+			DateFrom     *string           `json:"date_from"`
+			Accounts     []store.Account   `json:"accounts"`
+			Transactions []json.RawMessage `json:"transactions"`
+		}
+		err := json.NewDecoder(res.Body).Decode(&document)
+		assert.NilError(t, err)
+
+		if document.DateFrom != nil {
+			assert.Equal(t, *document.DateFrom, tomorrow)
+		}
+		assert.Equal(t, len(document.Accounts), 1)
+		assert.Equal(t, document.Transactions != nil, true)
+		assert.Equal(t, len(document.Transactions), 0)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		res := serveExport(t, handler, "/v1/export?format=csv", user)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, res.Header.Get("Content-Type"), "application/zip")
+
+		body, err := io.ReadAll(res.Body)
+		assert.NilError(t, err)
+
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		assert.NilError(t, err)
+		if zr == nil {
+			return
+		}
+
+		files := map[string][][]string{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			assert.NilError(t, err)
+			records, err := csv.NewReader(rc).ReadAll()
+			assert.NilError(t, err)
+			rc.Close()
+			files[f.Name] = records
+		}
+
+		assert.Equal(t, len(files), 12)
+		assert.Equal(t, len(files["accounts.csv"]), 2)
+		assert.Equal(t, len(files["tags.csv"]), 2)
+		assert.Equal(t, len(files["transaction_splits.csv"]), 3)
+		assert.Equal(t, len(files["recurring_transactions.csv"]), 2)
+		assert.Equal(t, len(files["recurring_transaction_exceptions.csv"]), 1)
+
+		var row []string
+		for _, record := range files["transactions.csv"] {
+			if record[0] == strconv.Itoa(int(transaction.ID)) {
+				row = record
+			}
+		}
+		assert.Equal(t, len(row), 9)
+		if len(row) == 9 {
+			assert.Equal(t, row[4], "-4520")
+			assert.Equal(t, row[5], "Supermarket, downtown")
+			assert.Equal(t, row[7], "cleared")
+		}
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			route          string
+			expectedStatus int
+		}{
+			{
+				name:           "Unknown format",
+				route:          "/v1/export?format=xml",
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Malformed date",
+				route:          "/v1/export?date_from=yesterday",
+				expectedStatus: http.StatusBadRequest,
+			},
+			{
+				name:           "Reversed date range",
+				route:          "/v1/export?date_from=2025-02-01&date_to=2025-01-01",
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res := serveExport(t, handler, tt.route, user)
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, tt.expectedStatus)
+			})
+		}
+	})
+}
+
+func serveExport(t *testing.T, handler *ExportHandler, route string, user *store.User) *http.Response {
+	t.Helper()
+
+	req := testutils.CreateGetRequest(t, route, user)
+	rr := httptest.NewRecorder()
+	handler.Export(rr, req)
+
+	return rr.Result()
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+const defaultForecastDays = 30
+
+type ForecastHandler struct {
+	forecastService *service.ForecastService
+}
+
+func NewForecastHandler(svc *service.ForecastService) *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: svc,
+	}
+}
+
+func (h *ForecastHandler) ForAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	today := time.Now()
+	until, err := readDateQuery(r, "until", today.AddDate(0, 0, defaultForecastDays))
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	forecast, err := h.forecastService.ForAccount(ctxUser.ID, int32(id), today, until)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"forecast": forecast})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ForecastHandler) ForUser(w http.ResponseWriter, r *http.Request) {
+	today := time.Now()
+	until, err := readDateQuery(r, "until", today.AddDate(0, 0, defaultForecastDays))
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	forecast, err := h.forecastService.ForUser(ctxUser.ID, today, until)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"forecast": forecast})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
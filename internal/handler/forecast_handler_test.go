@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/forecast"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestForecastHandler(t *testing.T) (*ForecastHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewForecastHandler(svc.Forecast)
+
+	return handler, svc, cleanup
+}
+
+func TestForecastHandler_ForAccount(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestForecastHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	accountID := strconv.Itoa(int(account.ID))
+	route := fmt.Sprintf("/v1/accounts/%d/forecast", account.ID)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	dayWeek := int32(tomorrow.Weekday())
+	_, err := svc.Recurring.Create(user.ID, &service.CreateRecurringTransactionParams{
+		AccountID:   account.ID,
+		AmountCents: -3000,
+		CategoryID:  category.ID,
+		Title:       "Groceries",
+		Frequency:   "weekly",
+		StartDate:   tomorrow,
+		DayWeek:     &dayWeek,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		accountID      string
+		expectedStatus int
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Forecast until date",
+			query:          "?until=" + tomorrow.AddDate(0, 0, 27).Format(time.DateOnly),
+			accountID:      accountID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*forecast.Account
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				f := resBody["forecast"]
+				assert.Equal(t, f.AccountID, account.ID)
+				assert.Equal(t, f.BalanceCents, account.BalanceCents)
+				assert.Equal(t, len(f.Days), 28)
+				assert.Equal(t, f.Days[0].ChangeCents, -3000)
+				assert.Equal(t, f.Days[27].BalanceCents, account.BalanceCents-4*3000)
+				assert.Equal(t, f.FirstNegativeDate.Format(time.DateOnly), tomorrow.AddDate(0, 0, 21).Format(time.DateOnly))
+			},
+		},
+		{
+			name:           "Default range",
+			accountID:      accountID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*forecast.Account
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				assert.Equal(t, len(resBody["forecast"].Days), 30)
+			},
+		},
+		{
+			name:           "Until in the past",
+			query:          "?until=2020-01-01",
+			accountID:      accountID,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Until too far",
+			query:          "?until=" + tomorrow.AddDate(5, 0, 0).Format(time.DateOnly),
+			accountID:      accountID,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid until",
+			query:          "?until=tomorrow",
+			accountID:      accountID,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not found",
+			accountID:      "999",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			accountID:      "test",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateGetRequest(t, route+tt.query, user)
+			req.SetPathValue("accountID", tt.accountID)
+
+			rr := httptest.NewRecorder()
+			handler.ForAccount(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
+
+func TestForecastHandler_ForUser(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestForecastHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	account2 := testutils.CreateTestAccount(t, svc.Account, user.ID, "Savings")
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	_, err := svc.Recurring.Create(user.ID, &service.CreateRecurringTransactionParams{
+		AccountID:   account2.ID,
+		AmountCents: 100,
+		CategoryID:  category.ID,
+		Title:       "Interest",
+		Frequency:   "daily",
+		StartDate:   tomorrow,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	until := tomorrow.AddDate(0, 0, 9).Format(time.DateOnly)
+	req := testutils.CreateGetRequest(t, "/v1/accounts/forecast?until="+until, user)
+
+	rr := httptest.NewRecorder()
+	handler.ForUser(rr, req)
+
+	res := rr.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+
+	var resBody map[string]*forecast.Forecast
+	json.NewDecoder(res.Body).Decode(&resBody)
+
+	f := resBody["forecast"]
+	assert.Equal(t, len(f.Accounts), 2)
+	assert.Equal(t, len(f.Days), 10)
+	assert.Equal(t, f.Days[9].BalanceCents, account.BalanceCents+account2.BalanceCents+10*100)
+	assert.Equal(t, f.FirstNegativeDate == nil, true)
+}
@@ -8,23 +8,41 @@ import (
 )
 
 type Handler struct {
-	Hello       *HelloHandler
-	Category    *CategoryHandler
-	Account     *AccountHandler
-	Transaction *TransactionHandler
-	User        *UserHandler
-	Auth        *AuthHandler
+	Hello          *HelloHandler
+	Category       *CategoryHandler
+	Tag            *TagHandler
+	Account        *AccountHandler
+	Transaction    *TransactionHandler
+	Transfer       *TransferHandler
+	Reconciliation *ReconciliationHandler
+	Attachment     *AttachmentHandler
+	Recurring      *RecurringTransactionHandler
+	Forecast       *ForecastHandler
+	Import         *ImportHandler
+	Export         *ExportHandler
+	Calendar       *CalendarHandler
+	User           *UserHandler
+	Auth           *AuthHandler
 }
 
 func New(svc *service.Service, logger *slog.Logger) *Handler {
 	response.SetLogger(logger)
 
 	return &Handler{
-		Hello:       NewHelloHandler(svc.Hello),
-		Category:    NewCategoryHandler(svc.Category),
-		Account:     NewAccountHandler(svc.Account),
-		Transaction: NewTransactionHandler(svc.Transaction),
-		User:        NewUserHandler(svc.User),
-		Auth:        NewAuthHandler(svc.Auth),
+		Hello:          NewHelloHandler(svc.Hello),
+		Category:       NewCategoryHandler(svc.Category),
+		Tag:            NewTagHandler(svc.Tag),
+		Account:        NewAccountHandler(svc.Account),
+		Transaction:    NewTransactionHandler(svc.Transaction),
+		Transfer:       NewTransferHandler(svc.Transfer),
+		Reconciliation: NewReconciliationHandler(svc.Reconciliation),
+		Attachment:     NewAttachmentHandler(svc.Attachment),
+		Recurring:      NewRecurringTransactionHandler(svc.Recurring),
+		Forecast:       NewForecastHandler(svc.Forecast),
+		Import:         NewImportHandler(svc.Import),
+		Export:         NewExportHandler(svc.Export),
+		Calendar:       NewCalendarHandler(svc.Calendar),
+		User:           NewUserHandler(svc.User),
+		Auth:           NewAuthHandler(svc.Auth),
 	}
 }
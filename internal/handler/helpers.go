@@ -2,8 +2,11 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func readIntParam(r *http.Request, key string) (int, error) {
@@ -16,3 +19,73 @@ func readIntParam(r *http.Request, key string) (int, error) {
 
 	return id, nil
 }
+
+func readIntQuery(r *http.Request, key string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	num, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+
+	return num, nil
+}
+
+func readDateQuery(r *http.Request, key string, defaultValue time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a date formatted as YYYY-MM-DD", key)
+	}
+
+	return date, nil
+}
+
+func readOptionalIntQuery(r *http.Request, key string) (*int64, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	num, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+
+	return &num, nil
+}
+
+func readIDListQuery(r *http.Request, key string) ([]int32, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	ids := make([]int32, len(parts))
+	for i, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a comma separated list of IDs", key)
+		}
+		ids[i] = int32(id)
+	}
+
+	return ids, nil
+}
+
+func readStringQuery(r *http.Request, key string, defaultValue string) string {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
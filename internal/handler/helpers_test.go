@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Quak1/gokei/pkg/assert"
 )
@@ -68,3 +69,207 @@ func Test_ReadIntParam(t *testing.T) {
 		})
 	}
 }
+
+func Test_ReadIntQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		defaultValue int
+		wantError    bool
+		expected     int
+	}{
+		{
+			name:         "Get number",
+			target:       "/?num=5",
+			defaultValue: 10,
+			expected:     5,
+		},
+		{
+			name:         "Missing key uses default",
+			target:       "/",
+			defaultValue: 10,
+			expected:     10,
+		},
+		{
+			name:         "Value is not a number",
+			target:       "/?num=NaN",
+			defaultValue: 10,
+			wantError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			num, err := readIntQuery(r, "num", tt.defaultValue)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.Equal(t, num, tt.expected)
+		})
+	}
+}
+
+func Test_ReadDateQuery(t *testing.T) {
+	defaultValue := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		target    string
+		wantError bool
+		expected  time.Time
+	}{
+		{
+			name:     "Get date",
+			target:   "/?until=2025-12-31",
+			expected: time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Missing key uses default",
+			target:   "/",
+			expected: defaultValue,
+		},
+		{
+			name:      "Value is not a date",
+			target:    "/?until=31-12-2025",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			date, err := readDateQuery(r, "until", defaultValue)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.Equal(t, date, tt.expected)
+		})
+	}
+}
+
+func Test_ReadOptionalIntQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		wantError bool
+		expected  int64
+		isNil     bool
+	}{
+		{
+			name:     "Get number",
+			target:   "/?num=-5",
+			expected: -5,
+		},
+		{
+			name:   "Missing key",
+			target: "/",
+			isNil:  true,
+		},
+		{
+			name:      "Value is not a number",
+			target:    "/?num=NaN",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			num, err := readOptionalIntQuery(r, "num")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.isNil {
+				assert.Equal(t, num == nil, true)
+				return
+			}
+			assert.Equal(t, *num, tt.expected)
+		})
+	}
+}
+
+func Test_ReadIDListQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		wantError bool
+		expected  []int32
+	}{
+		{
+			name:     "Single ID",
+			target:   "/?ids=3",
+			expected: []int32{3},
+		},
+		{
+			name:     "Several IDs",
+			target:   "/?ids=3,%204,5",
+			expected: []int32{3, 4, 5},
+		},
+		{
+			name:     "Missing key",
+			target:   "/",
+			expected: nil,
+		},
+		{
+			name:      "Value is not a number",
+			target:    "/?ids=3,a",
+			wantError: true,
+		},
+		{
+			name:      "Empty element",
+			target:    "/?ids=3,,4",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			ids, err := readIDListQuery(r, "ids")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assert.Equal(t, len(ids), len(tt.expected))
+			for i := range ids {
+				assert.Equal(t, ids[i], tt.expected[i])
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+const (
+	maxImportFileBytes = 10 << 20
+	// maxRestoreBytes limits a restore sent as a raw JSON body, which is read
+	// as a stream rather than held in memory.
+	maxRestoreBytes = 1 << 30
+	// restoreTimeout replaces the server's timeouts for a restore, as a
+	// backup takes much longer to upload and restore than a regular request.
+	restoreTimeout = 30 * time.Minute
+)
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(svc *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: svc,
+	}
+}
+
+// readImportForm reads a multipart/form-data body holding the statement in a
+// "file" part and the JSON import options in an "options" part. Imports that
+// take no options pass nil and have the part ignored.
+func readImportForm(w http.ResponseWriter, r *http.Request, options any) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes)
+
+	err := r.ParseMultipartForm(maxImportFileBytes)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, fmt.Errorf("Body must not be larger than %d bytes", maxBytesError.Limit)
+		}
+		return nil, errors.New("Body must be multipart/form-data")
+	}
+
+	if value := r.FormValue("options"); value != "" && options != nil {
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(options); err != nil {
+			return nil, fmt.Errorf("options must be valid JSON: %v", err)
+		}
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, errors.New("file must be provided")
+	}
+
+	return file, nil
+}
+
+func (h *ImportHandler) importResponse(w http.ResponseWriter, r *http.Request, result *service.ImportResult, err error) {
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, database.ErrInvalidCategory):
+			response.BadRequestResponse(w, r, err)
+		case errors.Is(err, database.ErrEditConflict):
+			response.ConflictResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if result.DryRun {
+		err = response.OK(w, response.Envelope{"import": result})
+	} else {
+		err = response.Created(w, response.Envelope{"import": result}, nil)
+	}
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ImportHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var options service.ImportCSVParams
+	file, err := readImportForm(w, r, &options)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	result, err := h.importService.ImportCSV(ctxUser.ID, int32(accountID), file, &options)
+	h.importResponse(w, r, result, err)
+}
+
+func (h *ImportHandler) ImportOFX(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var options service.ImportParams
+	file, err := readImportForm(w, r, &options)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	result, err := h.importService.ImportOFX(ctxUser.ID, int32(accountID), file, &options)
+	h.importResponse(w, r, result, err)
+}
+
+func (h *ImportHandler) ImportCAMT053(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var options service.ImportParams
+	file, err := readImportForm(w, r, &options)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	result, err := h.importService.ImportCAMT053(ctxUser.ID, int32(accountID), file, &options)
+	h.importResponse(w, r, result, err)
+}
+
+func (h *ImportHandler) ImportMT940(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var options service.ImportParams
+	file, err := readImportForm(w, r, &options)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	result, err := h.importService.ImportMT940(ctxUser.ID, int32(accountID), file, &options)
+	h.importResponse(w, r, result, err)
+}
+
+func (h *ImportHandler) ImportQIF(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var options service.ImportQIFParams
+	file, err := readImportForm(w, r, &options)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	result, err := h.importService.ImportQIF(ctxUser.ID, int32(accountID), file, &options)
+	h.importResponse(w, r, result, err)
+}
+
+// ImportGokei restores a JSON export, sent either as the "file" part of a
+// multipart/form-data body or, for larger exports, as the application/json
+// body itself.
+func (h *ImportHandler) ImportGokei(w http.ResponseWriter, r *http.Request) {
+	// The response is only written once the whole body has been restored, so
+	// the write deadline is extended along with the read one.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(restoreTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(restoreTimeout))
+
+	var file io.ReadCloser
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		file = http.MaxBytesReader(w, r.Body, maxRestoreBytes)
+	} else {
+		var err error
+		file, err = readImportForm(w, r, nil)
+		if err != nil {
+			response.BadRequestResponse(w, r, err)
+			return
+		}
+	}
+	defer file.Close()
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	result, err := h.importService.ImportGokei(ctxUser.ID, file)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.As(err, &maxBytesError):
+			response.BadRequestResponse(w, r, fmt.Errorf("Body must not be larger than %d bytes", maxBytesError.Limit))
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.Created(w, response.Envelope{"restore": result}, nil)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ImportHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	var input service.CreateImportProfileParams
+	err := response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	profile, err := h.importService.CreateProfile(ctxUser.ID, &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, service.ErrDuplicateImportProfileName):
+			response.BadRequestResponse(w, r, fmt.Errorf("An import profile with this name already exists"))
+		case errors.Is(err, database.ErrInvalidUser):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/import-profiles/%d", profile.ID))
+
+	err = response.Created(w, response.Envelope{"import_profile": profile}, headers)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ImportHandler) GetProfiles(w http.ResponseWriter, r *http.Request) {
+	ctxUser := appcontext.GetContextUser(r)
+
+	profiles, err := h.importService.GetProfiles(ctxUser.ID)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"import_profiles": profiles})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ImportHandler) GetProfileByID(w http.ResponseWriter, r *http.Request) {
+	profileID, err := readIntParam(r, "profileID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	profile, err := h.importService.GetProfileByID(ctxUser.ID, int32(profileID))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"import_profile": profile})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ImportHandler) UpdateProfileByID(w http.ResponseWriter, r *http.Request) {
+	profileID, err := readIntParam(r, "profileID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var input service.UpdateImportProfileParams
+	err = response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	profile, err := h.importService.UpdateProfileByID(ctxUser.ID, int32(profileID), &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, service.ErrDuplicateImportProfileName):
+			response.BadRequestResponse(w, r, fmt.Errorf("An import profile with this name already exists"))
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, database.ErrEditConflict):
+			response.ConflictResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"import_profile": profile})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ImportHandler) DeleteProfileByID(w http.ResponseWriter, r *http.Request) {
+	profileID, err := readIntParam(r, "profileID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.importService.DeleteProfileByID(ctxUser.ID, int32(profileID))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "import profile successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
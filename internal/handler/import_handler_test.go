@@ -0,0 +1,1024 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/importer"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestImportHandler(t *testing.T) (*ImportHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewImportHandler(svc.Import)
+
+	return handler, svc, cleanup
+}
+
+func TestImportHandler_ImportCSV(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	statement := "Datum;Omschrijving;Bedrag;Mededeling\n" +
+		"03-01-2025;Supermarket;-45,20;Groceries\n" +
+		"05-01-2025;Salary;2.500,00;\n"
+
+	mapping := map[string]any{
+		"delimiter":         ";",
+		"has_header":        true,
+		"date_column":       "Datum",
+		"date_format":       "DD-MM-YYYY",
+		"amount_column":     "Bedrag",
+		"title_column":      "Omschrijving",
+		"note_column":       "Mededeling",
+		"decimal_separator": ",",
+	}
+
+	profile, err := svc.Import.CreateProfile(user.ID, &service.CreateImportProfileParams{
+		Name: "My bank",
+		CSVMapping: importer.CSVMapping{
+			Delimiter:        ";",
+			HasHeader:        true,
+			DateColumn:       "Datum",
+			DateFormat:       "DD-MM-YYYY",
+			AmountColumn:     "Bedrag",
+			TitleColumn:      "Omschrijving",
+			NoteColumn:       "Mededeling",
+			DecimalSeparator: ",",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	route := fmt.Sprintf("/v1/accounts/%d/import/csv", account.ID)
+
+	tests := []struct {
+		name           string
+		accountID      int32
+		file           string
+		options        any
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name:           "Dry run with mapping",
+			file:           statement,
+			options:        map[string]any{"mapping": mapping, "category_id": category.ID, "dry_run": true},
+			expectedStatus: http.StatusOK,
+			expectedCount:  0,
+		},
+		{
+			name:           "Import with profile",
+			file:           statement,
+			options:        map[string]any{"profile_id": profile.ID, "category_id": category.ID},
+			expectedStatus: http.StatusCreated,
+			expectedCount:  2,
+		},
+		{
+			name:           "Missing mapping",
+			file:           statement,
+			options:        map[string]any{"category_id": category.ID},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Missing category",
+			file:           statement,
+			options:        map[string]any{"profile_id": profile.ID},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Unreadable row",
+			file:           statement + "06-01-2025;Coffee;abc;\n",
+			options:        map[string]any{"profile_id": profile.ID, "category_id": category.ID},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Unknown option",
+			file:           statement,
+			options:        map[string]any{"profile": profile.ID},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Other user's account",
+			accountID:      9999,
+			file:           statement,
+			options:        map[string]any{"profile_id": profile.ID, "category_id": category.ID},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountID := account.ID
+			if tt.accountID != 0 {
+				accountID = tt.accountID
+			}
+
+			req := testutils.CreateUploadRequest(t, route, tt.file, tt.options, user)
+			req.SetPathValue("accountID", strconv.Itoa(int(accountID)))
+
+			rr := httptest.NewRecorder()
+			handler.ImportCSV(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode >= 300 {
+				return
+			}
+
+			var resBody map[string]*service.ImportResult
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			result := resBody["import"]
+			assert.Equal(t, len(result.Entries), 2)
+			assert.Equal(t, result.Entries[0].AmountCents, -4520)
+			assert.Equal(t, result.Entries[1].AmountCents, 250000)
+			assert.Equal(t, len(result.Transactions), tt.expectedCount)
+		})
+	}
+
+	updated, err := svc.Account.GetByID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, updated.BalanceCents, account.BalanceCents-4520+250000)
+
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 3)
+}
+
+func TestImportHandler_ImportOFX(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	statement := "OFXHEADER:100\nDATA:OFXSGML\nVERSION:102\n\n" +
+		"<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>EUR<BANKTRANLIST>\n" +
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20250103<TRNAMT>-45.20<FITID>T1<NAME>Supermarket<MEMO>Groceries</STMTTRN>\n" +
+		"<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20250105<TRNAMT>2500.00<FITID>T2<NAME>Salary</STMTTRN>\n" +
+		"</BANKTRANLIST><LEDGERBAL><BALAMT>%s<DTASOF>20250131</LEDGERBAL>\n" +
+		"</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n"
+
+	route := fmt.Sprintf("/v1/accounts/%d/import/ofx", account.ID)
+	balance := account.BalanceCents - 4520 + 250000
+
+	tests := []struct {
+		name               string
+		file               string
+		options            any
+		expectedStatus     int
+		expectedCount      int
+		expectedSkipped    int
+		expectedDifference int64
+	}{
+		{
+			name:               "Dry run",
+			file:               fmt.Sprintf(statement, "2554.80"),
+			options:            map[string]any{"category_id": category.ID, "dry_run": true},
+			expectedStatus:     http.StatusOK,
+			expectedDifference: 0,
+		},
+		{
+			name:               "Import",
+			file:               fmt.Sprintf(statement, "2554.80"),
+			options:            map[string]any{"category_id": category.ID},
+			expectedStatus:     http.StatusCreated,
+			expectedCount:      2,
+			expectedDifference: 0,
+		},
+		{
+			name:               "Import again",
+			file:               fmt.Sprintf(statement, "2550.00"),
+			options:            map[string]any{"category_id": category.ID},
+			expectedStatus:     http.StatusCreated,
+			expectedSkipped:    2,
+			expectedDifference: 480,
+		},
+		{
+			name:           "Not OFX",
+			file:           "Date,Title,Amount\n",
+			options:        map[string]any{"category_id": category.ID},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Missing category",
+			file:           fmt.Sprintf(statement, "2554.80"),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateUploadRequest(t, route, tt.file, tt.options, user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+			rr := httptest.NewRecorder()
+			handler.ImportOFX(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode >= 300 {
+				return
+			}
+
+			var resBody map[string]*service.ImportResult
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			result := resBody["import"]
+			assert.Equal(t, len(result.Transactions), tt.expectedCount)
+			assert.Equal(t, len(result.Skipped), tt.expectedSkipped)
+			assert.Equal(t, result.BalanceCheck.AccountBalanceCents, balance)
+			assert.Equal(t, result.BalanceCheck.DifferenceCents, tt.expectedDifference)
+		})
+	}
+
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 3)
+}
+
+func TestImportHandler_ImportCAMT053(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	statement := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt><Stmt>
+    <Bal>
+      <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+      <Amt Ccy="EUR">2554.80</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+      <Dt><Dt>2025-01-31</Dt></Dt>
+    </Bal>
+    <Ntry>
+      <Amt Ccy="EUR">45.20</Amt><CdtDbtInd>DBIT</CdtDbtInd>
+      <Sts><Cd>BOOK</Cd></Sts><BookgDt><Dt>2025-01-03</Dt></BookgDt>
+      <AcctSvcrRef>REF-1</AcctSvcrRef>
+      <NtryDtls><TxDtls><RltdPties><Cdtr><Pty><Nm>Supermarket</Nm></Pty></Cdtr></RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+    <Ntry>
+      <Amt Ccy="EUR">2500.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+      <Sts><Cd>BOOK</Cd></Sts><BookgDt><Dt>2025-01-05</Dt></BookgDt>
+      <AcctSvcrRef>REF-2</AcctSvcrRef>
+      <NtryDtls><TxDtls><RltdPties><Dbtr><Pty><Nm>ACME Inc</Nm></Pty></Dbtr></RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`
+
+	route := fmt.Sprintf("/v1/accounts/%d/import/camt053", account.ID)
+
+	tests := []struct {
+		name            string
+		file            string
+		expectedStatus  int
+		expectedCount   int
+		expectedSkipped int
+	}{
+		{
+			name:           "Import",
+			file:           statement,
+			expectedStatus: http.StatusCreated,
+			expectedCount:  2,
+		},
+		{
+			name:            "Import again",
+			file:            statement,
+			expectedStatus:  http.StatusCreated,
+			expectedSkipped: 2,
+		},
+		{
+			name:           "Not camt.053",
+			file:           "<html></html>",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateUploadRequest(t, route, tt.file, map[string]any{"category_id": category.ID}, user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+			rr := httptest.NewRecorder()
+			handler.ImportCAMT053(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode >= 300 {
+				return
+			}
+
+			var resBody map[string]*service.ImportResult
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			result := resBody["import"]
+			assert.Equal(t, len(result.Transactions), tt.expectedCount)
+			assert.Equal(t, len(result.Skipped), tt.expectedSkipped)
+			assert.Equal(t, result.BalanceCheck.DifferenceCents, 0)
+		})
+	}
+}
+
+func TestImportHandler_ImportMT940(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	january := ":20:STMT1\n:25:NL91ABNA0417164300\n:28C:1\n" +
+		":61:250103D45,20NMSCNONREF//BREF1\n:86:/NAME/Supermarket/REMI/Groceries\n" +
+		":61:250105C2500,00NTRFNONREF//BREF2\n:86:/NAME/ACME Inc/REMI/Salary\n" +
+		":62F:C250105EUR2554,80\n-\n"
+	// Overlaps January by one entry.
+	february := ":20:STMT2\n:25:NL91ABNA0417164300\n:28C:2\n" +
+		":61:250105C2500,00NTRFNONREF//BREF2\n:86:/NAME/ACME Inc/REMI/Salary\n" +
+		":61:250201D800,00NTRFNONREF//BREF3\n:86:/NAME/Landlord/REMI/Rent\n" +
+		":62F:C250201EUR1754,80\n-\n"
+
+	route := fmt.Sprintf("/v1/accounts/%d/import/mt940", account.ID)
+
+	tests := []struct {
+		name            string
+		file            string
+		expectedStatus  int
+		expectedCount   int
+		expectedSkipped int
+	}{
+		{
+			name:           "Import",
+			file:           january,
+			expectedStatus: http.StatusCreated,
+			expectedCount:  2,
+		},
+		{
+			name:            "Import overlapping statement",
+			file:            february,
+			expectedStatus:  http.StatusCreated,
+			expectedCount:   1,
+			expectedSkipped: 1,
+		},
+		{
+			name:           "Invalid statement line",
+			file:           ":20:X\n:61:250210X12,50NTRFNONREF\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateUploadRequest(t, route, tt.file, map[string]any{"category_id": category.ID}, user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+			rr := httptest.NewRecorder()
+			handler.ImportMT940(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode >= 300 {
+				return
+			}
+
+			var resBody map[string]*service.ImportResult
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			result := resBody["import"]
+			assert.Equal(t, len(result.Transactions), tt.expectedCount)
+			assert.Equal(t, len(result.Skipped), tt.expectedSkipped)
+			assert.Equal(t, result.BalanceCheck.DifferenceCents, 0)
+		})
+	}
+
+	updated, err := svc.Account.GetByID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, updated.BalanceCents, account.BalanceCents-4520+250000-80000)
+}
+
+func TestImportHandler_ImportQIF(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	statement := "!Type:Bank\n" +
+		"D03/01/2025\nT-45.20\nPSupermarket\nLGroceries\n^\n" +
+		"D05/01/2025\nT2,500.00\nPSalary\nL" + category.Name + "\n^\n" +
+		"D06/01/2025\nT-10.00\nPTransfer\nL[Savings]\n^\n"
+
+	route := fmt.Sprintf("/v1/accounts/%d/import/qif", account.ID)
+
+	tests := []struct {
+		name                  string
+		file                  string
+		options               any
+		expectedStatus        int
+		expectedCount         int
+		expectedNewCategories int
+	}{
+		{
+			name:                  "Dry run",
+			file:                  statement,
+			options:               map[string]any{"category_id": category.ID, "date_order": "dmy", "dry_run": true},
+			expectedStatus:        http.StatusOK,
+			expectedNewCategories: 1,
+		},
+		{
+			name:                  "Import",
+			file:                  statement,
+			options:               map[string]any{"category_id": category.ID, "date_order": "dmy"},
+			expectedStatus:        http.StatusCreated,
+			expectedCount:         3,
+			expectedNewCategories: 1,
+		},
+		{
+			name:           "Uncategorized without category",
+			file:           statement,
+			options:        map[string]any{"date_order": "dmy"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Credit card statement",
+			file:           "!Type:CCard\nD03/01/2025\nT-45.20\nPSupermarket\nLGroceries\n^\n",
+			options:        map[string]any{"date_order": "dmy"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid date order",
+			file:           statement,
+			options:        map[string]any{"category_id": category.ID, "date_order": "ddmm"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateUploadRequest(t, route, tt.file, tt.options, user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+			rr := httptest.NewRecorder()
+			handler.ImportQIF(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode >= 300 {
+				return
+			}
+
+			var resBody map[string]*service.ImportResult
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			result := resBody["import"]
+			assert.Equal(t, len(result.Entries), 3)
+			assert.Equal(t, len(result.Transactions), tt.expectedCount)
+			assert.Equal(t, len(result.NewCategories), tt.expectedNewCategories)
+		})
+	}
+
+	categories, err := svc.Category.GetAll(user.ID)
+	assert.NilError(t, err)
+
+	var groceries *store.Category
+	for _, c := range categories {
+		if c.Name == "Groceries" {
+			groceries = c
+		}
+	}
+	if groceries == nil {
+		t.Fatal("Groceries category was not created")
+	}
+
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 4)
+
+	for _, transaction := range transactions {
+		switch transaction.Title {
+		case "Supermarket":
+			assert.Equal(t, transaction.CategoryID, groceries.ID)
+		case "Salary", "Transfer":
+			assert.Equal(t, transaction.CategoryID, category.ID)
+		}
+	}
+}
+
+func TestImportHandler_Duplicates(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	coffee, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		Title:       "Corner Coffee",
+		AccountID:   account.ID,
+		AmountCents: -450,
+		CategoryID:  category.ID,
+	})
+	assert.NilError(t, err)
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	statement := "Date,Title,Amount,Note\n" +
+		today + ",CORNER COFFEE 1234,-4.50,Card 5678\n" +
+		today + ",Bakery,-3.00,\n"
+
+	mapping := map[string]any{
+		"has_header":    true,
+		"date_column":   "Date",
+		"amount_column": "Amount",
+		"title_column":  "Title",
+		"note_column":   "Note",
+	}
+
+	route := fmt.Sprintf("/v1/accounts/%d/import/csv", account.ID)
+
+	send := func(t *testing.T, statement string, options map[string]any) (*http.Response, *service.ImportResult) {
+		options["mapping"] = mapping
+		options["category_id"] = category.ID
+
+		req := testutils.CreateUploadRequest(t, route, statement, options, user)
+		req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.ImportCSV(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		var resBody map[string]*service.ImportResult
+		json.NewDecoder(res.Body).Decode(&resBody)
+
+		return res, resBody["import"]
+	}
+
+	t.Run("Duplicates are skipped by default", func(t *testing.T) {
+		res, result := send(t, statement, map[string]any{"dry_run": true})
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		assert.Equal(t, len(result.Entries), 1)
+		assert.Equal(t, len(result.Duplicates), 1)
+		if len(result.Duplicates) == 1 {
+			assert.Equal(t, result.Duplicates[0].Index, 0)
+			assert.Equal(t, result.Duplicates[0].Decision, service.DuplicateSkip)
+			assert.Equal(t, result.Duplicates[0].Candidates[0].ID, coffee.ID)
+		}
+	})
+
+	t.Run("Import anyway", func(t *testing.T) {
+		res, result := send(t, statement, map[string]any{"dry_run": true, "duplicate_decisions": map[string]string{"0": "import"}})
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		assert.Equal(t, len(result.Entries), 2)
+		assert.Equal(t, len(result.Duplicates), 1)
+	})
+
+	t.Run("Invalid decision", func(t *testing.T) {
+		res, _ := send(t, statement, map[string]any{"duplicate_decisions": map[string]string{"0": "keep"}})
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Merge twice into one transaction", func(t *testing.T) {
+		twice := statement + today + ",CORNER COFFEE 1234,-4.50,\n"
+		res, _ := send(t, twice, map[string]any{"dry_run": true, "duplicate_decisions": map[string]string{"0": "merge", "2": "merge"}})
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		res, result := send(t, statement, map[string]any{"duplicate_decisions": map[string]string{"0": "merge"}})
+		assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+		assert.Equal(t, len(result.Transactions), 1)
+		assert.Equal(t, len(result.Merged), 1)
+		if len(result.Merged) == 1 {
+			assert.Equal(t, result.Merged[0].ID, coffee.ID)
+			assert.Equal(t, result.Merged[0].Note, "Card 5678")
+		}
+	})
+
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 3)
+}
+
+func TestImportHandler_MergeTransfer(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	checking := testutils.CreateTestAccount(t, svc.Account, user.ID, "Checking")
+	savings := testutils.CreateTestAccount(t, svc.Account, user.ID, "Savings")
+	fees := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	transfer, err := svc.Transfer.Create(user.ID, &service.CreateTransferParams{
+		FromAccountID: checking.ID,
+		ToAccountID:   savings.ID,
+		AmountCents:   1000,
+		FeeCents:      50,
+		FeeCategoryID: fees.ID,
+	})
+	assert.NilError(t, err)
+
+	date := time.Now().UTC().AddDate(0, 0, -2).Format(time.DateOnly)
+	statement := "Date,Title,Amount\n" + date + ",Transfer to Savings,-10.00\n"
+
+	req := testutils.CreateUploadRequest(t, fmt.Sprintf("/v1/accounts/%d/import/csv", checking.ID), statement, map[string]any{
+		"mapping": map[string]any{
+			"has_header":    true,
+			"date_column":   "Date",
+			"amount_column": "Amount",
+			"title_column":  "Title",
+		},
+		"category_id":         fees.ID,
+		"duplicate_decisions": map[string]string{"0": "merge"},
+	}, user)
+	req.SetPathValue("accountID", strconv.Itoa(int(checking.ID)))
+
+	rr := httptest.NewRecorder()
+	handler.ImportCSV(rr, req)
+
+	res := rr.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	var resBody map[string]*service.ImportResult
+	json.NewDecoder(res.Body).Decode(&resBody)
+	assert.Equal(t, len(resBody["import"].Merged), 1)
+	assert.Equal(t, resBody["import"].Merged[0].ID, transfer.From.ID)
+
+	merged, err := svc.Transfer.GetByID(user.ID, transfer.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, merged.From.Date.Format(time.DateOnly), date)
+	assert.Equal(t, merged.To.Date.Format(time.DateOnly), date)
+	assert.Equal(t, merged.Fee.Date.Format(time.DateOnly), date)
+}
+
+func TestImportHandler_Profiles(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+
+	create := func(t *testing.T, body any) *http.Response {
+		req := testutils.CreatePostRequest(t, "/v1/import-profiles", body, user)
+
+		rr := httptest.NewRecorder()
+		handler.CreateProfile(rr, req)
+
+		return rr.Result()
+	}
+
+	res := create(t, map[string]any{
+		"name":            "Credit card",
+		"has_header":      true,
+		"date_column":     "Date",
+		"amount_column":   "Amount",
+		"title_column":    "Description",
+		"sign_convention": "inverted",
+	})
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	var resBody map[string]*store.ImportProfile
+	json.NewDecoder(res.Body).Decode(&resBody)
+
+	profile := resBody["import_profile"]
+	assert.Equal(t, profile.Delimiter, ",")
+	assert.Equal(t, profile.DateFormat, "YYYY-MM-DD")
+	assert.Equal(t, profile.DecimalSeparator, ".")
+	assert.Equal(t, profile.SignConvention, "inverted")
+
+	invalid := []struct {
+		name           string
+		body           map[string]any
+		expectedStatus int
+	}{
+		{
+			name:           "Duplicate name",
+			body:           map[string]any{"name": "Credit card", "date_column": "Date", "amount_column": "Amount", "title_column": "Description"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Debit and credit columns missing",
+			body:           map[string]any{"name": "Bank", "date_column": "Date", "title_column": "Text", "sign_convention": "debit_credit"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Column names without header",
+			body:           map[string]any{"name": "Bank", "date_column": "Date", "amount_column": "2", "title_column": "3"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid date format",
+			body:           map[string]any{"name": "Bank", "has_header": true, "date_column": "Date", "date_format": "DDD", "amount_column": "Amount", "title_column": "Text"},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			res := create(t, tt.body)
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+		})
+	}
+
+	t.Run("Update profile", func(t *testing.T) {
+		req := testutils.CreatePostRequest(t, "/v1/import-profiles", map[string]any{"decimal_separator": ","}, user)
+		req.SetPathValue("profileID", strconv.Itoa(int(profile.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.UpdateProfileByID(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var resBody map[string]*store.ImportProfile
+		json.NewDecoder(res.Body).Decode(&resBody)
+
+		assert.Equal(t, resBody["import_profile"].DecimalSeparator, ",")
+		assert.Equal(t, resBody["import_profile"].Name, "Credit card")
+	})
+
+	t.Run("Delete profile", func(t *testing.T) {
+		req := testutils.CreatePostRequest(t, "/v1/import-profiles", nil, user)
+		req.SetPathValue("profileID", strconv.Itoa(int(profile.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.DeleteProfileByID(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		profiles, err := svc.Import.GetProfiles(user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(profiles), 0)
+	})
+}
+
+func TestImportHandler_ImportGokei(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestImportHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	restoredUser := testutils.CreateTestUser(t, svc.User, "restoreduser")
+	streamedUser := testutils.CreateTestUser(t, svc.User, "streameduser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID, "Checking")
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+
+	_, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		AccountID:   account.ID,
+		CategoryID:  category.ID,
+		AmountCents: -4520,
+		Title:       "Supermarket",
+		Splits: []service.TransactionSplitParams{
+			{CategoryID: category.ID, AmountCents: -4000},
+			{CategoryID: category.ID, AmountCents: -520},
+		},
+		Tags: []string{"groceries"},
+	})
+	assert.NilError(t, err)
+
+	export, err := svc.Export.Export(user.ID, &service.ExportParams{Format: service.ExportFormatJSON})
+	assert.NilError(t, err)
+
+	var file strings.Builder
+	assert.NilError(t, export.Encode(&file))
+
+	original, err := svc.Transaction.GetAll(user.ID)
+	assert.NilError(t, err)
+	originalAccount, err := svc.Account.GetByID(account.ID, user.ID)
+	assert.NilError(t, err)
+
+	partial, err := svc.Export.Export(user.ID, &service.ExportParams{
+		Format:   service.ExportFormatJSON,
+		DateFrom: time.Now().AddDate(0, 0, -1),
+	})
+	assert.NilError(t, err)
+
+	var partialFile strings.Builder
+	assert.NilError(t, partial.Encode(&partialFile))
+
+	tests := []struct {
+		name           string
+		file           string
+		body           bool
+		user           *store.User
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Not an export",
+			file:           "Date,Title,Amount\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "gokei JSON export",
+		},
+		{
+			name:           "Unsupported version",
+			file:           `{"version": 99}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "unsupported export version",
+		},
+		{
+			name:           "Date range export",
+			file:           partialFile.String(),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "must be a full export",
+		},
+		{
+			name: "Unknown account",
+			file: `{"version": 1, "initial_category_id": 1, "transactions": [` +
+				`{"id": 1, "account_id": 7, "category_id": 1, "title": "Coffee", "amount_cents": -300}]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "transaction 1: account_id",
+		},
+		{
+			name:           "Transactions not last",
+			file:           `{"version": 1, "initial_category_id": 1, "transactions": [], "accounts": []}`,
+			body:           true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "transactions must be the last field",
+		},
+		{
+			name:           "Restore",
+			file:           file.String(),
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Restore from JSON body",
+			file:           file.String(),
+			body:           true,
+			user:           streamedUser,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := restoredUser
+			if tt.user != nil {
+				user = tt.user
+			}
+
+			var req *http.Request
+			if tt.body {
+				req = testutils.CreatePostRequest(t, "/v1/import/gokei", json.RawMessage(tt.file), user)
+				req.Header.Set("Content-Type", "application/json")
+			} else {
+				req = testutils.CreateUploadRequest(t, "/v1/import/gokei", tt.file, nil, user)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ImportGokei(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if res.StatusCode >= 300 {
+				var resBody struct {
+					Error map[string]string `json:"error"`
+				}
+				json.NewDecoder(res.Body).Decode(&resBody)
+				assert.StringContains(t, resBody.Error["file"], tt.expectedError)
+				return
+			}
+
+			var resBody map[string]*service.RestoreResult
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			result := resBody["restore"]
+			assert.Equal(t, len(result.Accounts), 1)
+			assert.Equal(t, result.Transactions, len(original))
+			assert.Equal(t, result.RecurringTransactions, 1)
+			assert.Equal(t, len(result.NewCategories), 1)
+		})
+	}
+
+	accounts, err := svc.Account.GetAll(restoredUser.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(accounts), 1)
+	if len(accounts) == 1 {
+		assert.Equal(t, accounts[0].Name, "Checking")
+		assert.Equal(t, accounts[0].BalanceCents, originalAccount.BalanceCents)
+	}
+
+	transactions, _, err := svc.Transaction.List(restoredUser.ID, &service.ListTransactionsParams{
+		Sort:     "date",
+		Order:    "asc",
+		PageSize: service.MaxPageSize,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), len(original))
+
+	var restored *service.TransactionDetails
+	for _, transaction := range transactions {
+		if transaction.Title == "Supermarket" {
+			restored = transaction
+		}
+	}
+	if restored == nil {
+		t.Fatal("restored transaction not found")
+	}
+	assert.Equal(t, len(restored.Splits), 2)
+	assert.Equal(t, len(restored.Tags), 1)
+	assert.Equal(t, restored.Tags[0].UserID, restoredUser.ID)
+
+	rules, err := svc.Recurring.GetAll(restoredUser.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(rules), 1)
+}
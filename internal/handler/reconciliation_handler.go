@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *service.ReconciliationService
+}
+
+func NewReconciliationHandler(svc *service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: svc,
+	}
+}
+
+func (h *ReconciliationHandler) reconciliationError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *validator.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response.FailedValidationResponse(w, r, validationErr)
+	case errors.Is(err, database.ErrRecordNotFound):
+		response.NotFoundResponse(w, r)
+	case errors.Is(err, database.ErrEditConflict):
+		response.ConflictResponse(w, r)
+	case errors.Is(err, service.ErrNotLatestReconciliation):
+		response.ForbiddenResponse(w, r, err)
+	default:
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+// Create reconciles the account against a statement. A dry run answers with
+// 200 and the difference it found, without reconciling anything.
+func (h *ReconciliationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var input service.CreateReconciliationParams
+	err = response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	reconciliation, err := h.reconciliationService.Create(ctxUser.ID, int32(accountID), &input)
+	if err != nil {
+		h.reconciliationError(w, r, err)
+		return
+	}
+
+	if input.DryRun {
+		err = response.OK(w, response.Envelope{"reconciliation": reconciliation})
+	} else {
+		err = response.Created(w, response.Envelope{"reconciliation": reconciliation}, nil)
+	}
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ReconciliationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	reconciliations, err := h.reconciliationService.List(ctxUser.ID, int32(accountID))
+	if err != nil {
+		h.reconciliationError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"reconciliations": reconciliations})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ReconciliationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+	reconciliationID, err := readIntParam(r, "reconciliationID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	reconciliation, err := h.reconciliationService.GetByID(ctxUser.ID, int32(accountID), int32(reconciliationID))
+	if err != nil {
+		h.reconciliationError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"reconciliation": reconciliation})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *ReconciliationHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
+	accountID, err := readIntParam(r, "accountID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+	reconciliationID, err := readIntParam(r, "reconciliationID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.reconciliationService.DeleteByID(ctxUser.ID, int32(accountID), int32(reconciliationID))
+	if err != nil {
+		h.reconciliationError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "reconciliation successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
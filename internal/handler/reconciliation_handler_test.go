@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestReconciliationHandler(t *testing.T) (*ReconciliationHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewReconciliationHandler(svc.Reconciliation)
+
+	return handler, svc, cleanup
+}
+
+func TestReconciliationHandler(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestReconciliationHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	otherAccount := testutils.CreateTestAccount(t, svc.Account, otherUser.ID)
+
+	transactions, _, err := svc.Transaction.List(user.ID, &service.ListTransactionsParams{
+		AccountIDs: []int32{account.ID},
+		Sort:       "date",
+		Order:      "desc",
+		PageSize:   service.DefaultPageSize,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 1)
+	initial := transactions[0]
+
+	cleared := testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+	pending, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		Title:       "Pending",
+		AccountID:   account.ID,
+		AmountCents: -500,
+		CategoryID:  category.ID,
+		Status:      store.TransactionStatusPending,
+	})
+	assert.NilError(t, err)
+
+	statementDate := time.Now().UTC().Truncate(24 * time.Hour)
+	clearedCents := initial.AmountCents + cleared.AmountCents
+
+	send := func(t *testing.T, accountID int32, body any) *http.Response {
+		t.Helper()
+
+		req := testutils.CreatePostRequest(t, "/v1/accounts/reconciliations", body, user)
+		req.SetPathValue("accountID", strconv.Itoa(int(accountID)))
+
+		rr := httptest.NewRecorder()
+		handler.Create(rr, req)
+
+		return rr.Result()
+	}
+
+	decode := func(t *testing.T, res *http.Response) *service.ReconciliationDetails {
+		t.Helper()
+
+		var resBody map[string]*service.ReconciliationDetails
+		assert.NilError(t, json.NewDecoder(res.Body).Decode(&resBody))
+		return resBody["reconciliation"]
+	}
+
+	list := func(t *testing.T) []store.Reconciliation {
+		t.Helper()
+
+		reconciliations, err := svc.Reconciliation.List(user.ID, account.ID)
+		assert.NilError(t, err)
+		return reconciliations
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		res := send(t, account.ID, map[string]any{
+			"statement_date":          statementDate,
+			"statement_balance_cents": clearedCents - 1000,
+			"transaction_ids":         []int32{initial.ID, cleared.ID},
+			"dry_run":                 true,
+		})
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		reconciliation := decode(t, res)
+		assert.Equal(t, reconciliation.ReconciledBalanceCents, clearedCents)
+		assert.Equal(t, reconciliation.DifferenceCents, -1000)
+		assert.Equal(t, len(list(t)), 0)
+	})
+
+	t.Run("Invalid reconciliations", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			accountID      int32
+			body           map[string]any
+			expectedStatus int
+		}{
+			{
+				name:           "No statement date",
+				accountID:      account.ID,
+				body:           map[string]any{"statement_balance_cents": clearedCents, "transaction_ids": []int32{initial.ID, cleared.ID}},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Unbalanced without adjustment",
+				accountID:      account.ID,
+				body:           map[string]any{"statement_date": statementDate, "statement_balance_cents": clearedCents - 1000, "transaction_ids": []int32{initial.ID, cleared.ID}},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Pending transaction",
+				accountID:      account.ID,
+				body:           map[string]any{"statement_date": statementDate, "statement_balance_cents": clearedCents + pending.AmountCents, "transaction_ids": []int32{initial.ID, cleared.ID, pending.ID}},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Repeated transaction",
+				accountID:      account.ID,
+				body:           map[string]any{"statement_date": statementDate, "statement_balance_cents": clearedCents, "transaction_ids": []int32{initial.ID, cleared.ID, cleared.ID}},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Transaction after the statement",
+				accountID:      account.ID,
+				body:           map[string]any{"statement_date": statementDate.AddDate(0, 0, -7), "statement_balance_cents": clearedCents, "transaction_ids": []int32{initial.ID, cleared.ID}},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Other user's account",
+				accountID:      otherAccount.ID,
+				body:           map[string]any{"statement_date": statementDate, "statement_balance_cents": 0, "transaction_ids": []int32{}},
+				expectedStatus: http.StatusNotFound,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res := send(t, tt.accountID, tt.body)
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, tt.expectedStatus)
+			})
+		}
+
+		assert.Equal(t, len(list(t)), 0)
+	})
+
+	res := send(t, account.ID, map[string]any{
+		"statement_date":          statementDate,
+		"statement_balance_cents": clearedCents - 1000,
+		"transaction_ids":         []int32{initial.ID, cleared.ID},
+		"create_adjustment":       true,
+	})
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	created := decode(t, res)
+	assert.Equal(t, created.StatementBalanceCents, clearedCents-1000)
+	assert.Equal(t, created.DifferenceCents, -1000)
+	assert.Equal(t, created.AdjustmentTransactionID != nil, true)
+	assert.Equal(t, len(created.TransactionIDs), 3)
+
+	t.Run("Transactions are reconciled", func(t *testing.T) {
+		adjustment, err := svc.Transaction.GetByID(*created.AdjustmentTransactionID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, adjustment.AmountCents, -1000)
+		assert.Equal(t, adjustment.Status, store.TransactionStatusReconciled)
+
+		transaction, err := svc.Transaction.GetByID(cleared.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Status, store.TransactionStatusReconciled)
+
+		transaction, err = svc.Transaction.GetByID(pending.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Status, store.TransactionStatusPending)
+
+		err = svc.Transaction.DeleteByID(cleared.ID, user.ID)
+		assert.Equal(t, errors.Is(err, service.ErrReconciledTransaction), true)
+
+		updated, err := svc.Account.GetByID(account.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.BalanceCents, clearedCents-1000+pending.AmountCents)
+		assert.Equal(t, updated.ClearedBalanceCents, clearedCents-1000)
+	})
+
+	t.Run("Reconciled transactions can't be ticked again", func(t *testing.T) {
+		res := send(t, account.ID, map[string]any{
+			"statement_date":          statementDate,
+			"statement_balance_cents": clearedCents - 1000,
+			"transaction_ids":         []int32{cleared.ID},
+		})
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Statement before the last reconciliation", func(t *testing.T) {
+		res := send(t, account.ID, map[string]any{
+			"statement_date":          statementDate.AddDate(0, 0, -1),
+			"statement_balance_cents": clearedCents - 1000,
+			"transaction_ids":         []int32{},
+		})
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Nothing left to reconcile", func(t *testing.T) {
+		res := send(t, account.ID, map[string]any{
+			"statement_date":          statementDate.AddDate(0, 1, 0),
+			"statement_balance_cents": clearedCents - 1000,
+			"transaction_ids":         []int32{},
+		})
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+		reconciliation := decode(t, res)
+		assert.Equal(t, reconciliation.DifferenceCents, 0)
+		assert.Equal(t, reconciliation.AdjustmentTransactionID == nil, true)
+		assert.Equal(t, len(reconciliation.TransactionIDs), 0)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		req := testutils.CreateGetRequest(t, "/v1/accounts/reconciliations", user)
+		req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.GetAll(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var resBody map[string][]store.Reconciliation
+		json.NewDecoder(res.Body).Decode(&resBody)
+		assert.Equal(t, len(resBody["reconciliations"]), 2)
+		assert.Equal(t, resBody["reconciliations"][1].ID, created.ID)
+		assert.Equal(t, resBody["reconciliations"][1].StatementBalanceCents, clearedCents-1000)
+
+		req = testutils.CreateGetRequest(t, "/v1/accounts/reconciliations", otherUser)
+		req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+		rr = httptest.NewRecorder()
+		handler.GetAll(rr, req)
+
+		assert.Equal(t, rr.Result().StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Get by ID", func(t *testing.T) {
+		req := testutils.CreateGetRequest(t, "/v1/accounts/reconciliations", user)
+		req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+		req.SetPathValue("reconciliationID", strconv.Itoa(int(created.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.GetByID(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		reconciliation := decode(t, res)
+		assert.Equal(t, reconciliation.ID, created.ID)
+		assert.Equal(t, len(reconciliation.TransactionIDs), 3)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		remove := func(t *testing.T, reconciliationID int32) int {
+			t.Helper()
+
+			req := testutils.CreatePostRequest(t, "/v1/accounts/reconciliations", nil, user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+			req.SetPathValue("reconciliationID", strconv.Itoa(int(reconciliationID)))
+
+			rr := httptest.NewRecorder()
+			handler.DeleteByID(rr, req)
+
+			return rr.Result().StatusCode
+		}
+
+		latest := list(t)[0]
+		assert.Equal(t, remove(t, created.ID), http.StatusForbidden)
+		assert.Equal(t, remove(t, latest.ID), http.StatusOK)
+		assert.Equal(t, remove(t, latest.ID), http.StatusNotFound)
+		assert.Equal(t, remove(t, created.ID), http.StatusOK)
+		assert.Equal(t, len(list(t)), 0)
+
+		_, err := svc.Transaction.GetByID(*created.AdjustmentTransactionID, user.ID)
+		assert.Equal(t, errors.Is(err, database.ErrRecordNotFound), true)
+
+		transaction, err := svc.Transaction.GetByID(cleared.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Status, store.TransactionStatusCleared)
+
+		updated, err := svc.Account.GetByID(account.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, updated.BalanceCents, clearedCents+pending.AmountCents)
+	})
+}
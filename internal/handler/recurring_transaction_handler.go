@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+type RecurringTransactionHandler struct {
+	recurringService *service.RecurringTransactionService
+}
+
+func NewRecurringTransactionHandler(svc *service.RecurringTransactionService) *RecurringTransactionHandler {
+	return &RecurringTransactionHandler{
+		recurringService: svc,
+	}
+}
+
+func (h *RecurringTransactionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input service.CreateRecurringTransactionParams
+	err := response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transaction, err := h.recurringService.Create(ctxUser.ID, &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrInvalidCategory):
+			response.BadRequestResponse(w, r, err)
+		case errors.Is(err, database.ErrInvalidAccount), errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrTransactionWithInitialCategory):
+			response.ForbiddenResponse(w, r, err)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/recurring-transactions/%d", transaction.ID))
+
+	err = response.Created(w, response.Envelope{"recurring_transaction": transaction}, headers)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	ctxUser := appcontext.GetContextUser(r)
+
+	transactions, err := h.recurringService.GetAll(ctxUser.ID)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"recurring_transactions": transactions})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transaction, err := h.recurringService.GetByID(ctxUser.ID, int32(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"recurring_transaction": transaction})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.recurringService.DeleteByID(ctxUser.ID, int32(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "recurring transaction successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) UpdateByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var input service.UpdateRecurringTransactionParams
+	err = response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transaction, err := h.recurringService.UpdateByID(ctxUser.ID, int32(id), &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, database.ErrEditConflict):
+			response.ConflictResponse(w, r)
+		case errors.Is(err, database.ErrInvalidCategory):
+			response.BadRequestResponse(w, r, err)
+		case errors.Is(err, service.ErrTransactionWithInitialCategory):
+			response.ForbiddenResponse(w, r, err)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"recurring_transaction": transaction})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) PauseByID(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+func (h *RecurringTransactionHandler) ResumeByID(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *RecurringTransactionHandler) setActive(w http.ResponseWriter, r *http.Request, isActive bool) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transaction, err := h.recurringService.SetActiveByID(ctxUser.ID, int32(id), isActive)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, database.ErrEditConflict):
+			response.ConflictResponse(w, r)
+		case errors.Is(err, database.ErrInvalidCategory):
+			response.BadRequestResponse(w, r, err)
+		case errors.Is(err, service.ErrTransactionWithInitialCategory):
+			response.ForbiddenResponse(w, r, err)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"recurring_transaction": transaction})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	count, err := readIntQuery(r, "count", 10)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	occurrences, err := h.recurringService.Preview(ctxUser.ID, int32(id), time.Now(), count)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"occurrences": occurrences})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) GetExceptions(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	exceptions, err := h.recurringService.GetExceptions(ctxUser.ID, int32(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"exceptions": exceptions})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) CreateException(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var input service.CreateRecurringTransactionExceptionParams
+	err = response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	exception, err := h.recurringService.CreateException(ctxUser.ID, int32(id), &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.Created(w, response.Envelope{"exception": exception}, nil)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *RecurringTransactionHandler) DeleteException(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "recurringTransactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	exceptionID, err := readIntParam(r, "exceptionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.recurringService.DeleteException(ctxUser.ID, int32(id), int32(exceptionID))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "exception successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
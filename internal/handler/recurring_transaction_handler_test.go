@@ -0,0 +1,1133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/recurrence"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestRecurringTransactionHandler(t *testing.T) (*RecurringTransactionHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewRecurringTransactionHandler(svc.Recurring)
+
+	return handler, svc, cleanup
+}
+
+func expectFieldError(field string) func(*testing.T, *http.Response) {
+	return func(t *testing.T, r *http.Response) {
+		var resBody map[string]map[string]string
+		json.NewDecoder(r.Body).Decode(&resBody)
+
+		_, ok := resBody["error"][field]
+		assert.Equal(t, ok, true)
+	}
+}
+
+func TestRecurringTransactionHandler_Create(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	route := "/v1/recurring-transactions"
+
+	tests := []struct {
+		name           string
+		requestBody    any
+		expectedStatus int
+		setupRequest   func(*testing.T) *http.Request
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name: "Create recurring transaction",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+				"day_month":    1,
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*store.RecurringTransaction
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				transaction := resBody["recurring_transaction"]
+				assert.Equal(t, transaction.Title, "Rent")
+				assert.Equal(t, transaction.AmountCents, -50000)
+				assert.Equal(t, transaction.AccountID, account.ID)
+				assert.Equal(t, transaction.CategoryID, category.ID)
+				assert.Equal(t, transaction.Frequency, store.RecurrenceFrequencyMonthly)
+				assert.Equal(t, transaction.Interval, 1)
+				assert.Equal(t, *transaction.DayMonth, 1)
+				assert.Equal(t, transaction.IsActive, true)
+
+				location := r.Header.Get("Location")
+				assert.Equal(t, location, fmt.Sprintf("%s/%d", route, transaction.ID))
+			},
+		},
+		{
+			name: "Create paused recurring transaction",
+			requestBody: map[string]any{
+				"title":        "Gym",
+				"amount_cents": -3000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+				"is_active":    false,
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*store.RecurringTransaction
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				assert.Equal(t, resBody["recurring_transaction"].IsActive, false)
+			},
+		},
+		{
+			name: "Category doesn't exist",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  99,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Can't use initial category",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  1,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Account doesn't exist",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   99,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Fail to create in another user's account",
+			setupRequest: func(t *testing.T) *http.Request {
+				user2 := testutils.CreateTestUser(t, svc.User, "user2")
+				account2 := testutils.CreateTestAccount(t, svc.Account, user2.ID)
+				requestBody := map[string]any{
+					"title":        "Rent",
+					"amount_cents": -50000,
+					"account_id":   account2.ID,
+					"category_id":  category.ID,
+					"frequency":    "monthly",
+					"start_date":   "2025-01-01T00:00:00Z",
+				}
+				return testutils.CreatePostRequest(t, route, requestBody, user)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Weekly without day_week",
+			requestBody: map[string]any{
+				"title":        "Cleaning",
+				"amount_cents": -2000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "weekly",
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("day_week"),
+		},
+		{
+			name: "day_month on weekly rule",
+			requestBody: map[string]any{
+				"title":        "Cleaning",
+				"amount_cents": -2000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "weekly",
+				"start_date":   "2025-01-01T00:00:00Z",
+				"day_week":     1,
+				"day_month":    15,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("day_month"),
+		},
+		{
+			name: "day_month out of range",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+				"day_month":    32,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("day_month"),
+		},
+		{
+			name: "end_date before start_date",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+				"end_date":     "2024-12-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("end_date"),
+		},
+		{
+			name: "Zero amount",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": 0,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("amount_cents"),
+		},
+		{
+			name: "Amount overflows INT",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": 3000000000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("amount_cents"),
+		},
+		{
+			name: "Invalid frequency and interval",
+			requestBody: map[string]any{
+				"title":        "Rent",
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "hourly",
+				"interval":     0,
+				"start_date":   "2025-01-01T00:00:00Z",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]map[string]string
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				assert.Equal(t, len(resBody["error"]), 2)
+				assert.StringContains(t, resBody["error"]["frequency"], "Invalid frequency")
+				assert.StringContains(t, resBody["error"]["interval"], "greater than zero")
+			},
+		},
+		{
+			name: "Create from RRULE",
+			requestBody: map[string]any{
+				"title":        "Cleaning",
+				"amount_cents": -2000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"start_date":   "2025-01-01T00:00:00Z",
+				"rrule":        "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;COUNT=10",
+			},
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*store.RecurringTransaction
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				transaction := resBody["recurring_transaction"]
+				assert.Equal(t, transaction.Frequency, store.RecurrenceFrequencyWeekly)
+				assert.Equal(t, transaction.Interval, 2)
+				assert.Equal(t, *transaction.DayWeek, 5)
+				assert.Equal(t, *transaction.MaxOccurrences, 10)
+			},
+		},
+		{
+			name: "RRULE with schedule fields",
+			requestBody: map[string]any{
+				"title":        "Cleaning",
+				"amount_cents": -2000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "weekly",
+				"start_date":   "2025-01-01T00:00:00Z",
+				"rrule":        "FREQ=WEEKLY",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("rrule"),
+		},
+		{
+			name: "Unsupported RRULE",
+			requestBody: map[string]any{
+				"title":        "Cleaning",
+				"amount_cents": -2000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"start_date":   "2025-01-01T00:00:00Z",
+				"rrule":        "FREQ=MONTHLY;BYDAY=1MO",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("rrule"),
+		},
+		{
+			name: "Missing fields",
+			requestBody: map[string]any{
+				"amount_cents": -50000,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+				"frequency":    "monthly",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]map[string]string
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				assert.Equal(t, resBody["error"]["title"], "Must be provided")
+				assert.Equal(t, resBody["error"]["start_date"], "Must be provided")
+			},
+		},
+		{
+			name: "Invalid key",
+			requestBody: map[string]any{
+				"test": "test",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Bad JSON",
+			requestBody:    "",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.setupRequest != nil {
+				req = tt.setupRequest(t)
+			} else {
+				req = testutils.CreatePostRequest(t, route, tt.requestBody, user)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, req)
+
+			rs := rr.Result()
+			defer rs.Body.Close()
+
+			assert.Equal(t, rs.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, rs)
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionHandler_GetAll(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	route := "/v1/recurring-transactions"
+
+	tests := []struct {
+		name           string
+		expectedStatus int
+		setup          func(*testing.T)
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Get all recurring transactions",
+			expectedStatus: http.StatusOK,
+			setup: func(t *testing.T) {
+				testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+				testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+			},
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*[]store.RecurringTransaction
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				transactions := resBody["recurring_transactions"]
+				assert.Equal(t, len(*transactions), 2)
+			},
+		},
+		{
+			name:           "Only get user recurring transactions",
+			expectedStatus: http.StatusOK,
+			setup: func(t *testing.T) {
+				user2 := testutils.CreateTestUser(t, svc.User, "user2")
+				account2 := testutils.CreateTestAccount(t, svc.Account, user2.ID)
+				testutils.CreateTestRecurringTransaction(t, svc.Recurring, user2.ID, account2.ID, category.ID)
+			},
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*[]store.RecurringTransaction
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				transactions := resBody["recurring_transactions"]
+				assert.Equal(t, len(*transactions), 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			req := testutils.CreateGetRequest(t, route, user)
+
+			rr := httptest.NewRecorder()
+			handler.GetAll(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionHandler_GetByID(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	route := "/v1/recurring-transactions"
+
+	tests := []struct {
+		name           string
+		transactionID  string
+		expectedStatus int
+		setup          func(*testing.T) int32
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Get recurring transaction",
+			expectedStatus: http.StatusOK,
+			setup: func(t *testing.T) int32 {
+				transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+				return transaction.ID
+			},
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*store.RecurringTransaction
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				transaction := resBody["recurring_transaction"]
+				assert.Equal(t, transaction.AccountID, account.ID)
+				assert.Equal(t, transaction.CategoryID, category.ID)
+				assert.Equal(t, transaction.Title, "Test Recurring Transaction")
+			},
+		},
+		{
+			name:           "Fail to get other user's recurring transaction",
+			expectedStatus: http.StatusNotFound,
+			setup: func(t *testing.T) int32 {
+				user2 := testutils.CreateTestUser(t, svc.User, "user2")
+				account2 := testutils.CreateTestAccount(t, svc.Account, user2.ID)
+				transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user2.ID, account2.ID, category.ID)
+				return transaction.ID
+			},
+		},
+		{
+			name:           "Not found",
+			expectedStatus: http.StatusNotFound,
+			transactionID:  "999",
+		},
+		{
+			name:           "Negative ID",
+			expectedStatus: http.StatusNotFound,
+			transactionID:  "-1",
+		},
+		{
+			name:           "Invalid ID",
+			expectedStatus: http.StatusBadRequest,
+			transactionID:  "test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				id := tt.setup(t)
+				tt.transactionID = strconv.Itoa(int(id))
+			}
+
+			req := testutils.CreateGetRequest(t, route, user)
+			req.SetPathValue("recurringTransactionID", tt.transactionID)
+
+			rr := httptest.NewRecorder()
+			handler.GetByID(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionHandler_UpdateByID(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+	transactionID := strconv.Itoa(int(transaction.ID))
+	route := "/v1/recurring-transactions"
+
+	tests := []struct {
+		name           string
+		body           any
+		transactionID  string
+		expectedStatus int
+		setup          func(*testing.T) int32
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name: "Update recurring transaction",
+			body: map[string]any{
+				"title":        "updated recurring transaction",
+				"amount_cents": -321,
+				"frequency":    "weekly",
+				"day_week":     1,
+				"end_date":     "2026-01-01T00:00:00Z",
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				updated, err := svc.Recurring.GetByID(user.ID, transaction.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, updated.Title, "updated recurring transaction")
+				assert.Equal(t, updated.AmountCents, -321)
+				assert.Equal(t, updated.Frequency, store.RecurrenceFrequencyWeekly)
+				assert.Equal(t, *updated.DayWeek, 1)
+				assert.Equal(t, updated.EndDate.Year(), 2026)
+				assert.Equal(t, updated.CategoryID, category.ID)
+				assert.Equal(t, updated.Version, transaction.Version+1)
+			},
+		},
+		{
+			name: "Pause through is_active",
+			body: map[string]any{
+				"is_active": false,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				updated, err := svc.Recurring.GetByID(user.ID, transaction.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, updated.IsActive, false)
+			},
+		},
+		{
+			name: "Fail to update other user's recurring transaction",
+			body: map[string]any{
+				"title": "new title",
+			},
+			expectedStatus: http.StatusNotFound,
+			setup: func(t *testing.T) int32 {
+				user2 := testutils.CreateTestUser(t, svc.User, "user2")
+				account2 := testutils.CreateTestAccount(t, svc.Account, user2.ID)
+				transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user2.ID, account2.ID, category.ID)
+				return transaction.ID
+			},
+		},
+		{
+			name: "Can't use initial category",
+			body: map[string]any{
+				"category_id": 1,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Invalid category",
+			body: map[string]any{
+				"category_id": 99,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid interval",
+			body: map[string]any{
+				"interval": 0,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("interval"),
+		},
+		{
+			name: "Amount overflows INT",
+			body: map[string]any{
+				"amount_cents": -3000000000,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("amount_cents"),
+		},
+		{
+			name: "end_date before start_date",
+			body: map[string]any{
+				"end_date": "2024-01-01T00:00:00Z",
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("end_date"),
+		},
+		{
+			name: "Switch to monthly drops day_week",
+			body: map[string]any{
+				"frequency": "monthly",
+				"day_month": 15,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				updated, err := svc.Recurring.GetByID(user.ID, transaction.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, updated.Frequency, store.RecurrenceFrequencyMonthly)
+				assert.Equal(t, *updated.DayMonth, 15)
+				assert.Equal(t, updated.DayWeek == nil, true)
+			},
+		},
+		{
+			name: "Clear end_date and day_month",
+			body: map[string]any{
+				"end_date":  nil,
+				"day_month": nil,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				updated, err := svc.Recurring.GetByID(user.ID, transaction.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, updated.EndDate == nil, true)
+				assert.Equal(t, updated.DayMonth == nil, true)
+				assert.Equal(t, updated.Frequency, store.RecurrenceFrequencyMonthly)
+			},
+		},
+		{
+			name: "Invalid key",
+			body: map[string]any{
+				"test": "test",
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not found",
+			body:           map[string]any{},
+			expectedStatus: http.StatusNotFound,
+			transactionID:  "999",
+		},
+		{
+			name:           "Invalid ID",
+			expectedStatus: http.StatusBadRequest,
+			transactionID:  "test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				id := tt.setup(t)
+				tt.transactionID = strconv.Itoa(int(id))
+			}
+
+			req := testutils.CreatePostRequest(t, route, tt.body, user)
+			req.SetPathValue("recurringTransactionID", tt.transactionID)
+
+			rr := httptest.NewRecorder()
+			handler.UpdateByID(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionHandler_PauseResume(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+	route := fmt.Sprintf("/v1/recurring-transactions/%d", transaction.ID)
+
+	req := testutils.CreatePostRequest(t, route+"/pause", nil, user)
+	req.SetPathValue("recurringTransactionID", strconv.Itoa(int(transaction.ID)))
+	rr := httptest.NewRecorder()
+	handler.PauseByID(rr, req)
+	assert.Equal(t, rr.Result().StatusCode, http.StatusOK)
+
+	paused, err := svc.Recurring.GetByID(user.ID, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, paused.IsActive, false)
+
+	req = testutils.CreatePostRequest(t, route+"/resume", nil, user)
+	req.SetPathValue("recurringTransactionID", strconv.Itoa(int(transaction.ID)))
+	rr = httptest.NewRecorder()
+	handler.ResumeByID(rr, req)
+	assert.Equal(t, rr.Result().StatusCode, http.StatusOK)
+
+	resumed, err := svc.Recurring.GetByID(user.ID, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, resumed.IsActive, true)
+
+	req = testutils.CreatePostRequest(t, "/v1/recurring-transactions/999/pause", nil, user)
+	req.SetPathValue("recurringTransactionID", "999")
+	rr = httptest.NewRecorder()
+	handler.PauseByID(rr, req)
+	assert.Equal(t, rr.Result().StatusCode, http.StatusNotFound)
+}
+
+func TestRecurringTransactionHandler_DeleteByID(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	route := "/v1/recurring-transactions"
+
+	tests := []struct {
+		name           string
+		transactionID  string
+		expectedStatus int
+		setup          func(*testing.T) int32
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Delete recurring transaction",
+			expectedStatus: http.StatusOK,
+			setup: func(t *testing.T) int32 {
+				transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+				return transaction.ID
+			},
+			validate: func(t *testing.T, r *http.Response) {
+				transactions, err := svc.Recurring.GetAll(user.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, len(transactions), 0)
+			},
+		},
+		{
+			name:           "Fail to delete other user's recurring transaction",
+			expectedStatus: http.StatusNotFound,
+			setup: func(t *testing.T) int32 {
+				user2 := testutils.CreateTestUser(t, svc.User, "user2")
+				account2 := testutils.CreateTestAccount(t, svc.Account, user2.ID)
+				transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user2.ID, account2.ID, category.ID)
+				return transaction.ID
+			},
+		},
+		{
+			name:           "Not found",
+			expectedStatus: http.StatusNotFound,
+			transactionID:  "999",
+		},
+		{
+			name:           "Invalid ID",
+			expectedStatus: http.StatusBadRequest,
+			transactionID:  "test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				id := tt.setup(t)
+				tt.transactionID = strconv.Itoa(int(id))
+			}
+
+			req := testutils.CreateGetRequest(t, route, user)
+			req.SetPathValue("recurringTransactionID", tt.transactionID)
+
+			rr := httptest.NewRecorder()
+			handler.DeleteByID(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionHandler_Preview(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+	transactionID := strconv.Itoa(int(transaction.ID))
+	route := fmt.Sprintf("/v1/recurring-transactions/%d/preview", transaction.ID)
+
+	tests := []struct {
+		name           string
+		query          string
+		transactionID  string
+		expectedStatus int
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Default count",
+			transactionID:  transactionID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string][]recurrence.Occurrence
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				occurrences := resBody["occurrences"]
+				assert.Equal(t, len(occurrences), 10)
+				for _, occurrence := range occurrences {
+					assert.Equal(t, occurrence.Date.Day(), 1)
+					assert.Equal(t, occurrence.AmountCents, transaction.AmountCents)
+				}
+			},
+		},
+		{
+			name:           "Custom count",
+			query:          "?count=3",
+			transactionID:  transactionID,
+			expectedStatus: http.StatusOK,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string][]recurrence.Occurrence
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				assert.Equal(t, len(resBody["occurrences"]), 3)
+			},
+		},
+		{
+			name:           "Count out of range",
+			query:          "?count=0",
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid count",
+			query:          "?count=test",
+			transactionID:  transactionID,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not found",
+			transactionID:  "999",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			transactionID:  "test",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateGetRequest(t, route+tt.query, user)
+			req.SetPathValue("recurringTransactionID", tt.transactionID)
+
+			rr := httptest.NewRecorder()
+			handler.Preview(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+}
+
+func TestRecurringTransactionHandler_Exceptions(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestRecurringTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	transaction := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+	transactionID := strconv.Itoa(int(transaction.ID))
+	route := fmt.Sprintf("/v1/recurring-transactions/%d/exceptions", transaction.ID)
+
+	next := time.Date(time.Now().Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           any
+		transactionID  string
+		expectedStatus int
+		validate       func(*testing.T, *http.Response)
+	}{
+		{
+			name: "Skip occurrence",
+			body: map[string]any{
+				"occurrence_date": next,
+				"skip":            true,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, r *http.Response) {
+				var resBody map[string]*store.RecurringTransactionException
+				json.NewDecoder(r.Body).Decode(&resBody)
+
+				exception := resBody["exception"]
+				assert.Equal(t, exception.RecurringTransactionID, transaction.ID)
+				assert.Equal(t, exception.OccurrenceDate.Equal(next), true)
+				assert.Equal(t, exception.Skip, true)
+
+				occurrences, err := svc.Recurring.Preview(user.ID, transaction.ID, next, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, occurrences[0].Date.Equal(next.AddDate(0, 1, 0)), true)
+			},
+		},
+		{
+			name: "Move and override amount",
+			body: map[string]any{
+				"occurrence_date": next.AddDate(0, 1, 0),
+				"new_date":        next.AddDate(0, 1, 2),
+				"amount_cents":    -1750,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusCreated,
+			validate: func(t *testing.T, r *http.Response) {
+				occurrences, err := svc.Recurring.Preview(user.ID, transaction.ID, next, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, occurrences[0].ScheduledDate.Equal(next.AddDate(0, 1, 0)), true)
+				assert.Equal(t, occurrences[0].Date.Equal(next.AddDate(0, 1, 2)), true)
+				assert.Equal(t, occurrences[0].AmountCents, -1750)
+			},
+		},
+		{
+			name: "Exception already exists",
+			body: map[string]any{
+				"occurrence_date": next,
+				"amount_cents":    -10,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("occurrence_date"),
+		},
+		{
+			name: "Date is not scheduled",
+			body: map[string]any{
+				"occurrence_date": next.AddDate(0, 0, 3),
+				"skip":            true,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("occurrence_date"),
+		},
+		{
+			name: "Skip with override",
+			body: map[string]any{
+				"occurrence_date": next.AddDate(0, 2, 0),
+				"skip":            true,
+				"amount_cents":    -10,
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("skip"),
+		},
+		{
+			name: "Nothing to change",
+			body: map[string]any{
+				"occurrence_date": next.AddDate(0, 2, 0),
+			},
+			transactionID:  transactionID,
+			expectedStatus: http.StatusUnprocessableEntity,
+			validate:       expectFieldError("skip"),
+		},
+		{
+			name: "Recurring transaction not found",
+			body: map[string]any{
+				"occurrence_date": next,
+				"skip":            true,
+			},
+			transactionID:  "999",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			transactionID:  "test",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreatePostRequest(t, route, tt.body, user)
+			req.SetPathValue("recurringTransactionID", tt.transactionID)
+
+			rr := httptest.NewRecorder()
+			handler.CreateException(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.validate != nil {
+				tt.validate(t, res)
+			}
+		})
+	}
+
+	req := testutils.CreateGetRequest(t, route, user)
+	req.SetPathValue("recurringTransactionID", transactionID)
+	rr := httptest.NewRecorder()
+	handler.GetExceptions(rr, req)
+	assert.Equal(t, rr.Result().StatusCode, http.StatusOK)
+
+	var resBody map[string][]*store.RecurringTransactionException
+	json.NewDecoder(rr.Result().Body).Decode(&resBody)
+	exceptions := resBody["exceptions"]
+	assert.Equal(t, len(exceptions), 2)
+
+	req = testutils.CreateGetRequest(t, route, user)
+	req.SetPathValue("recurringTransactionID", transactionID)
+	req.SetPathValue("exceptionID", strconv.Itoa(int(exceptions[0].ID)))
+	rr = httptest.NewRecorder()
+	handler.DeleteException(rr, req)
+	assert.Equal(t, rr.Result().StatusCode, http.StatusOK)
+
+	rr = httptest.NewRecorder()
+	handler.DeleteException(rr, req)
+	assert.Equal(t, rr.Result().StatusCode, http.StatusNotFound)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(svc *service.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: svc,
+	}
+}
+
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input service.CreateTagParams
+	err := response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	tag, err := h.tagService.Create(ctxUser.ID, &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, service.ErrDuplicateTagName):
+			response.BadRequestResponse(w, r, fmt.Errorf("A tag with this name already exists"))
+		case errors.Is(err, database.ErrInvalidUser):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/tags/%d", tag.ID))
+
+	err = response.Created(w, response.Envelope{"tag": tag}, headers)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	ctxUser := appcontext.GetContextUser(r)
+
+	tags, err := h.tagService.GetAll(ctxUser.ID)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"tags": tags})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TagHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	tagID, err := readIntParam(r, "tagID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	tag, err := h.tagService.GetByID(ctxUser.ID, int32(tagID))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"tag": tag})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TagHandler) UpdateByID(w http.ResponseWriter, r *http.Request) {
+	tagID, err := readIntParam(r, "tagID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var input service.UpdateTagParams
+	err = response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	tag, err := h.tagService.UpdateByID(ctxUser.ID, int32(tagID), &input)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, service.ErrDuplicateTagName):
+			response.BadRequestResponse(w, r, fmt.Errorf("A tag with this name already exists"))
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, database.ErrEditConflict):
+			response.ConflictResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"tag": tag})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TagHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
+	tagID, err := readIntParam(r, "tagID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.tagService.DeleteByID(ctxUser.ID, int32(tagID))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "tag successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TagHandler) Report(w http.ResponseWriter, r *http.Request) {
+	from, err := readDateQuery(r, "date_from", time.Time{})
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	to, err := readDateQuery(r, "date_to", time.Time{})
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	report, err := h.tagService.Report(ctxUser.ID, from, to)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"report": report})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestTagHandler(t *testing.T) (*TagHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewTagHandler(svc.Tag)
+
+	return handler, svc, cleanup
+}
+
+func TestTagHandler_Create(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTagHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	user2 := testutils.CreateTestUser(t, svc.User, "testuser2")
+
+	route := "/v1/tags"
+
+	tests := []struct {
+		name           string
+		user           *store.User
+		requestBody    any
+		expectedStatus int
+		checkResponse  func(*testing.T, *http.Response)
+	}{
+		{
+			name:        "Create tag",
+			user:        user,
+			requestBody: map[string]any{"name": " vacation-2026 "},
+			checkResponse: func(t *testing.T, rs *http.Response) {
+				var resBody map[string]*store.Tag
+				json.NewDecoder(rs.Body).Decode(&resBody)
+
+				tag := resBody["tag"]
+				assert.Equal(t, tag.Name, "vacation-2026")
+				assert.Equal(t, tag.UserID, user.ID)
+
+				location := rs.Header.Get("Location")
+				assert.Equal(t, location, fmt.Sprintf("%s/%d", route, tag.ID))
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Duplicate name",
+			user:           user,
+			requestBody:    map[string]any{"name": "vacation-2026"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Same name for another user",
+			user:           user2,
+			requestBody:    map[string]any{"name": "vacation-2026"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Empty name",
+			user:           user,
+			requestBody:    map[string]any{"name": " "},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Incorrect JSON",
+			user:           user,
+			requestBody:    "",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreatePostRequest(t, route, tt.requestBody, tt.user)
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, req)
+
+			rs := rr.Result()
+			defer rs.Body.Close()
+
+			assert.Equal(t, rs.StatusCode, tt.expectedStatus)
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, rs)
+			}
+		})
+	}
+}
+
+func TestTagHandler_UpdateByID(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTagHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	tag, err := svc.Tag.Create(user.ID, &service.CreateTagParams{Name: "vacation"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.Tag.Create(user.ID, &service.CreateTagParams{Name: "reimbursable"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2 := testutils.CreateTestUser(t, svc.User, "testuser2")
+	otherTag, err := svc.Tag.Create(user2.ID, &service.CreateTagParams{Name: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		id             int32
+		requestBody    any
+		expectedStatus int
+		expectedName   string
+	}{
+		{
+			name:           "Rename tag",
+			id:             tag.ID,
+			requestBody:    map[string]any{"name": "vacation-2026"},
+			expectedStatus: http.StatusOK,
+			expectedName:   "vacation-2026",
+		},
+		{
+			name:           "Duplicate name",
+			id:             tag.ID,
+			requestBody:    map[string]any{"name": "reimbursable"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Too long",
+			id:             tag.ID,
+			requestBody:    map[string]any{"name": fmt.Sprintf("%051d", 0)},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Other user's tag",
+			id:             otherTag.ID,
+			requestBody:    map[string]any{"name": "mine"},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreatePostRequest(t, "/v1/tags", tt.requestBody, user)
+			req.SetPathValue("tagID", strconv.Itoa(int(tt.id)))
+
+			rr := httptest.NewRecorder()
+			handler.UpdateByID(rr, req)
+
+			rs := rr.Result()
+			defer rs.Body.Close()
+
+			assert.Equal(t, rs.StatusCode, tt.expectedStatus)
+
+			if tt.expectedStatus == http.StatusOK {
+				var resBody map[string]*store.Tag
+				json.NewDecoder(rs.Body).Decode(&resBody)
+
+				assert.Equal(t, resBody["tag"].Name, tt.expectedName)
+			}
+		})
+	}
+}
+
+func TestTagHandler_DeleteByID(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTagHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	transaction, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		Title:       "Hotel",
+		AccountID:   account.ID,
+		AmountCents: -20000,
+		CategoryID:  category.ID,
+		Tags:        []string{"vacation"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagID := strconv.Itoa(int(transaction.Tags[0].ID))
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+	}{
+		{"Delete tag", tagID, http.StatusOK},
+		{"Already deleted", tagID, http.StatusNotFound},
+		{"Invalid ID", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreatePostRequest(t, "/v1/tags", nil, user)
+			req.SetPathValue("tagID", tt.id)
+
+			rr := httptest.NewRecorder()
+			handler.DeleteByID(rr, req)
+
+			rs := rr.Result()
+			defer rs.Body.Close()
+
+			assert.Equal(t, rs.StatusCode, tt.expectedStatus)
+		})
+	}
+
+	updated, err := svc.Transaction.GetByID(transaction.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(updated.Tags), 0)
+}
+
+func TestTagHandler_Report(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTagHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	transactions := []service.CreateTransactionParams{
+		{Title: "Hotel", AmountCents: -20000, Tags: []string{"vacation", "reimbursable"}},
+		{Title: "Flight", AmountCents: -50000, Tags: []string{"vacation"}},
+		{Title: "Reimbursement", AmountCents: 20000, Tags: []string{"reimbursable"}},
+		{Title: "Groceries", AmountCents: -3000},
+	}
+	for _, transaction := range transactions {
+		transaction.AccountID = account.ID
+		transaction.CategoryID = category.ID
+		_, err := svc.Transaction.Create(user.ID, &transaction)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tags, err := svc.Tag.GetAll(user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(tags), 2)
+	reimbursable, vacation := tags[0], tags[1]
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       []store.GetTagReportRow
+	}{
+		{
+			name:           "Totals per tag",
+			expectedStatus: http.StatusOK,
+			expected: []store.GetTagReportRow{
+				{TagID: reimbursable.ID, Name: "reimbursable", Count: 2, IncomeCents: 20000, ExpenseCents: -20000, TotalCents: 0},
+				{TagID: vacation.ID, Name: "vacation", Count: 2, IncomeCents: 0, ExpenseCents: -70000, TotalCents: -70000},
+			},
+		},
+		{
+			name:           "Date range",
+			query:          "?date_from=2020-01-01&date_to=2020-12-31",
+			expectedStatus: http.StatusOK,
+			expected:       []store.GetTagReportRow{},
+		},
+		{
+			name:           "date_to before date_from",
+			query:          "?date_from=2020-12-31&date_to=2020-01-01",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateGetRequest(t, "/v1/tags/report"+tt.query, user)
+
+			rr := httptest.NewRecorder()
+			handler.Report(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resBody map[string][]store.GetTagReportRow
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			report := resBody["report"]
+			assert.Equal(t, len(report), len(tt.expected))
+			for i, row := range report {
+				assert.Equal(t, row, tt.expected[i])
+			}
+		})
+	}
+}
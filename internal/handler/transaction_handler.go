@@ -4,10 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/Quak1/gokei/internal/appcontext"
 	"github.com/Quak1/gokei/internal/database"
-	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/internal/service"
 	"github.com/Quak1/gokei/pkg/response"
 	"github.com/Quak1/gokei/pkg/validator"
@@ -24,7 +24,7 @@ func NewTransactionHandler(svc *service.TransactionService) *TransactionHandler
 }
 
 func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var input store.CreateTransactionParams
+	var input service.CreateTransactionParams
 
 	err := response.ReadJSON(w, r, &input)
 	if err != nil {
@@ -63,12 +63,105 @@ func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func readListTransactionsParams(r *http.Request) (*service.ListTransactionsParams, error) {
+	var params service.ListTransactionsParams
+	var err error
+
+	if params.AccountIDs, err = readIDListQuery(r, "account_id"); err != nil {
+		return nil, err
+	}
+	if params.CategoryIDs, err = readIDListQuery(r, "category_id"); err != nil {
+		return nil, err
+	}
+	if params.TagIDs, err = readIDListQuery(r, "tag_id"); err != nil {
+		return nil, err
+	}
+	if params.DateFrom, err = readDateQuery(r, "date_from", time.Time{}); err != nil {
+		return nil, err
+	}
+	if params.DateTo, err = readDateQuery(r, "date_to", time.Time{}); err != nil {
+		return nil, err
+	}
+	if params.MinAmountCents, err = readOptionalIntQuery(r, "min_amount_cents"); err != nil {
+		return nil, err
+	}
+	if params.MaxAmountCents, err = readOptionalIntQuery(r, "max_amount_cents"); err != nil {
+		return nil, err
+	}
+	if params.PageSize, err = readIntQuery(r, "page_size", service.DefaultPageSize); err != nil {
+		return nil, err
+	}
+
+	params.Sign = readStringQuery(r, "sign", "")
+	params.Status = readStringQuery(r, "status", "")
+	params.Sort = readStringQuery(r, "sort", "date")
+	params.Order = readStringQuery(r, "order", "desc")
+	params.Cursor = readStringQuery(r, "cursor", "")
+
+	return &params, nil
+}
+
 func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	params, err := readListTransactionsParams(r)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
 	ctxUser := appcontext.GetContextUser(r)
 
-	transactions, err := h.transactionService.GetAll(ctxUser.ID)
+	transactions, pagination, err := h.transactionService.List(ctxUser.ID, params)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"transactions": transactions, "pagination": pagination})
 	if err != nil {
 		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransactionHandler) Search(w http.ResponseWriter, r *http.Request) {
+	var params service.SearchTransactionsParams
+	var err error
+
+	params.Query = readStringQuery(r, "q", "")
+
+	if params.AccountIDs, err = readIDListQuery(r, "account_id"); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	if params.DateFrom, err = readDateQuery(r, "date_from", time.Time{}); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	if params.DateTo, err = readDateQuery(r, "date_to", time.Time{}); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	if params.Limit, err = readIntQuery(r, "limit", service.DefaultPageSize); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transactions, err := h.transactionService.Search(ctxUser.ID, &params)
+	if err != nil {
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
 		return
 	}
 
@@ -85,11 +178,20 @@ func (h *TransactionHandler) GetAccountTransactions(w http.ResponseWriter, r *ht
 		return
 	}
 
+	params, err := readListTransactionsParams(r)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
 	ctxUser := appcontext.GetContextUser(r)
 
-	transactions, err := h.transactionService.GetAllTRansactionsForAccountID(int32(accountID), ctxUser.ID)
+	transactions, pagination, err := h.transactionService.ListForAccount(ctxUser.ID, int32(accountID), params)
 	if err != nil {
+		var validationErr *validator.ValidationError
 		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
 		case errors.Is(err, database.ErrRecordNotFound):
 			response.NotFoundResponse(w, r)
 		default:
@@ -98,7 +200,7 @@ func (h *TransactionHandler) GetAccountTransactions(w http.ResponseWriter, r *ht
 		return
 	}
 
-	err = response.OK(w, response.Envelope{"transactions": transactions})
+	err = response.OK(w, response.Envelope{"transactions": transactions, "pagination": pagination})
 	if err != nil {
 		response.ServerErrorResponse(w, r, err)
 	}
@@ -144,7 +246,8 @@ func (h *TransactionHandler) DeleteByID(w http.ResponseWriter, r *http.Request)
 		switch {
 		case errors.Is(err, database.ErrRecordNotFound):
 			response.NotFoundResponse(w, r)
-		case errors.Is(err, service.ErrDeleteInitialTransaction):
+		case errors.Is(err, service.ErrDeleteInitialTransaction), errors.Is(err, service.ErrTransferTransaction),
+			errors.Is(err, service.ErrReconciledTransaction):
 			response.ForbiddenResponse(w, r, err)
 		default:
 			response.ServerErrorResponse(w, r, err)
@@ -186,7 +289,8 @@ func (h *TransactionHandler) UpdateByID(w http.ResponseWriter, r *http.Request)
 			response.ConflictResponse(w, r)
 		case errors.Is(err, database.ErrInvalidAccount), errors.Is(err, database.ErrInvalidCategory):
 			response.BadRequestResponse(w, r, err)
-		case errors.Is(err, service.ErrTransactionWithInitialCategory):
+		case errors.Is(err, service.ErrTransactionWithInitialCategory), errors.Is(err, service.ErrTransferTransaction),
+			errors.Is(err, service.ErrReconciledTransaction):
 			response.ForbiddenResponse(w, r, err)
 		default:
 			response.ServerErrorResponse(w, r, err)
@@ -218,10 +322,13 @@ func (h *TransactionHandler) RefundByID(w http.ResponseWriter, r *http.Request)
 
 	transaction, err := h.transactionService.RefundByID(int32(id), ctxUser.ID, &input)
 	if err != nil {
+		var validationErr *validator.ValidationError
 		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
 		case errors.Is(err, database.ErrRecordNotFound):
 			response.NotFoundResponse(w, r)
-		case errors.Is(err, service.ErrRefundInitialTransaction):
+		case errors.Is(err, service.ErrRefundInitialTransaction), errors.Is(err, service.ErrRefundRefund):
 			response.ForbiddenResponse(w, r, err)
 		default:
 			response.ServerErrorResponse(w, r, err)
@@ -234,3 +341,72 @@ func (h *TransactionHandler) RefundByID(w http.ResponseWriter, r *http.Request)
 		response.ServerErrorResponse(w, r, err)
 	}
 }
+
+func (h *TransactionHandler) UnlockByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "transactionID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transaction, err := h.transactionService.UnlockByID(int32(id), ctxUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.NotFoundResponse(w, r)
+		case errors.Is(err, database.ErrEditConflict):
+			response.ConflictResponse(w, r)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"transaction": transaction})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransactionHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Operations []service.BulkOperation `json:"operations"`
+	}
+	err := response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	results, err := h.transactionService.Bulk(ctxUser.ID, input.Operations)
+	if err != nil {
+		// Errors name the failing operation, so they are sent back as they are
+		// instead of the generic messages.
+		var validationErr *validator.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			response.FailedValidationResponse(w, r, validationErr)
+		case errors.Is(err, database.ErrRecordNotFound), errors.Is(err, database.ErrInvalidAccount):
+			response.ErrorResponse(w, r, http.StatusNotFound, err.Error())
+		case errors.Is(err, database.ErrEditConflict):
+			response.ErrorResponse(w, r, http.StatusConflict, err.Error())
+		case errors.Is(err, database.ErrInvalidCategory):
+			response.BadRequestResponse(w, r, err)
+		case errors.Is(err, service.ErrTransactionWithInitialCategory), errors.Is(err, service.ErrDeleteInitialTransaction),
+			errors.Is(err, service.ErrTransferTransaction), errors.Is(err, service.ErrReconciledTransaction):
+			response.ForbiddenResponse(w, r, err)
+		default:
+			response.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"results": results})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
@@ -3,12 +3,17 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/internal/service"
 	"github.com/Quak1/gokei/internal/testutils"
@@ -22,7 +27,7 @@ func setupTestTransactionHandler(t *testing.T) (*TransactionHandler, *service.Se
 		t.Fatal(err)
 	}
 
-	svc := service.New(db)
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
 	handler := NewTransactionHandler(svc.Transaction)
 
 	return handler, svc, cleanup
@@ -243,6 +248,390 @@ func TestTransactionHandler_GetAll(t *testing.T) {
 	}
 }
 
+func TestTransactionHandler_GetAllFilters(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	category2, err := svc.Category.Create(&store.CreateCategoryParams{
+		Name:   "Other Category",
+		Color:  "#000000",
+		Icon:   "O",
+		UserID: user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(amountCents int64, categoryID int32, date time.Time) int32 {
+		transaction, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+			Title:       "Test Transaction",
+			AccountID:   account.ID,
+			AmountCents: amountCents,
+			CategoryID:  categoryID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = svc.Transaction.UpdateByID(transaction.ID, user.ID, &service.UpdateTransactionParams{Date: &date})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return transaction.ID
+	}
+
+	january := create(-500, category.ID, time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC))
+	february := create(-2000, category2.ID, time.Date(2025, time.February, 10, 12, 0, 0, 0, time.UTC))
+	march := create(20000, category.ID, time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []int32
+	}{
+		{
+			name:           "Default order is newest first",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{march, february, january},
+		},
+		{
+			name:           "Date range includes date_to",
+			query:          "date_from=2025-01-01&date_to=2025-02-10&order=asc",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{january, february},
+		},
+		{
+			name:           "Category filter",
+			query:          fmt.Sprintf("category_id=%d", category.ID),
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{march, january},
+		},
+		{
+			name:           "Expenses only",
+			query:          "sign=expense",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{february, january},
+		},
+		{
+			name:           "Income only",
+			query:          "sign=income",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{march},
+		},
+		{
+			name:           "Amount range",
+			query:          "min_amount_cents=-1000&max_amount_cents=0",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{january},
+		},
+		{
+			name:           "Sort by amount",
+			query:          "sort=amount&order=asc",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{february, january, march},
+		},
+		{
+			name:           "Other account",
+			query:          "account_id=999",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{},
+		},
+		{
+			name:           "Invalid sort",
+			query:          "sort=title",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid sign",
+			query:          "sign=positive",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "date_to before date_from",
+			query:          "date_from=2025-02-01&date_to=2025-01-01",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Page size too big",
+			query:          "page_size=1000",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid cursor",
+			query:          "cursor=test",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid category list",
+			query:          "category_id=1,a",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if tt.expectedStatus == http.StatusOK && !strings.Contains(query, "date_to") {
+				// Leave out the initial transaction, which is dated now.
+				query += "&date_to=2025-12-31"
+			}
+			req := testutils.CreateGetRequest(t, "/v1/transactions?"+query, user)
+
+			rr := httptest.NewRecorder()
+			handler.GetAll(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resBody struct {
+				Transactions []store.Transaction `json:"transactions"`
+				Pagination   service.Pagination  `json:"pagination"`
+			}
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			assert.Equal(t, len(resBody.Transactions), len(tt.expectedIDs))
+			for i, transaction := range resBody.Transactions {
+				assert.Equal(t, transaction.ID, tt.expectedIDs[i])
+			}
+			assert.Equal(t, resBody.Pagination.HasMore, false)
+		})
+	}
+}
+
+func TestTransactionHandler_GetAllPagination(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	// Same amount and date for all, so pages are split by ID alone.
+	for range 4 {
+		testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+	}
+
+	for _, sort := range []string{"date", "amount"} {
+		t.Run(sort, func(t *testing.T) {
+			seen := map[int32]bool{}
+			cursor := ""
+			pages := 0
+
+			for {
+				route := fmt.Sprintf("/v1/accounts/%d/transactions?page_size=2&sort=%s&cursor=%s", account.ID, sort, cursor)
+				req := testutils.CreateGetRequest(t, route, user)
+				req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+				rr := httptest.NewRecorder()
+				handler.GetAccountTransactions(rr, req)
+
+				res := rr.Result()
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, http.StatusOK)
+
+				var resBody struct {
+					Transactions []store.Transaction `json:"transactions"`
+					Pagination   service.Pagination  `json:"pagination"`
+				}
+				json.NewDecoder(res.Body).Decode(&resBody)
+
+				pages++
+				assert.Equal(t, resBody.Pagination.PageSize, 2)
+				for _, transaction := range resBody.Transactions {
+					assert.Equal(t, seen[transaction.ID], false)
+					seen[transaction.ID] = true
+				}
+
+				if !resBody.Pagination.HasMore {
+					assert.Equal(t, resBody.Pagination.NextCursor, "")
+					break
+				}
+				cursor = resBody.Pagination.NextCursor
+			}
+
+			// Four transactions plus the initial balance one.
+			assert.Equal(t, len(seen), 5)
+			assert.Equal(t, pages, 3)
+		})
+	}
+
+	t.Run("Cursor from another sort", func(t *testing.T) {
+		list := func(t *testing.T, query string) *http.Response {
+			t.Helper()
+
+			req := testutils.CreateGetRequest(t, fmt.Sprintf("/v1/accounts/%d/transactions?page_size=2&%s", account.ID, query), user)
+			req.SetPathValue("accountID", strconv.Itoa(int(account.ID)))
+
+			rr := httptest.NewRecorder()
+			handler.GetAccountTransactions(rr, req)
+
+			return rr.Result()
+		}
+
+		res := list(t, "sort=date&order=asc")
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var resBody struct {
+			Pagination service.Pagination `json:"pagination"`
+		}
+		json.NewDecoder(res.Body).Decode(&resBody)
+		cursor := resBody.Pagination.NextCursor
+
+		for _, query := range []string{"sort=amount&order=asc", "sort=date&order=desc"} {
+			res := list(t, query+"&cursor="+cursor)
+			defer res.Body.Close()
+			assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+		}
+	})
+}
+
+func TestTransactionHandler_Search(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	create := func(userID, accountID int32, title, note string) int32 {
+		transaction, err := svc.Transaction.Create(userID, &service.CreateTransactionParams{
+			Title:       title,
+			Note:        note,
+			AccountID:   accountID,
+			AmountCents: -1000,
+			CategoryID:  category.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return transaction.ID
+	}
+
+	order := create(user.ID, account.ID, "Amazon order", "Headphones")
+	groceries := create(user.ID, account.ID, "Groceries", "Paid with Amazon gift card")
+	create(user.ID, account.ID, "Rent", "")
+
+	user2 := testutils.CreateTestUser(t, svc.User, "user2")
+	account2 := testutils.CreateTestAccount(t, svc.Account, user2.ID)
+	create(user2.ID, account2.ID, "Amazon order", "")
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []int32
+	}{
+		{
+			name:           "Title matches rank first",
+			query:          "q=amazon",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{order, groceries},
+		},
+		{
+			name:           "Prefix match",
+			query:          "q=AMAZ",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{order, groceries},
+		},
+		{
+			name:           "Search notes",
+			query:          "q=headphone",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{order},
+		},
+		{
+			name:           "All words must match",
+			query:          "q=amazon+orders",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{order},
+		},
+		{
+			name:           "Date range",
+			query:          "q=amazon&date_to=2020-01-01",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{},
+		},
+		{
+			name:           "Limit",
+			query:          "q=amazon&limit=1",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int32{order},
+		},
+		{
+			name:           "Missing query",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Query without words",
+			query:          "q=%21%26%7C",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid limit",
+			query:          "q=amazon&limit=0",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid date",
+			query:          "q=amazon&date_from=march",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreateGetRequest(t, "/v1/transactions/search?"+tt.query, user)
+
+			rr := httptest.NewRecorder()
+			handler.Search(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resBody map[string][]store.Transaction
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			transactions := resBody["transactions"]
+			assert.Equal(t, len(transactions), len(tt.expectedIDs))
+			for i, transaction := range transactions {
+				assert.Equal(t, transaction.ID, tt.expectedIDs[i])
+			}
+		})
+	}
+}
+
 func TestTransactionHandler_GetAccountTransactions(t *testing.T) {
 	t.Parallel()
 	if testing.Short() {
@@ -863,3 +1252,926 @@ func TestTransactionHandler_RefundByID(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionHandler_PartialRefunds(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	groceries := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	household, err := svc.Category.Create(&store.CreateCategoryParams{
+		Name:   "Household",
+		Color:  "#000000",
+		Icon:   "H",
+		UserID: user.ID,
+	})
+	assert.NilError(t, err)
+
+	original, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		AccountID:   account.ID,
+		AmountCents: -10000,
+		Title:       "Supermarket",
+		Splits: []service.TransactionSplitParams{
+			{CategoryID: groceries.ID, AmountCents: -7500},
+			{CategoryID: household.ID, AmountCents: -2500},
+		},
+	})
+	assert.NilError(t, err)
+
+	refund := func(t *testing.T, body map[string]any, id int32) *http.Response {
+		t.Helper()
+
+		req := testutils.CreatePostRequest(t, "/v1/transactions", body, user)
+		req.SetPathValue("transactionID", strconv.Itoa(int(id)))
+
+		rr := httptest.NewRecorder()
+		handler.RefundByID(rr, req)
+
+		return rr.Result()
+	}
+
+	decode := func(t *testing.T, res *http.Response) *service.TransactionDetails {
+		t.Helper()
+
+		var resBody map[string]*service.TransactionDetails
+		assert.NilError(t, json.NewDecoder(res.Body).Decode(&resBody))
+		return resBody["transaction"]
+	}
+
+	var firstRefund *service.TransactionDetails
+
+	t.Run("Partial refund", func(t *testing.T) {
+		res := refund(t, map[string]any{"amount_cents": 4000, "reason": "Damaged goods"}, original.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		firstRefund = decode(t, res)
+		assert.Equal(t, firstRefund.AmountCents, 4000)
+		assert.Equal(t, firstRefund.RefundOf.TransactionID, original.ID)
+		assert.Equal(t, firstRefund.RefundOf.Reason, "Damaged goods")
+		assert.Equal(t, len(firstRefund.Splits), 2)
+		assert.Equal(t, firstRefund.Splits[0].AmountCents, 3000)
+		assert.Equal(t, firstRefund.Splits[1].AmountCents, 1000)
+
+		transaction, err := svc.Transaction.GetByID(original.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Refund.Status, service.RefundStatusPartial)
+		assert.Equal(t, transaction.Refund.RefundedCents, 4000)
+		assert.Equal(t, transaction.Refund.RefundableCents, 6000)
+		assert.Equal(t, len(transaction.Refund.Refunds), 1)
+		assert.Equal(t, transaction.Refund.Refunds[0].RefundTransactionID, firstRefund.ID)
+	})
+
+	t.Run("Refund shows what it refunds", func(t *testing.T) {
+		transaction, err := svc.Transaction.GetByID(firstRefund.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.RefundOf.TransactionID, original.ID)
+		assert.Equal(t, transaction.Refund == nil, true)
+	})
+
+	t.Run("Fail to refund a refund", func(t *testing.T) {
+		res := refund(t, map[string]any{}, firstRefund.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("Invalid amounts", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body map[string]any
+		}{
+			{name: "More than is left", body: map[string]any{"amount_cents": 6001}},
+			{name: "Zero", body: map[string]any{"amount_cents": 0}},
+			{name: "Negative", body: map[string]any{"amount_cents": -100}},
+			{name: "Reason too long", body: map[string]any{"reason": strings.Repeat("a", 501)}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res := refund(t, tt.body, original.ID)
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+			})
+		}
+	})
+
+	t.Run("Refund the rest", func(t *testing.T) {
+		res := refund(t, map[string]any{}, original.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		rest := decode(t, res)
+		assert.Equal(t, rest.AmountCents, 6000)
+		assert.Equal(t, rest.RefundOf.Reason, "")
+
+		transaction, err := svc.Transaction.GetByID(original.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Refund.Status, service.RefundStatusFull)
+		assert.Equal(t, transaction.Refund.RefundableCents, 0)
+		assert.Equal(t, len(transaction.Refund.Refunds), 2)
+
+		balance, err := svc.Account.GetSumBalance(account.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, balance, account.BalanceCents)
+	})
+
+	t.Run("Fail to refund more once fully refunded", func(t *testing.T) {
+		res := refund(t, map[string]any{}, original.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Amounts are checked against the refunds", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			transactionID  int32
+			amountCents    int64
+			expectedStatus int
+		}{
+			{name: "Refund more than is left", transactionID: firstRefund.ID, amountCents: 5000, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "Refund the same way", transactionID: firstRefund.ID, amountCents: -4000, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "Original below its refunds", transactionID: original.ID, amountCents: -9000, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "Original changing sign", transactionID: original.ID, amountCents: 10000, expectedStatus: http.StatusUnprocessableEntity},
+			{name: "Smaller refund", transactionID: firstRefund.ID, amountCents: 3000, expectedStatus: http.StatusOK},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				body := map[string]any{"amount_cents": tt.amountCents, "splits": []any{}}
+				req := testutils.CreatePostRequest(t, "/v1/transactions", body, user)
+				req.SetPathValue("transactionID", strconv.Itoa(int(tt.transactionID)))
+
+				rr := httptest.NewRecorder()
+				handler.UpdateByID(rr, req)
+
+				res := rr.Result()
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+				if res.StatusCode != http.StatusOK {
+					var resBody struct {
+						Error map[string]string `json:"error"`
+					}
+					json.NewDecoder(res.Body).Decode(&resBody)
+					_, ok := resBody.Error["amount_cents"]
+					assert.Equal(t, ok, true)
+				}
+			})
+		}
+	})
+
+	t.Run("Deleting a refund frees its amount", func(t *testing.T) {
+		err := svc.Transaction.DeleteByID(firstRefund.ID, user.ID)
+		assert.NilError(t, err)
+
+		transaction, err := svc.Transaction.GetByID(original.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Refund.Status, service.RefundStatusPartial)
+		assert.Equal(t, transaction.Refund.RefundableCents, 4000)
+	})
+}
+
+func TestTransactionHandler_Status(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	send := func(t *testing.T, h http.HandlerFunc, body any, transactionID int32) *http.Response {
+		t.Helper()
+
+		req := testutils.CreatePostRequest(t, "/v1/transactions", body, user)
+		if transactionID != 0 {
+			req.SetPathValue("transactionID", strconv.Itoa(int(transactionID)))
+		}
+
+		rr := httptest.NewRecorder()
+		h(rr, req)
+
+		return rr.Result()
+	}
+
+	decode := func(t *testing.T, res *http.Response) *service.TransactionDetails {
+		t.Helper()
+
+		var resBody map[string]*service.TransactionDetails
+		assert.NilError(t, json.NewDecoder(res.Body).Decode(&resBody))
+		return resBody["transaction"]
+	}
+
+	balances := func(t *testing.T) (int64, int64) {
+		t.Helper()
+
+		account, err := svc.Account.GetByID(account.ID, user.ID)
+		assert.NilError(t, err)
+		return account.BalanceCents, account.ClearedBalanceCents
+	}
+
+	res := send(t, handler.Create, map[string]any{
+		"title":        "Card payment",
+		"amount_cents": -2500,
+		"account_id":   account.ID,
+		"category_id":  category.ID,
+		"status":       "pending",
+	}, 0)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	pending := decode(t, res)
+	assert.Equal(t, pending.Status, store.TransactionStatusPending)
+
+	cleared := testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+	assert.Equal(t, cleared.Status, store.TransactionStatusCleared)
+
+	balance, clearedBalance := balances(t)
+	assert.Equal(t, balance, account.BalanceCents-2500+cleared.AmountCents)
+	assert.Equal(t, clearedBalance, account.BalanceCents+cleared.AmountCents)
+
+	t.Run("Invalid status", func(t *testing.T) {
+		res := send(t, handler.Create, map[string]any{
+			"title":        "Card payment",
+			"amount_cents": -100,
+			"account_id":   account.ID,
+			"category_id":  category.ID,
+			"status":       "bounced",
+		}, 0)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Filter by status", func(t *testing.T) {
+		tests := []struct {
+			query          string
+			expectedStatus int
+			expectedCount  int
+		}{
+			{query: "status=pending", expectedStatus: http.StatusOK, expectedCount: 1},
+			{query: "status=cleared", expectedStatus: http.StatusOK, expectedCount: 2},
+			{query: "status=reconciled", expectedStatus: http.StatusOK, expectedCount: 0},
+			{query: "status=bounced", expectedStatus: http.StatusUnprocessableEntity},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				req := testutils.CreateGetRequest(t, "/v1/transactions?"+tt.query, user)
+
+				rr := httptest.NewRecorder()
+				handler.GetAll(rr, req)
+
+				res := rr.Result()
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, tt.expectedStatus)
+				if res.StatusCode != http.StatusOK {
+					return
+				}
+
+				var resBody map[string][]*service.TransactionDetails
+				json.NewDecoder(res.Body).Decode(&resBody)
+				assert.Equal(t, len(resBody["transactions"]), tt.expectedCount)
+			})
+		}
+	})
+
+	t.Run("Reconciled only by reconciling", func(t *testing.T) {
+		res := send(t, handler.Create, map[string]any{
+			"title":        "Card payment",
+			"amount_cents": -100,
+			"account_id":   account.ID,
+			"category_id":  category.ID,
+			"status":       "reconciled",
+		}, 0)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+
+		res = send(t, handler.UpdateByID, map[string]any{"status": "reconciled"}, pending.ID)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Reconcile", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"status": "cleared"}, pending.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, decode(t, res).Status, store.TransactionStatusCleared)
+
+		_, err := svc.Reconciliation.Create(user.ID, account.ID, &service.CreateReconciliationParams{
+			StatementDate:         time.Now().UTC().Truncate(24 * time.Hour),
+			StatementBalanceCents: pending.AmountCents,
+			TransactionIDs:        []int32{pending.ID},
+		})
+		assert.NilError(t, err)
+
+		transaction, err := svc.Transaction.GetByID(pending.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, transaction.Status, store.TransactionStatusReconciled)
+
+		balance, clearedBalance := balances(t)
+		assert.Equal(t, clearedBalance, balance)
+	})
+
+	t.Run("Reconciled transactions are locked", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"amount_cents": -3000}, pending.ID)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+
+		res = send(t, handler.UpdateByID, map[string]any{"status": "cleared"}, pending.ID)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+
+		res = send(t, handler.DeleteByID, nil, pending.ID)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("Unlock other user's transaction", func(t *testing.T) {
+		req := testutils.CreatePostRequest(t, "/v1/transactions", nil, otherUser)
+		req.SetPathValue("transactionID", strconv.Itoa(int(pending.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.UnlockByID(rr, req)
+
+		assert.Equal(t, rr.Result().StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		res := send(t, handler.UnlockByID, nil, pending.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, decode(t, res).Status, store.TransactionStatusCleared)
+
+		res = send(t, handler.DeleteByID, nil, pending.ID)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		balance, clearedBalance := balances(t)
+		assert.Equal(t, balance, account.BalanceCents+cleared.AmountCents)
+		assert.Equal(t, clearedBalance, balance)
+	})
+}
+
+func TestTransactionHandler_Splits(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	groceries := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	household, err := svc.Category.Create(&store.CreateCategoryParams{
+		Name:   "Household",
+		Color:  "#000000",
+		Icon:   "H",
+		UserID: user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decode := func(t *testing.T, r *http.Response) *service.TransactionDetails {
+		var resBody map[string]*service.TransactionDetails
+		json.NewDecoder(r.Body).Decode(&resBody)
+		return resBody["transaction"]
+	}
+
+	send := func(t *testing.T, method func(http.ResponseWriter, *http.Request), body any, transactionID int32) *http.Response {
+		req := testutils.CreatePostRequest(t, "/v1/transactions", body, user)
+		req.SetPathValue("transactionID", strconv.Itoa(int(transactionID)))
+
+		rr := httptest.NewRecorder()
+		method(rr, req)
+
+		return rr.Result()
+	}
+
+	receipt := map[string]any{
+		"title":        "Supermarket",
+		"amount_cents": -5000,
+		"account_id":   account.ID,
+		"splits": []map[string]any{
+			{"category_id": groceries.ID, "amount_cents": -3000, "note": "Food"},
+			{"category_id": household.ID, "amount_cents": -2000},
+		},
+	}
+
+	res := send(t, handler.Create, receipt, 0)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	created := decode(t, res)
+	assert.Equal(t, created.CategoryID, groceries.ID)
+	assert.Equal(t, len(created.Splits), 2)
+	assert.Equal(t, created.Splits[0].AmountCents, -3000)
+	assert.Equal(t, created.Splits[0].Note, "Food")
+	assert.Equal(t, created.Splits[1].CategoryID, household.ID)
+
+	t.Run("Invalid splits", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			splits         []map[string]any
+			expectedStatus int
+		}{
+			{
+				name: "Lines don't add up",
+				splits: []map[string]any{
+					{"category_id": groceries.ID, "amount_cents": -3000},
+					{"category_id": household.ID, "amount_cents": -1000},
+				},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name: "Single line",
+				splits: []map[string]any{
+					{"category_id": groceries.ID, "amount_cents": -5000},
+				},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name: "Zero amount line",
+				splits: []map[string]any{
+					{"category_id": groceries.ID, "amount_cents": -5000},
+					{"category_id": household.ID, "amount_cents": 0},
+				},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name: "Initial category",
+				splits: []map[string]any{
+					{"category_id": groceries.ID, "amount_cents": -3000},
+					{"category_id": 1, "amount_cents": -2000},
+				},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name: "Category doesn't exist",
+				splits: []map[string]any{
+					{"category_id": groceries.ID, "amount_cents": -3000},
+					{"category_id": 999, "amount_cents": -2000},
+				},
+				expectedStatus: http.StatusBadRequest,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				body := map[string]any{
+					"title":        "Supermarket",
+					"amount_cents": -5000,
+					"account_id":   account.ID,
+					"category_id":  groceries.ID,
+					"splits":       tt.splits,
+				}
+
+				res := send(t, handler.Create, body, 0)
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, tt.expectedStatus)
+			})
+		}
+	})
+
+	t.Run("Amount change must keep lines consistent", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"amount_cents": -6000}, created.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Replace lines with amount", func(t *testing.T) {
+		body := map[string]any{
+			"amount_cents": -6000,
+			"splits": []map[string]any{
+				{"category_id": groceries.ID, "amount_cents": -1000},
+				{"category_id": household.ID, "amount_cents": -5000},
+			},
+		}
+
+		res := send(t, handler.UpdateByID, body, created.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		updated := decode(t, res)
+		assert.Equal(t, updated.AmountCents, -6000)
+		assert.Equal(t, len(updated.Splits), 2)
+		assert.Equal(t, updated.Splits[1].AmountCents, -5000)
+	})
+
+	t.Run("Refund mirrors lines", func(t *testing.T) {
+		res := send(t, handler.RefundByID, map[string]any{}, created.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		refund := decode(t, res)
+		assert.Equal(t, refund.AmountCents, 6000)
+		assert.Equal(t, len(refund.Splits), 2)
+		assert.Equal(t, refund.Splits[0].CategoryID, groceries.ID)
+		assert.Equal(t, refund.Splits[0].AmountCents, 1000)
+		assert.Equal(t, refund.Splits[1].AmountCents, 5000)
+	})
+
+	t.Run("Clear lines", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"splits": []any{}}, created.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		updated := decode(t, res)
+		assert.Equal(t, len(updated.Splits), 0)
+
+		transaction, err := svc.Transaction.GetByID(created.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, len(transaction.Splits), 0)
+	})
+
+	balance, err := svc.Account.GetSumBalance(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, balance, account.BalanceCents)
+}
+
+func TestTransactionHandler_Tags(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	decode := func(t *testing.T, r *http.Response) *service.TransactionDetails {
+		var resBody map[string]*service.TransactionDetails
+		json.NewDecoder(r.Body).Decode(&resBody)
+		return resBody["transaction"]
+	}
+
+	send := func(t *testing.T, method func(http.ResponseWriter, *http.Request), body any, transactionID int32) *http.Response {
+		req := testutils.CreatePostRequest(t, "/v1/transactions", body, user)
+		req.SetPathValue("transactionID", strconv.Itoa(int(transactionID)))
+
+		rr := httptest.NewRecorder()
+		method(rr, req)
+
+		return rr.Result()
+	}
+
+	res := send(t, handler.Create, map[string]any{
+		"title":        "Hotel",
+		"amount_cents": -20000,
+		"account_id":   account.ID,
+		"category_id":  category.ID,
+		"tags":         []string{"vacation-2026", " reimbursable ", "vacation-2026"},
+	}, 0)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	hotel := decode(t, res)
+	assert.Equal(t, len(hotel.Tags), 2)
+	assert.Equal(t, hotel.Tags[0].Name, "vacation-2026")
+	assert.Equal(t, hotel.Tags[1].Name, "reimbursable")
+
+	flight, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		Title:       "Flight",
+		AccountID:   account.ID,
+		AmountCents: -50000,
+		CategoryID:  category.ID,
+		Tags:        []string{"vacation-2026"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, flight.Tags[0].ID, hotel.Tags[0].ID)
+
+	t.Run("Invalid tags", func(t *testing.T) {
+		res := send(t, handler.Create, map[string]any{
+			"title":        "Hotel",
+			"amount_cents": -20000,
+			"account_id":   account.ID,
+			"category_id":  category.ID,
+			"tags":         []string{"  "},
+		}, 0)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Filter by tag", func(t *testing.T) {
+		query := fmt.Sprintf("/v1/transactions?tag_id=%d", hotel.Tags[1].ID)
+		req := testutils.CreateGetRequest(t, query, user)
+
+		rr := httptest.NewRecorder()
+		handler.GetAll(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var resBody struct {
+			Transactions []*service.TransactionDetails `json:"transactions"`
+		}
+		json.NewDecoder(res.Body).Decode(&resBody)
+
+		assert.Equal(t, len(resBody.Transactions), 1)
+		assert.Equal(t, resBody.Transactions[0].ID, hotel.ID)
+		assert.Equal(t, len(resBody.Transactions[0].Tags), 2)
+	})
+
+	t.Run("Refund keeps tags", func(t *testing.T) {
+		res := send(t, handler.RefundByID, map[string]any{}, hotel.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		refund := decode(t, res)
+		assert.Equal(t, len(refund.Tags), 2)
+	})
+
+	t.Run("Replace tags", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"tags": []string{"tax-deductible"}}, hotel.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		updated := decode(t, res)
+		assert.Equal(t, len(updated.Tags), 1)
+		assert.Equal(t, updated.Tags[0].Name, "tax-deductible")
+	})
+
+	t.Run("Keep tags on update", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"title": "Hotel room"}, hotel.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		updated := decode(t, res)
+		assert.Equal(t, len(updated.Tags), 1)
+	})
+
+	t.Run("Clear tags", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"tags": []string{}}, hotel.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		updated := decode(t, res)
+		assert.Equal(t, len(updated.Tags), 0)
+	})
+}
+
+func TestTransactionHandler_Bulk(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	account2 := testutils.CreateTestAccount(t, svc.Account, user.ID, "Second Account")
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	toUpdate := testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+	toDelete := testutils.CreateTestTransaction(t, svc.Transaction, user.ID, account.ID, category.ID)
+
+	send := func(t *testing.T, operations any) *http.Response {
+		req := testutils.CreatePostRequest(t, "/v1/transactions/bulk", map[string]any{"operations": operations}, user)
+
+		rr := httptest.NewRecorder()
+		handler.Bulk(rr, req)
+
+		return rr.Result()
+	}
+
+	balance := func(t *testing.T, accountID int32) int64 {
+		account, err := svc.Account.GetByID(accountID, user.ID)
+		assert.NilError(t, err)
+		return account.BalanceCents
+	}
+
+	before, before2 := balance(t, account.ID), balance(t, account2.ID)
+
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 3)
+
+	var initialID int32
+	for _, transaction := range transactions {
+		if transaction.CategoryID == database.InitialCategoryID() {
+			initialID = transaction.ID
+		}
+	}
+
+	created := map[string]any{
+		"op": "create",
+		"create": map[string]any{
+			"title":        "Coffee",
+			"amount_cents": -500,
+			"account_id":   account.ID,
+			"category_id":  category.ID,
+		},
+	}
+
+	failures := []struct {
+		name           string
+		operations     any
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "No operations",
+			operations:     []any{},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid op",
+			operations:     []any{created, map[string]any{"op": "move", "id": toUpdate.ID}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "operations[1].op",
+		},
+		{
+			name: "Invalid transaction",
+			operations: []any{created, map[string]any{
+				"op":     "update",
+				"id":     toUpdate.ID,
+				"update": map[string]any{"title": ""},
+			}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "operations[1].title",
+		},
+		{
+			name:           "Missing transaction rolls back",
+			operations:     []any{created, map[string]any{"op": "delete", "id": 99999}},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "operation 1",
+		},
+		{
+			name:           "Initial transaction",
+			operations:     []any{created, map[string]any{"op": "delete", "id": initialID}},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			res := send(t, tt.operations)
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, tt.expectedStatus)
+
+			body, err := io.ReadAll(res.Body)
+			assert.NilError(t, err)
+			assert.StringContains(t, string(body), tt.expectedError)
+
+			assert.Equal(t, balance(t, account.ID), before)
+		})
+	}
+
+	transactions, err = svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), 3)
+
+	res := send(t, []any{
+		created,
+		map[string]any{
+			"op": "update",
+			"id": toUpdate.ID,
+			"update": map[string]any{
+				"account_id":   account2.ID,
+				"amount_cents": -1000,
+			},
+		},
+		map[string]any{"op": "delete", "id": toDelete.ID},
+	})
+	defer res.Body.Close()
+
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+
+	var resBody map[string][]service.BulkResult
+	json.NewDecoder(res.Body).Decode(&resBody)
+
+	results := resBody["results"]
+	assert.Equal(t, len(results), 3)
+	assert.Equal(t, results[0].Op, "create")
+	assert.Equal(t, results[0].ID, results[0].Transaction.ID)
+	assert.Equal(t, results[0].Transaction.Title, "Coffee")
+	assert.Equal(t, results[1].Transaction.AccountID, account2.ID)
+	assert.Equal(t, results[2].ID, toDelete.ID)
+	assert.Equal(t, results[2].Transaction == nil, true)
+
+	assert.Equal(t, balance(t, account.ID), before-500-toUpdate.AmountCents-toDelete.AmountCents)
+	assert.Equal(t, balance(t, account2.ID), before2-1000)
+}
+
+func TestTransactionHandler_Duplicates(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransactionHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	original, err := svc.Transaction.Create(user.ID, &service.CreateTransactionParams{
+		Title:       "Corner Coffee",
+		AccountID:   account.ID,
+		AmountCents: -450,
+		CategoryID:  category.ID,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(original.DuplicateOf), 0)
+
+	tests := []struct {
+		name              string
+		title             string
+		amountCents       int64
+		expectedDuplicate bool
+	}{
+		{
+			name:              "Same title",
+			title:             "corner coffee",
+			amountCents:       -450,
+			expectedDuplicate: true,
+		},
+		{
+			name:              "Longer title",
+			title:             "CORNER COFFEE #1234 BERLIN",
+			amountCents:       -450,
+			expectedDuplicate: true,
+		},
+		{
+			name:        "Different amount",
+			title:       "Corner Coffee",
+			amountCents: -500,
+		},
+		{
+			name:        "Different title",
+			title:       "Bakery",
+			amountCents: -450,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutils.CreatePostRequest(t, "/v1/transactions", map[string]any{
+				"title":        tt.title,
+				"amount_cents": tt.amountCents,
+				"account_id":   account.ID,
+				"category_id":  category.ID,
+			}, user)
+
+			rr := httptest.NewRecorder()
+			handler.Create(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+			var resBody map[string]*service.TransactionDetails
+			json.NewDecoder(res.Body).Decode(&resBody)
+
+			duplicateOf := resBody["transaction"].DuplicateOf
+			if tt.expectedDuplicate {
+				assert.Equal(t, len(duplicateOf) > 0, true)
+				assert.Equal(t, slices.Contains(duplicateOf, original.ID), true)
+			} else {
+				assert.Equal(t, len(duplicateOf), 0)
+			}
+		})
+	}
+}
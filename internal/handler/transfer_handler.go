@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/pkg/response"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+type TransferHandler struct {
+	transferService *service.TransferService
+}
+
+func NewTransferHandler(svc *service.TransferService) *TransferHandler {
+	return &TransferHandler{
+		transferService: svc,
+	}
+}
+
+func (h *TransferHandler) transferError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *validator.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response.FailedValidationResponse(w, r, validationErr)
+	case errors.Is(err, database.ErrRecordNotFound):
+		response.NotFoundResponse(w, r)
+	case errors.Is(err, database.ErrEditConflict):
+		response.ConflictResponse(w, r)
+	case errors.Is(err, database.ErrInvalidAccount), errors.Is(err, database.ErrInvalidCategory):
+		response.BadRequestResponse(w, r, err)
+	case errors.Is(err, service.ErrReconciledTransaction):
+		response.ForbiddenResponse(w, r, err)
+	default:
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var input service.CreateTransferParams
+	err := response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transfer, err := h.transferService.Create(ctxUser.ID, &input)
+	if err != nil {
+		h.transferError(w, r, err)
+		return
+	}
+
+	err = response.Created(w, response.Envelope{"transfer": transfer}, nil)
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	var params service.ListTransfersParams
+
+	accountID, err := readIntQuery(r, "account_id", 0)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	params.AccountID = int32(accountID)
+
+	if params.DateFrom, err = readDateQuery(r, "date_from", time.Time{}); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+	if params.DateTo, err = readDateQuery(r, "date_to", time.Time{}); err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transfers, err := h.transferService.List(ctxUser.ID, &params)
+	if err != nil {
+		h.transferError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"transfers": transfers})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransferHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "transferID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transfer, err := h.transferService.GetByID(ctxUser.ID, int32(id))
+	if err != nil {
+		h.transferError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"transfer": transfer})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransferHandler) UpdateByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "transferID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	var input service.UpdateTransferParams
+	err = response.ReadJSON(w, r, &input)
+	if err != nil {
+		response.BadRequestResponse(w, r, err)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	transfer, err := h.transferService.UpdateByID(ctxUser.ID, int32(id), &input)
+	if err != nil {
+		h.transferError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"transfer": transfer})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *TransferHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
+	id, err := readIntParam(r, "transferID")
+	if err != nil {
+		response.BadRequestResponseGeneric(w, r)
+		return
+	}
+
+	ctxUser := appcontext.GetContextUser(r)
+
+	err = h.transferService.DeleteByID(ctxUser.ID, int32(id))
+	if err != nil {
+		h.transferError(w, r, err)
+		return
+	}
+
+	err = response.OK(w, response.Envelope{"message": "transfer successfully deleted"})
+	if err != nil {
+		response.ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func setupTestTransferHandler(t *testing.T) (*TransferHandler, *service.Service, func()) {
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+	handler := NewTransferHandler(svc.Transfer)
+
+	return handler, svc, cleanup
+}
+
+func TestTransferHandler(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	handler, svc, cleanup := setupTestTransferHandler(t)
+	defer cleanup()
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	otherUser := testutils.CreateTestUser(t, svc.User, "otheruser")
+	checking := testutils.CreateTestAccount(t, svc.Account, user.ID, "Checking")
+	savings := testutils.CreateTestAccount(t, svc.Account, user.ID, "Savings")
+	otherAccount := testutils.CreateTestAccount(t, svc.Account, otherUser.ID)
+	fees := testutils.CreateTestCategory(t, svc.Category, user.ID)
+
+	send := func(t *testing.T, h http.HandlerFunc, body any, transferID int32) *http.Response {
+		t.Helper()
+
+		req := testutils.CreatePostRequest(t, "/v1/transfers", body, user)
+		if transferID != 0 {
+			req.SetPathValue("transferID", strconv.Itoa(int(transferID)))
+		}
+
+		rr := httptest.NewRecorder()
+		h(rr, req)
+
+		return rr.Result()
+	}
+
+	decode := func(t *testing.T, res *http.Response) *service.TransferDetails {
+		t.Helper()
+
+		var resBody map[string]*service.TransferDetails
+		assert.NilError(t, json.NewDecoder(res.Body).Decode(&resBody))
+		return resBody["transfer"]
+	}
+
+	balance := func(t *testing.T, accountID int32) int64 {
+		t.Helper()
+
+		account, err := svc.Account.GetByID(accountID, user.ID)
+		assert.NilError(t, err)
+		return account.BalanceCents
+	}
+
+	t.Run("Invalid transfers", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			body           map[string]any
+			expectedStatus int
+		}{
+			{
+				name:           "Same account",
+				body:           map[string]any{"from_account_id": checking.ID, "to_account_id": checking.ID, "amount_cents": 1000},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "No amount",
+				body:           map[string]any{"from_account_id": checking.ID, "to_account_id": savings.ID},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Fee without category",
+				body:           map[string]any{"from_account_id": checking.ID, "to_account_id": savings.ID, "amount_cents": 1000, "fee_cents": 50},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Fee in the initial category",
+				body:           map[string]any{"from_account_id": checking.ID, "to_account_id": savings.ID, "amount_cents": 1000, "fee_cents": 50, "fee_category_id": 1},
+				expectedStatus: http.StatusUnprocessableEntity,
+			},
+			{
+				name:           "Other user's account",
+				body:           map[string]any{"from_account_id": checking.ID, "to_account_id": otherAccount.ID, "amount_cents": 1000},
+				expectedStatus: http.StatusNotFound,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res := send(t, handler.Create, tt.body, 0)
+				defer res.Body.Close()
+
+				assert.Equal(t, res.StatusCode, tt.expectedStatus)
+			})
+		}
+	})
+
+	res := send(t, handler.Create, map[string]any{
+		"from_account_id": checking.ID,
+		"to_account_id":   savings.ID,
+		"amount_cents":    1000,
+		"fee_cents":       50,
+		"fee_category_id": fees.ID,
+		"note":            "Rainy day",
+	}, 0)
+	defer res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusCreated)
+
+	created := decode(t, res)
+	assert.Equal(t, created.From.AmountCents, -1000)
+	assert.Equal(t, created.From.AccountID, checking.ID)
+	assert.Equal(t, created.To.AmountCents, 1000)
+	assert.Equal(t, created.To.AccountID, savings.ID)
+	assert.Equal(t, created.Fee.AmountCents, -50)
+	assert.Equal(t, created.Fee.CategoryID, fees.ID)
+	assert.Equal(t, created.To.Note, "Rainy day")
+	assert.Equal(t, balance(t, checking.ID), checking.BalanceCents-1050)
+	assert.Equal(t, balance(t, savings.ID), savings.BalanceCents+1000)
+
+	t.Run("List", func(t *testing.T) {
+		req := testutils.CreateGetRequest(t, "/v1/transfers?account_id="+strconv.Itoa(int(savings.ID)), user)
+
+		rr := httptest.NewRecorder()
+		handler.GetAll(rr, req)
+
+		res := rr.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		var resBody map[string][]*service.TransferDetails
+		json.NewDecoder(res.Body).Decode(&resBody)
+		assert.Equal(t, len(resBody["transfers"]), 1)
+		assert.Equal(t, resBody["transfers"][0].ID, created.ID)
+
+		req = testutils.CreateGetRequest(t, "/v1/transfers", otherUser)
+
+		rr = httptest.NewRecorder()
+		handler.GetAll(rr, req)
+
+		json.NewDecoder(rr.Result().Body).Decode(&resBody)
+		assert.Equal(t, len(resBody["transfers"]), 0)
+	})
+
+	t.Run("Legs are marked and kept out of income and expenses", func(t *testing.T) {
+		leg, err := svc.Transaction.GetByID(created.From.ID, user.ID)
+		assert.NilError(t, err)
+		assert.Equal(t, *leg.TransferID, created.ID)
+
+		expenses, _, err := svc.Transaction.List(user.ID, &service.ListTransactionsParams{
+			AccountIDs: []int32{checking.ID},
+			Sign:       "expense",
+			Sort:       "date",
+			Order:      "desc",
+			PageSize:   service.DefaultPageSize,
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, len(expenses), 1)
+		assert.Equal(t, expenses[0].ID, created.Fee.ID)
+	})
+
+	t.Run("Legs can't be changed on their own", func(t *testing.T) {
+		err := svc.Transaction.DeleteByID(created.To.ID, user.ID)
+		assert.Equal(t, errors.Is(err, service.ErrTransferTransaction), true)
+
+		amount := int64(5)
+		_, err = svc.Transaction.UpdateByID(created.Fee.ID, user.ID, &service.UpdateTransactionParams{AmountCents: &amount})
+		assert.Equal(t, errors.Is(err, service.ErrTransferTransaction), true)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		res := send(t, handler.UpdateByID, map[string]any{"amount_cents": 2500, "fee_cents": 0}, created.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+
+		updated := decode(t, res)
+		assert.Equal(t, updated.From.AmountCents, -2500)
+		assert.Equal(t, updated.To.AmountCents, 2500)
+		assert.Equal(t, updated.Fee == nil, true)
+		assert.Equal(t, updated.From.Note, "Rainy day")
+		assert.Equal(t, balance(t, checking.ID), checking.BalanceCents-2500)
+		assert.Equal(t, balance(t, savings.ID), savings.BalanceCents+2500)
+
+		_, err := svc.Transaction.GetByID(created.Fee.ID, user.ID)
+		assert.HasError(t, err)
+	})
+
+	t.Run("Update other user's transfer", func(t *testing.T) {
+		req := testutils.CreatePostRequest(t, "/v1/transfers", map[string]any{"amount_cents": 1}, otherUser)
+		req.SetPathValue("transferID", strconv.Itoa(int(created.ID)))
+
+		rr := httptest.NewRecorder()
+		handler.UpdateByID(rr, req)
+
+		assert.Equal(t, rr.Result().StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		res := send(t, handler.DeleteByID, nil, created.ID)
+		defer res.Body.Close()
+
+		assert.Equal(t, res.StatusCode, http.StatusOK)
+		assert.Equal(t, balance(t, checking.ID), checking.BalanceCents)
+		assert.Equal(t, balance(t, savings.ID), savings.BalanceCents)
+
+		for _, id := range []int32{created.From.ID, created.To.ID} {
+			_, err := svc.Transaction.GetByID(id, user.ID)
+			assert.HasError(t, err)
+		}
+
+		res = send(t, handler.GetByID, nil, created.ID)
+		defer res.Body.Close()
+		assert.Equal(t, res.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("Account transfer is linked", func(t *testing.T) {
+		leg, err := svc.Account.TransferByID(user.ID, checking.ID, &service.TransferParams{
+			AmountCents: 300,
+			RecipientID: savings.ID,
+		})
+		assert.NilError(t, err)
+
+		details, err := svc.Transaction.GetByID(leg.ID, user.ID)
+		assert.NilError(t, err)
+
+		transfer, err := svc.Transfer.GetByID(user.ID, *details.TransferID)
+		assert.NilError(t, err)
+		assert.Equal(t, transfer.To.AccountID, savings.ID)
+		assert.Equal(t, transfer.To.AmountCents, 300)
+	})
+}
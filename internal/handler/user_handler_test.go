@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/Quak1/gokei/internal/appcontext"
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/internal/service"
@@ -24,7 +25,7 @@ func setupTestUserHandler(t *testing.T) (*UserHandler, *service.Service, func())
 		t.Fatal(err)
 	}
 
-	svc := service.New(db)
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
 	handler := NewUserHandler(svc.User)
 
 	return handler, svc, cleanup
@@ -0,0 +1,95 @@
+// Package ical writes iCalendar (RFC 5545) feeds and parses recurrence rules
+// into the recurring transaction model.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxLineLength = 75
+	dateFormat    = "20060102"
+	stampFormat   = "20060102T150405Z"
+)
+
+// Event is an all-day event.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+type Calendar struct {
+	Name string
+	// Stamp is written as the DTSTAMP of every event.
+	Stamp  time.Time
+	Events []Event
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine writes a content line, folding it into several lines of at most 75
+// octets without splitting UTF-8 characters.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of continuation lines counts towards the limit.
+		limit = maxLineLength - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// Encode writes the calendar to w.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:-//gokei//recurring transactions//EN")
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	if c.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	stamp := c.Stamp.UTC().Format(stampFormat)
+	for _, event := range c.Events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+event.UID)
+		writeLine(bw, "DTSTAMP:"+stamp)
+		writeLine(bw, "DTSTART;VALUE=DATE:"+event.Date.Format(dateFormat))
+		writeLine(bw, "DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format(dateFormat))
+		writeLine(bw, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		writeLine(bw, "TRANSP:TRANSPARENT")
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+
+	return bw.Flush()
+}
@@ -0,0 +1,88 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	cal := &Calendar{
+		Name:  "Bills",
+		Stamp: time.Date(2025, time.January, 1, 12, 30, 0, 0, time.UTC),
+		Events: []Event{
+			{
+				UID:         "recurring-1-20250115@gokei",
+				Date:        time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC),
+				Summary:     "Rent; flat, 2B",
+				Description: "Amount: -500.00\nAccount: Checking",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := cal.Encode(&buf)
+	assert.NilError(t, err)
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//gokei//recurring transactions//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Bills",
+		"BEGIN:VEVENT",
+		"UID:recurring-1-20250115@gokei",
+		"DTSTAMP:20250101T123000Z",
+		"DTSTART;VALUE=DATE:20250115",
+		"DTEND;VALUE=DATE:20250116",
+		`SUMMARY:Rent\; flat\, 2B`,
+		`DESCRIPTION:Amount: -500.00\nAccount: Checking`,
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	assert.Equal(t, buf.String(), want)
+}
+
+func TestWriteLine_Folding(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{"short", "Rent"},
+		{"exactly 75 octets", strings.Repeat("a", 63)},
+		{"long ascii", strings.Repeat("abcdefghij", 20)},
+		{"long multibyte", strings.Repeat("ñ€", 60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := &Calendar{Events: []Event{{Summary: "x", Description: tt.description}}}
+			var buf bytes.Buffer
+			assert.NilError(t, cal.Encode(&buf))
+
+			var unfolded strings.Builder
+			for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+				if len(line) > maxLineLength {
+					t.Errorf("line %d is %d octets long", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character", i)
+				}
+				if strings.HasPrefix(line, " ") {
+					unfolded.WriteString(line[1:])
+				} else {
+					unfolded.WriteString("\n" + line)
+				}
+			}
+
+			assert.StringContains(t, unfolded.String(), "\nDESCRIPTION:"+tt.description+"\n")
+		})
+	}
+}
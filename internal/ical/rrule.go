@@ -0,0 +1,142 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+)
+
+var ErrInvalidRule = errors.New("invalid RRULE")
+
+// Rule is the part of a recurring transaction that can be described by an
+// RRULE.
+type Rule struct {
+	Frequency      store.RecurrenceFrequency
+	Interval       int32
+	DayWeek        *int32
+	DayMonth       *int32
+	EndDate        *time.Time
+	MaxOccurrences *int32
+}
+
+var frequencies = map[string]store.RecurrenceFrequency{
+	"DAILY":   store.RecurrenceFrequencyDaily,
+	"WEEKLY":  store.RecurrenceFrequencyWeekly,
+	"MONTHLY": store.RecurrenceFrequencyMonthly,
+	"YEARLY":  store.RecurrenceFrequencyYearly,
+}
+
+var weekdays = map[string]int32{
+	"SU": 0, "MO": 1, "TU": 2, "WE": 3, "TH": 4, "FR": 5, "SA": 6,
+}
+
+func invalid(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRule, fmt.Sprintf(format, a...))
+}
+
+// ParseRRULE parses a recurrence rule such as "FREQ=MONTHLY;BYMONTHDAY=1".
+// Only rules that the recurring transaction model can represent are accepted:
+// a single weekday for weekly rules and a single positive day of the month for
+// monthly and yearly ones.
+func ParseRRULE(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "RRULE:")
+	if s == "" {
+		return nil, invalid("empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+
+	for part := range strings.SplitSeq(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, invalid("malformed part %q", part)
+		}
+		if seen[name] {
+			return nil, invalid("%s is repeated", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			frequency, ok := frequencies[value]
+			if !ok {
+				return nil, invalid("unsupported FREQ %s", value)
+			}
+			rule.Frequency = frequency
+
+		case "INTERVAL":
+			interval, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || interval < 1 {
+				return nil, invalid("INTERVAL must be a positive integer")
+			}
+			rule.Interval = int32(interval)
+
+		case "BYDAY":
+			day, ok := weekdays[value]
+			if !ok {
+				return nil, invalid("unsupported BYDAY %s, only a single weekday is supported", value)
+			}
+			rule.DayWeek = &day
+
+		case "BYMONTHDAY":
+			day, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || day < 1 || day > 31 {
+				return nil, invalid("unsupported BYMONTHDAY %s, only a single day between 1 and 31 is supported", value)
+			}
+			dayMonth := int32(day)
+			rule.DayMonth = &dayMonth
+
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.EndDate = &until
+
+		case "COUNT":
+			count, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || count < 1 {
+				return nil, invalid("COUNT must be a positive integer")
+			}
+			maxOccurrences := int32(count)
+			rule.MaxOccurrences = &maxOccurrences
+
+		case "WKST":
+			// Only matters for weekly rules with several days, which aren't supported.
+
+		default:
+			return nil, invalid("unsupported part %s", name)
+		}
+	}
+
+	switch {
+	case rule.Frequency == "":
+		return nil, invalid("FREQ is required")
+	case rule.EndDate != nil && rule.MaxOccurrences != nil:
+		return nil, invalid("UNTIL and COUNT can't be combined")
+	case rule.DayWeek != nil && rule.Frequency != store.RecurrenceFrequencyWeekly:
+		return nil, invalid("BYDAY is only supported with FREQ=WEEKLY")
+	case rule.DayMonth != nil && rule.Frequency != store.RecurrenceFrequencyMonthly && rule.Frequency != store.RecurrenceFrequencyYearly:
+		return nil, invalid("BYMONTHDAY is only supported with FREQ=MONTHLY or FREQ=YEARLY")
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{dateFormat, stampFormat, "20060102T150405"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	return time.Time{}, invalid("UNTIL must be a date like 20250131 or 20250131T000000Z")
+}
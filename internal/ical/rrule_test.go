@@ -0,0 +1,83 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func formatRule(r *Rule) string {
+	s := fmt.Sprintf("%s/%d", r.Frequency, r.Interval)
+	if r.DayWeek != nil {
+		s += fmt.Sprintf(" day_week=%d", *r.DayWeek)
+	}
+	if r.DayMonth != nil {
+		s += fmt.Sprintf(" day_month=%d", *r.DayMonth)
+	}
+	if r.EndDate != nil {
+		s += " end_date=" + r.EndDate.Format(time.DateOnly)
+	}
+	if r.MaxOccurrences != nil {
+		s += fmt.Sprintf(" max_occurrences=%d", *r.MaxOccurrences)
+	}
+	return s
+}
+
+func TestParseRRULE(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"daily", "FREQ=DAILY", "daily/1"},
+		{"with prefix", "RRULE:FREQ=DAILY;INTERVAL=3", "daily/3"},
+		{"weekly on monday", "FREQ=WEEKLY;BYDAY=MO", "weekly/1 day_week=1"},
+		{"biweekly on sunday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU;WKST=MO", "weekly/2 day_week=0"},
+		{"monthly on the 31st", "FREQ=MONTHLY;BYMONTHDAY=31", "monthly/1 day_month=31"},
+		{"yearly with count", "FREQ=YEARLY;COUNT=5", "yearly/1 max_occurrences=5"},
+		{"until date", "FREQ=MONTHLY;UNTIL=20251231", "monthly/1 end_date=2025-12-31"},
+		{"until date-time", "FREQ=MONTHLY;UNTIL=20251231T235959Z", "monthly/1 end_date=2025-12-31"},
+		{"lowercase and spaces", " freq=weekly; byday=fr ", "weekly/1 day_week=5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRULE(tt.input)
+			assert.NilError(t, err)
+			assert.Equal(t, formatRule(rule), tt.want)
+		})
+	}
+}
+
+func TestParseRRULE_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"missing FREQ", "INTERVAL=2"},
+		{"unsupported FREQ", "FREQ=HOURLY"},
+		{"malformed", "FREQ"},
+		{"repeated part", "FREQ=DAILY;FREQ=WEEKLY"},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0"},
+		{"several weekdays", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"ordinal weekday", "FREQ=MONTHLY;BYDAY=1MO"},
+		{"BYDAY with daily", "FREQ=DAILY;BYDAY=MO"},
+		{"negative month day", "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"BYMONTHDAY with weekly", "FREQ=WEEKLY;BYMONTHDAY=1"},
+		{"UNTIL and COUNT", "FREQ=DAILY;UNTIL=20250101;COUNT=2"},
+		{"invalid UNTIL", "FREQ=DAILY;UNTIL=2025-01-01"},
+		{"unsupported part", "FREQ=YEARLY;BYMONTH=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRRULE(tt.input)
+			assert.HasError(t, err)
+			assert.Equal(t, errors.Is(err, ErrInvalidRule), true)
+		})
+	}
+}
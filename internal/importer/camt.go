@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The elements of a camt.053 document that entries are read from. Elements
+// are matched by local name, so every version of the schema is accepted.
+type camtDocument struct {
+	XMLName    xml.Name        `xml:"Document"`
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtBalance struct {
+	Type      string   `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    string   `xml:"Amt"`
+	Indicator string   `xml:"CdtDbtInd"`
+	Date      camtDate `xml:"Dt"`
+}
+
+type camtEntry struct {
+	Reference      string            `xml:"NtryRef"`
+	Amount         string            `xml:"Amt"`
+	Indicator      string            `xml:"CdtDbtInd"`
+	Status         camtStatus        `xml:"Sts"`
+	BookingDate    camtDate          `xml:"BookgDt"`
+	ValueDate      camtDate          `xml:"ValDt"`
+	ServicerRef    string            `xml:"AcctSvcrRef"`
+	Details        []camtTransaction `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string            `xml:"AddtlNtryInf"`
+}
+
+// camtStatus is written as <Sts>BOOK</Sts> up to version 6 and as
+// <Sts><Cd>BOOK</Cd></Sts> from version 7 on.
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTransaction struct {
+	ServicerRef    string    `xml:"Refs>AcctSvcrRef"`
+	Debtor         camtParty `xml:"RltdPties>Dbtr"`
+	Creditor       camtParty `xml:"RltdPties>Cdtr"`
+	Unstructured   []string  `xml:"RmtInf>Ustrd"`
+	Structured     []string  `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AdditionalInfo string    `xml:"AddtlTxInf"`
+}
+
+// camtParty holds the name directly up to version 7 and within Pty from
+// version 8 on.
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+func (p camtParty) name() string {
+	return strings.TrimSpace(p.Name + p.PartyName)
+}
+
+// ParseCAMT053 reads the booked entries of an ISO 20022 camt.053 bank to
+// customer statement. Pending entries are skipped. The ledger balance is the
+// closing booked balance of the last statement in the document.
+func ParseCAMT053(r io.Reader) (*Statement, error) {
+	var document camtDocument
+	err := xml.NewDecoder(r).Decode(&document)
+	if err != nil {
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &LineError{Line: syntaxErr.Line, Err: errors.New(syntaxErr.Msg)}
+		}
+		return nil, errors.New("file is not a camt.053 statement")
+	}
+	if len(document.Statements) == 0 {
+		return nil, errors.New("file is not a camt.053 statement")
+	}
+
+	statement := &Statement{Entries: []Entry{}}
+	for _, stmt := range document.Statements {
+		for _, ntry := range stmt.Entries {
+			status := strings.TrimSpace(ntry.Status.Value + ntry.Status.Code)
+			if status != "" && status != "BOOK" {
+				continue
+			}
+
+			entry, err := camtEntryToEntry(ntry)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", len(statement.Entries)+1, err)
+			}
+			statement.Entries = append(statement.Entries, entry)
+		}
+
+		for _, bal := range stmt.Balances {
+			if bal.Type != "CLBD" {
+				continue
+			}
+
+			balance, err := camtBalanceToBalance(bal)
+			if err != nil {
+				return nil, fmt.Errorf("closing balance: %w", err)
+			}
+			statement.LedgerBalance = balance
+		}
+	}
+
+	return statement, nil
+}
+
+func camtEntryToEntry(ntry camtEntry) (Entry, error) {
+	var entry Entry
+	var err error
+
+	date := ntry.BookingDate
+	if date.Date == "" && date.DateTime == "" {
+		date = ntry.ValueDate
+	}
+	entry.Date, err = date.parse()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.AmountCents, err = camtAmount(ntry.Amount, ntry.Indicator)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// Batch bookings have a TxDtls per transaction, but are a single entry on
+	// the account. The details of the first one are used.
+	var details camtTransaction
+	if len(ntry.Details) > 0 {
+		details = ntry.Details[0]
+	}
+
+	entry.ExternalID = strings.TrimSpace(ntry.ServicerRef)
+	if entry.ExternalID == "" {
+		entry.ExternalID = strings.TrimSpace(ntry.Reference)
+	}
+	if entry.ExternalID == "" {
+		entry.ExternalID = strings.TrimSpace(details.ServicerRef)
+	}
+
+	// The counterparty is the creditor of money going out and the debtor of
+	// money coming in.
+	counterparty := details.Debtor.name()
+	if entry.AmountCents < 0 {
+		counterparty = details.Creditor.name()
+	}
+
+	remittance := strings.TrimSpace(strings.Join(append(details.Unstructured, details.Structured...), " "))
+
+	entry.Title = firstNonEmpty(counterparty, remittance, details.AdditionalInfo, ntry.AdditionalInfo)
+	if remittance != entry.Title {
+		entry.Note = remittance
+	}
+	if entry.Title == "" {
+		return Entry{}, errors.New("title is empty")
+	}
+
+	return entry, nil
+}
+
+func camtBalanceToBalance(bal camtBalance) (*Balance, error) {
+	amount, err := camtAmount(bal.Amount, bal.Indicator)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := bal.Date.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balance{Date: date, AmountCents: amount}, nil
+}
+
+// camtAmount reads an amount, which is always positive, signed by its
+// credit or debit indicator.
+func camtAmount(amount, indicator string) (int64, error) {
+	cents, err := ParseAmount(strings.TrimSpace(amount), '.')
+	if err != nil {
+		return 0, err
+	}
+
+	switch strings.TrimSpace(indicator) {
+	case "CRDT":
+		return cents, nil
+	case "DBIT":
+		return -cents, nil
+	default:
+		return 0, fmt.Errorf("invalid credit or debit indicator %q", indicator)
+	}
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	s := strings.TrimSpace(d.Date)
+	if s == "" {
+		s = strings.TrimSpace(d.DateTime)
+	}
+
+	if len(s) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	date, err := time.Parse(time.DateOnly, s[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	return date, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
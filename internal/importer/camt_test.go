@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestParseCAMT053(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>MSG1</MsgId></GrpHdr>
+    <Stmt>
+      <Id>STMT1</Id>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2025-01-01</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">3454.80</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2025-01-31</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="EUR">45.20</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-01-03</Dt></BookgDt>
+        <ValDt><Dt>2025-01-04</Dt></ValDt>
+        <AcctSvcrRef>REF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties>
+            <Dbtr><Nm>Jane Doe</Nm></Dbtr>
+            <Cdtr><Nm>Supermarket &amp; Co</Nm></Cdtr>
+          </RltdPties>
+          <RmtInf><Ustrd>Card payment</Ustrd><Ustrd>Store 12</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">2500.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><DtTm>2025-01-05T08:00:00+01:00</DtTm></BookgDt>
+        <NtryDtls><TxDtls>
+          <Refs><AcctSvcrRef>REF-2</AcctSvcrRef></Refs>
+          <RltdPties><Dbtr><Pty><Nm>ACME Inc</Nm></Pty></Dbtr></RltdPties>
+          <RmtInf><Ustrd>Salary</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">9.99</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2025-01-30</Dt></BookgDt>
+        <AddtlNtryInf>Pending</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <NtryRef>REF-3</NtryRef>
+        <Amt Ccy="EUR">1.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-01-31</Dt></BookgDt>
+        <AddtlNtryInf>Account fee</AddtlNtryInf>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+	statement, err := ParseCAMT053(strings.NewReader(input))
+	assert.NilError(t, err)
+	if statement == nil {
+		return
+	}
+
+	want := []Entry{
+		{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -4520, Title: "Supermarket & Co", Note: "Card payment Store 12", ExternalID: "REF-1"},
+		{Date: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC), AmountCents: 250000, Title: "ACME Inc", Note: "Salary", ExternalID: "REF-2"},
+		{Date: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), AmountCents: -150, Title: "Account fee", ExternalID: "REF-3"},
+	}
+
+	assert.Equal(t, len(statement.Entries), len(want))
+	for i := range min(len(statement.Entries), len(want)) {
+		assert.Equal(t, statement.Entries[i], want[i])
+	}
+
+	if statement.LedgerBalance == nil {
+		t.Fatal("got no ledger balance")
+	}
+	assert.Equal(t, *statement.LedgerBalance, Balance{Date: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), AmountCents: 345480})
+}
+
+func TestParseCAMT053_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: "file is not a camt.053 statement",
+		},
+		{
+			name:    "other document",
+			input:   `<Document><BkToCstmrAcctRpt><Rpt></Rpt></BkToCstmrAcctRpt></Document>`,
+			wantErr: "file is not a camt.053 statement",
+		},
+		{
+			name:    "malformed XML",
+			input:   "<Document>\n<BkToCstmrStmt>\n</Document>",
+			wantErr: "line 3",
+		},
+		{
+			name: "missing indicator",
+			input: `<Document><BkToCstmrStmt><Stmt><Ntry><Amt>1.00</Amt><BookgDt><Dt>2025-01-01</Dt></BookgDt>` +
+				`<AddtlNtryInf>Fee</AddtlNtryInf></Ntry></Stmt></BkToCstmrStmt></Document>`,
+			wantErr: "entry 1: invalid credit or debit indicator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCAMT053(strings.NewReader(tt.input))
+			assert.HasError(t, err)
+			if err != nil {
+				assert.StringContains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
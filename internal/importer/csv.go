@@ -0,0 +1,231 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sign conventions of the amounts in a CSV export.
+const (
+	// SignSigned amounts are negative for money going out.
+	SignSigned = "signed"
+	// SignInverted amounts are positive for money going out, as in most
+	// credit card exports.
+	SignInverted = "inverted"
+	// SignDebitCredit exports have a debit column for money going out and a
+	// credit column for money coming in, both positive.
+	SignDebitCredit = "debit_credit"
+)
+
+// CSVMapping describes the layout of a bank's CSV export. Columns are
+// referenced by their header when HasHeader is set, or else by their position
+// starting at 1.
+type CSVMapping struct {
+	Delimiter        string `json:"delimiter"`
+	HasHeader        bool   `json:"has_header"`
+	SkipRows         int32  `json:"skip_rows"`
+	DateColumn       string `json:"date_column"`
+	DateFormat       string `json:"date_format"`
+	AmountColumn     string `json:"amount_column"`
+	DebitColumn      string `json:"debit_column"`
+	CreditColumn     string `json:"credit_column"`
+	TitleColumn      string `json:"title_column"`
+	NoteColumn       string `json:"note_column"`
+	DecimalSeparator string `json:"decimal_separator"`
+	SignConvention   string `json:"sign_convention"`
+}
+
+// columns holds the index of each mapped column, -1 for unmapped ones.
+type columns struct {
+	date, amount, debit, credit, title, note int
+}
+
+func (m *CSVMapping) resolveColumns(header []string) (columns, error) {
+	var err error
+	resolve := func(ref string) int {
+		if ref == "" || err != nil {
+			return -1
+		}
+
+		if m.HasHeader {
+			for i, name := range header {
+				if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(ref)) {
+					return i
+				}
+			}
+			err = fmt.Errorf("column %q not found in header", ref)
+			return -1
+		}
+
+		n, convErr := strconv.Atoi(ref)
+		if convErr != nil || n < 1 {
+			err = fmt.Errorf("column %q must be a position starting at 1 when there is no header", ref)
+			return -1
+		}
+		return n - 1
+	}
+
+	cols := columns{
+		date:   resolve(m.DateColumn),
+		amount: resolve(m.AmountColumn),
+		debit:  resolve(m.DebitColumn),
+		credit: resolve(m.CreditColumn),
+		title:  resolve(m.TitleColumn),
+		note:   resolve(m.NoteColumn),
+	}
+
+	return cols, err
+}
+
+// ParseCSV reads the entries of a CSV export laid out as described by m.
+// Empty lines are ignored. Errors about a specific line are *LineError.
+func ParseCSV(r io.Reader, m CSVMapping) ([]Entry, error) {
+	layout, err := DateLayout(m.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.DecimalSeparator != "." && m.DecimalSeparator != "," {
+		return nil, fmt.Errorf("invalid decimal separator %q", m.DecimalSeparator)
+	}
+	decimalSeparator := m.DecimalSeparator[0]
+
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if m.Delimiter != "" {
+		delimiter := []rune(m.Delimiter)
+		if len(delimiter) != 1 {
+			return nil, fmt.Errorf("invalid delimiter %q", m.Delimiter)
+		}
+		reader.Comma = delimiter[0]
+	}
+
+	for range m.SkipRows {
+		if _, err := reader.Read(); err != nil {
+			return nil, csvError(err)
+		}
+	}
+
+	var header []string
+	if m.HasHeader {
+		header, err = reader.Read()
+		if err != nil {
+			return nil, csvError(err)
+		}
+	}
+
+	cols, err := m.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		if isBlank(record) {
+			continue
+		}
+
+		entry, err := m.entry(record, cols, layout, decimalSeparator)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, &LineError{Line: line, Err: err}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (m *CSVMapping) entry(record []string, cols columns, layout string, decimalSeparator byte) (Entry, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var entry Entry
+	var err error
+
+	entry.Date, err = time.Parse(layout, field(cols.date))
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid date %q", field(cols.date))
+	}
+
+	switch m.SignConvention {
+	case SignDebitCredit:
+		var debit, credit int64
+		if s := field(cols.debit); s != "" {
+			if debit, err = ParseAmount(s, decimalSeparator); err != nil {
+				return Entry{}, err
+			}
+		}
+		if s := field(cols.credit); s != "" {
+			if credit, err = ParseAmount(s, decimalSeparator); err != nil {
+				return Entry{}, err
+			}
+		}
+		// Some banks write debits as negative numbers too.
+		entry.AmountCents = credit - max(debit, -debit)
+	case SignSigned, SignInverted:
+		entry.AmountCents, err = ParseAmount(field(cols.amount), decimalSeparator)
+		if err != nil {
+			return Entry{}, err
+		}
+		if m.SignConvention == SignInverted {
+			entry.AmountCents = -entry.AmountCents
+		}
+	default:
+		return Entry{}, fmt.Errorf("invalid sign convention %q", m.SignConvention)
+	}
+
+	entry.Title = field(cols.title)
+	entry.Note = field(cols.note)
+	if entry.Title == "" {
+		return Entry{}, errors.New("title is empty")
+	}
+
+	return entry, nil
+}
+
+func csvError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return errors.New("file has no rows")
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &LineError{Line: parseErr.Line, Err: parseErr.Err}
+	}
+
+	return err
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		mapping CSVMapping
+		want    []Entry
+	}{
+		{
+			name: "signed amounts with header",
+			input: "\xef\xbb\xbfDate,Description,Amount,Memo\n" +
+				"2025-01-03,Coffee,-3.50,\n" +
+				"\n" +
+				"2025-01-05,\"Salary, January\",2500.00,ACME Inc\n",
+			mapping: CSVMapping{
+				HasHeader:        true,
+				DateColumn:       "date",
+				DateFormat:       "YYYY-MM-DD",
+				AmountColumn:     "Amount",
+				TitleColumn:      "Description",
+				NoteColumn:       "Memo",
+				DecimalSeparator: ".",
+				SignConvention:   SignSigned,
+			},
+			want: []Entry{
+				{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -350, Title: "Coffee"},
+				{Date: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC), AmountCents: 250000, Title: "Salary, January", Note: "ACME Inc"},
+			},
+		},
+		{
+			name: "inverted amounts by position",
+			input: "Statement for card 1234\n" +
+				"03/01/2025;Restaurant;1.234,50\n" +
+				"04/01/2025;Payment;-500,00\n",
+			mapping: CSVMapping{
+				Delimiter:        ";",
+				SkipRows:         1,
+				DateColumn:       "1",
+				DateFormat:       "DD/MM/YYYY",
+				AmountColumn:     "3",
+				TitleColumn:      "2",
+				DecimalSeparator: ",",
+				SignConvention:   SignInverted,
+			},
+			want: []Entry{
+				{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -123450, Title: "Restaurant"},
+				{Date: time.Date(2025, time.January, 4, 0, 0, 0, 0, time.UTC), AmountCents: 50000, Title: "Payment"},
+			},
+		},
+		{
+			name: "debit and credit columns",
+			input: "Date\tText\tDebit\tCredit\n" +
+				"01/02/2025\tRent\t800.00\t\n" +
+				"01/03/2025\tRefund\t\t20.00\n" +
+				"01/04/2025\tFee\t-1.50\t\n",
+			mapping: CSVMapping{
+				Delimiter:        "\t",
+				HasHeader:        true,
+				DateColumn:       "Date",
+				DateFormat:       "MM/DD/YYYY",
+				DebitColumn:      "Debit",
+				CreditColumn:     "Credit",
+				TitleColumn:      "Text",
+				DecimalSeparator: ".",
+				SignConvention:   SignDebitCredit,
+			},
+			want: []Entry{
+				{Date: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC), AmountCents: -80000, Title: "Rent"},
+				{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: 2000, Title: "Refund"},
+				{Date: time.Date(2025, time.January, 4, 0, 0, 0, 0, time.UTC), AmountCents: -150, Title: "Fee"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseCSV(strings.NewReader(tt.input), tt.mapping)
+			assert.NilError(t, err)
+
+			assert.Equal(t, len(entries), len(tt.want))
+			for i := range min(len(entries), len(tt.want)) {
+				assert.Equal(t, entries[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	mapping := CSVMapping{
+		HasHeader:        true,
+		DateColumn:       "Date",
+		DateFormat:       "YYYY-MM-DD",
+		AmountColumn:     "Amount",
+		TitleColumn:      "Title",
+		DecimalSeparator: ".",
+		SignConvention:   SignSigned,
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		mapping  func(m CSVMapping) CSVMapping
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: "file has no rows",
+		},
+		{
+			name:    "missing column",
+			input:   "Date,Title\n",
+			wantErr: `column "Amount" not found in header`,
+		},
+		{
+			name:  "column by name without header",
+			input: "2025-01-01,Coffee,-3.50\n",
+			mapping: func(m CSVMapping) CSVMapping {
+				m.HasHeader = false
+				return m
+			},
+			wantErr: "must be a position",
+		},
+		{
+			name:     "invalid amount",
+			input:    "Date,Title,Amount\n2025-01-01,Coffee,-3.50\n2025-01-02,Tea,abc\n",
+			wantLine: 3,
+			wantErr:  "invalid amount",
+		},
+		{
+			name:     "invalid date",
+			input:    "Date,Title,Amount\n01/02/2025,Coffee,-3.50\n",
+			wantLine: 2,
+			wantErr:  "invalid date",
+		},
+		{
+			name:     "empty title",
+			input:    "Date,Title,Amount\n2025-01-01,,-3.50\n",
+			wantLine: 2,
+			wantErr:  "title is empty",
+		},
+		{
+			name:  "invalid date format",
+			input: "Date,Title,Amount\n",
+			mapping: func(m CSVMapping) CSVMapping {
+				m.DateFormat = "YYY"
+				return m
+			},
+			wantErr: "invalid date format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mapping
+			if tt.mapping != nil {
+				m = tt.mapping(m)
+			}
+
+			_, err := ParseCSV(strings.NewReader(tt.input), m)
+			assert.HasError(t, err)
+			if err == nil {
+				return
+			}
+			assert.StringContains(t, err.Error(), tt.wantErr)
+
+			if tt.wantLine > 0 {
+				var lineErr *LineError
+				assert.Equal(t, errors.As(err, &lineErr), true)
+				if lineErr != nil {
+					assert.Equal(t, lineErr.Line, tt.wantLine)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package importer reads the transactions of bank statement exports, and
+// writes them back out in the formats other tools import.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a transaction read from a statement.
+type Entry struct {
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amount_cents"`
+	Title       string    `json:"title"`
+	Note        string    `json:"note"`
+	// ExternalID is the bank's identifier for the transaction, when the
+	// format has one.
+	ExternalID string `json:"external_id,omitempty"`
+	// Category is the name of the entry's category, when the format has one.
+	Category string `json:"category,omitempty"`
+}
+
+// Balance is a balance reported by a statement.
+type Balance struct {
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amount_cents"`
+}
+
+// Statement holds the entries of a statement along with its closing ledger
+// balance, for the formats that report it.
+type Statement struct {
+	Entries       []Entry
+	LedgerBalance *Balance
+}
+
+// LineError reports the line of the statement an entry could not be read from.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err.Error())
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseAmount converts an amount such as "-1,234.56", "1.234,56-" or
+// "(12.00)" to cents. decimalSeparator is either '.' or ',', and the other one
+// is taken as a thousands separator. Amounts with more than two decimals are
+// rejected rather than rounded.
+func ParseAmount(s string, decimalSeparator byte) (int64, error) {
+	thousandsSeparator := ","
+	if decimalSeparator == ',' {
+		thousandsSeparator = "."
+	}
+
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.ReplaceAll(s, thousandsSeparator, "")
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+		negative, s = true, s[1:len(s)-1]
+	case strings.HasSuffix(s, "-"):
+		negative, s = true, s[:len(s)-1]
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, string(decimalSeparator))
+	if whole == "" && fraction == "" || len(fraction) > 2 || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w %q", ErrInvalidAmount, s)
+	}
+
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidAmount, s)
+	}
+
+	if negative {
+		cents = -cents
+	}
+
+	return cents, nil
+}
+
+// parseDecimalAmount reads an amount written with a decimal point, as most
+// formats specify, or with a decimal comma, as some banks write it anyway.
+func parseDecimalAmount(s string) (int64, error) {
+	if strings.Contains(s, ",") && !strings.Contains(s, ".") {
+		return ParseAmount(s, ',')
+	}
+	return ParseAmount(s, '.')
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DateLayout turns a date format written with YYYY, YY, MM, M, DD and D, such
+// as "DD/MM/YYYY", into a layout for time.Parse.
+func DateLayout(format string) (string, error) {
+	var layout strings.Builder
+
+	for i := 0; i < len(format); {
+		c := format[i]
+		if c != 'Y' && c != 'M' && c != 'D' {
+			layout.WriteByte(c)
+			i++
+			continue
+		}
+
+		n := 1
+		for i+n < len(format) && format[i+n] == c {
+			n++
+		}
+
+		switch {
+		case c == 'Y' && n == 4:
+			layout.WriteString("2006")
+		case c == 'Y' && n == 2:
+			layout.WriteString("06")
+		case c == 'M' && n == 2:
+			layout.WriteString("01")
+		case c == 'M' && n == 1:
+			layout.WriteString("1")
+		case c == 'D' && n == 2:
+			layout.WriteString("02")
+		case c == 'D' && n == 1:
+			layout.WriteString("2")
+		default:
+			return "", fmt.Errorf("invalid date format %q", format)
+		}
+		i += n
+	}
+
+	return layout.String(), nil
+}
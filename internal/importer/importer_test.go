@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input            string
+		decimalSeparator byte
+		want             int64
+		wantErr          bool
+	}{
+		{"12.34", '.', 1234, false},
+		{"-12.34", '.', -1234, false},
+		{"+12", '.', 1200, false},
+		{"1,234.5", '.', 123450, false},
+		{"1.234,56", ',', 123456, false},
+		{"1 234,56", ',', 123456, false},
+		{"1'234.56", '.', 123456, false},
+		{"12.34-", '.', -1234, false},
+		{"(12.34)", '.', -1234, false},
+		{".5", '.', 50, false},
+		{"0,07", ',', 7, false},
+		{"", '.', 0, true},
+		{"-", '.', 0, true},
+		{"12.345", '.', 0, true},
+		{"1.2.3", '.', 0, true},
+		{"12a", '.', 0, true},
+		{"$12", '.', 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAmount(tt.input, tt.decimalSeparator)
+			if tt.wantErr {
+				assert.HasError(t, err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{"YYYY-MM-DD", "2006-01-02", false},
+		{"DD/MM/YYYY", "02/01/2006", false},
+		{"M/D/YY", "1/2/06", false},
+		{"YYYYMMDD", "20060102", false},
+		{"DD.MM.YYYY", "02.01.2006", false},
+		{"YYY-MM-DD", "", true},
+		{"DDD/MM/YYYY", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := DateLayout(tt.format)
+			if tt.wantErr {
+				assert.HasError(t, err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
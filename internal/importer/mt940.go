@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// mt940Field is a field of an MT940 message, such as :61:, along with the
+// line it starts on.
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+var (
+	mt940TagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+
+	// mt940LinePattern matches the statement line of a :61: field: value
+	// date, optional entry date, credit or debit mark, optional funds code,
+	// amount, transaction type, reference for the account owner, optional
+	// reference of the bank and optional supplementary details.
+	mt940LinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d{0,2})([NFS][A-Z0-9]{3})([^\n]*?)(?://([^\n]*))?(?:\n([\s\S]*))?$`)
+
+	// mt940BalancePattern matches a balance field such as :62F:.
+	mt940BalancePattern = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})(\d+,\d{0,2})$`)
+)
+
+// ParseMT940 reads the entries of a SWIFT MT940 customer statement. Files
+// holding several messages, with or without their SWIFT blocks, are accepted.
+// The ledger balance is the closing balance of the last message. Errors about
+// a specific field are *LineError.
+func ParseMT940(r io.Reader) (*Statement, error) {
+	fields, err := mt940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("file is not an MT940 statement")
+	}
+
+	statement := &Statement{Entries: []Entry{}}
+	var last *Entry
+
+	for _, field := range fields {
+		switch field.tag {
+		case "61":
+			entry, err := mt940Entry(field.value)
+			if err != nil {
+				return nil, &LineError{Line: field.line, Err: err}
+			}
+			statement.Entries = append(statement.Entries, entry)
+			last = &statement.Entries[len(statement.Entries)-1]
+		case "86":
+			if last == nil {
+				continue
+			}
+			if err := applyMT940Information(last, field.value); err != nil {
+				return nil, &LineError{Line: field.line, Err: err}
+			}
+			last = nil
+		case "62F", "62M":
+			balance, err := mt940Balance(field.value)
+			if err != nil {
+				return nil, &LineError{Line: field.line, Err: err}
+			}
+			statement.LedgerBalance = balance
+		}
+	}
+
+	for i, entry := range statement.Entries {
+		if entry.Title == "" {
+			return nil, fmt.Errorf("entry %d: title is empty", i+1)
+		}
+	}
+
+	return statement, nil
+}
+
+// mt940Fields splits the messages into fields, joining continuation lines
+// with a newline and dropping the SWIFT blocks around the message text.
+func mt940Fields(r io.Reader) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r ")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\xef\xbb\xbf")
+		}
+
+		// The text block starts after {4: and ends with -}.
+		if i := strings.Index(text, "{4:"); i >= 0 {
+			text = text[i+len("{4:"):]
+		}
+		if text == "" || text == "-" || strings.HasPrefix(text, "-}") || strings.HasPrefix(text, "{") {
+			continue
+		}
+
+		if match := mt940TagPattern.FindStringSubmatch(text); match != nil {
+			fields = append(fields, mt940Field{
+				tag:   match[1],
+				value: text[len(match[0]):],
+				line:  line,
+			})
+			continue
+		}
+
+		if len(fields) == 0 {
+			return nil, &LineError{Line: line, Err: errors.New("file is not an MT940 statement")}
+		}
+		fields[len(fields)-1].value += "\n" + text
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func mt940Entry(value string) (Entry, error) {
+	match := mt940LinePattern.FindStringSubmatch(value)
+	if match == nil {
+		return Entry{}, fmt.Errorf("invalid statement line %q", firstLine(value))
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid date %q", match[1])
+	}
+
+	// The booking date has no year, so it is taken from the value date, moving
+	// to the previous or next year for bookings across the new year.
+	date := valueDate
+	if match[2] != "" {
+		date, err = time.Parse("20060102", fmt.Sprintf("%04d%s", valueDate.Year(), match[2]))
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid date %q", match[2])
+		}
+		switch {
+		case date.Sub(valueDate) > 180*24*time.Hour:
+			date = date.AddDate(-1, 0, 0)
+		case valueDate.Sub(date) > 180*24*time.Hour:
+			date = date.AddDate(1, 0, 0)
+		}
+	}
+
+	amount, err := ParseAmount(match[5], ',')
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// Debits and reversals of credits take money out of the account.
+	if mark := match[3]; mark == "D" || mark == "RC" {
+		amount = -amount
+	}
+
+	// Only the bank's reference identifies the transaction. The owner's
+	// reference is whatever the payer wrote, such as an invoice number, which
+	// many transactions can share.
+	entry := Entry{
+		Date:        date,
+		AmountCents: amount,
+		ExternalID:  strings.TrimSpace(match[8]),
+		Title:       strings.TrimSpace(strings.ReplaceAll(match[9], "\n", " ")),
+	}
+
+	return entry, nil
+}
+
+// applyMT940Information fills in the entry from its :86: field. Banks write
+// it either as structured subfields, such as the German ?20 to ?29 for the
+// remittance information and ?32 and ?33 for the counterparty, as codes such
+// as /NAME/ and /REMI/, or as free text.
+func applyMT940Information(entry *Entry, value string) error {
+	var name, remittance string
+
+	switch {
+	case len(value) > 3 && isDigits(value[:3]) && strings.Contains(value, "?"):
+		subfields := mt940Subfields(strings.ReplaceAll(value[3:], "\n", ""))
+		name = subfields["32"] + subfields["33"]
+		for code := 20; code <= 29; code++ {
+			remittance += subfields[fmt.Sprint(code)]
+		}
+		if remittance == "" {
+			remittance = subfields["00"]
+		}
+	case strings.Contains(value, "/NAME/") || strings.Contains(value, "/REMI/"):
+		codes := mt940Codes(strings.ReplaceAll(value, "\n", ""))
+		name = codes["NAME"]
+		remittance = codes["REMI"]
+	default:
+		remittance = strings.ReplaceAll(value, "\n", " ")
+	}
+
+	name = strings.TrimSpace(name)
+	remittance = strings.TrimSpace(remittance)
+
+	title := firstNonEmpty(name, remittance, entry.Title)
+	if title == "" {
+		return errors.New("title is empty")
+	}
+
+	entry.Title = title
+	if remittance != title {
+		entry.Note = remittance
+	}
+
+	return nil
+}
+
+// mt940Subfields splits ?20Text?21More into its subfields by code.
+func mt940Subfields(s string) map[string]string {
+	subfields := map[string]string{}
+	for _, part := range strings.Split(s, "?")[1:] {
+		if len(part) >= 2 {
+			subfields[part[:2]] += part[2:]
+		}
+	}
+	return subfields
+}
+
+// mt940Codes splits /CODE/value/CODE/value into its values by code. Values may
+// contain slashes, so only the known codes start a new value.
+func mt940Codes(s string) map[string]string {
+	known := []string{"TRTP", "IBAN", "BIC", "NAME", "REMI", "EREF", "MARF", "CSID", "ORDP", "BENM", "ID", "ADDR", "CNTP", "SVCL", "PURP", "ISDT", "RTRN"}
+
+	codes := map[string]string{}
+	current := ""
+	for _, part := range strings.Split(s, "/") {
+		if slices.Contains(known, part) {
+			current = part
+			codes[current] = ""
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		if codes[current] != "" {
+			codes[current] += "/"
+		}
+		codes[current] += part
+	}
+
+	for code, value := range codes {
+		codes[code] = strings.Trim(value, "/")
+	}
+
+	return codes
+}
+
+func mt940Balance(value string) (*Balance, error) {
+	match := mt940BalancePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil, fmt.Errorf("invalid balance %q", value)
+	}
+
+	date, err := time.Parse("060102", match[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", match[2])
+	}
+
+	amount, err := ParseAmount(match[4], ',')
+	if err != nil {
+		return nil, err
+	}
+	if match[1] == "D" {
+		amount = -amount
+	}
+
+	return &Balance{Date: date, AmountCents: amount}, nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestParseMT940(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        []Entry
+		wantBalance *Balance
+	}{
+		{
+			name: "German structured information",
+			input: "{1:F01BANKDEFFAXXX0000000000}{2:O940BANKDEFFXXXX}{4:\r\n" +
+				":20:STARTUMS\r\n" +
+				":25:10020030/1234567\r\n" +
+				":28C:1/1\r\n" +
+				":60F:C241231EUR1000,00\r\n" +
+				":61:2501030103DR45,20NMSCNONREF//BREF1\r\n" +
+				":86:106?00KARTENZAHLUNG?20Card payment?21Store 12?32SUPERMARKT\r\n" +
+				"?33GMBH\r\n" +
+				":61:2412310102CR2500,NTRFNONREF//BREF2\r\n" +
+				":86:166?00GUTSCHRIFT?20Salary?32ACME INC\r\n" +
+				":62F:C250131EUR3454,80\r\n" +
+				"-}\r\n",
+			want: []Entry{
+				{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -4520, Title: "SUPERMARKTGMBH", Note: "Card paymentStore 12", ExternalID: "BREF1"},
+				{Date: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC), AmountCents: 250000, Title: "ACME INC", Note: "Salary", ExternalID: "BREF2"},
+			},
+			wantBalance: &Balance{Date: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), AmountCents: 345480},
+		},
+		{
+			name: "Dutch codes and free text",
+			input: ":20:940S250210\n" +
+				":25:NL91ABNA0417164300\n" +
+				":28C:00001\n" +
+				":60F:D250209EUR100,00\n" +
+				":61:250210C12,50NTRFEREF-1\n" +
+				":86:/TRTP/SEPA OVERBOEKING/IBAN/NL02ABNA0123456789/BIC/ABNANL2A/NAME/\n" +
+				"J. Jansen/REMI/Dinner 07/02/EREF/EREF-1\n" +
+				":61:250211RC3,00NCHGNONREF\n" +
+				":86:Reversed fee\n" +
+				":62F:D250211EUR90,50\n" +
+				"-\n",
+			want: []Entry{
+				{Date: time.Date(2025, time.February, 10, 0, 0, 0, 0, time.UTC), AmountCents: 1250, Title: "J. Jansen", Note: "Dinner 07/02"},
+				{Date: time.Date(2025, time.February, 11, 0, 0, 0, 0, time.UTC), AmountCents: -300, Title: "Reversed fee"},
+			},
+			wantBalance: &Balance{Date: time.Date(2025, time.February, 11, 0, 0, 0, 0, time.UTC), AmountCents: -9050},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := ParseMT940(strings.NewReader(tt.input))
+			assert.NilError(t, err)
+			if statement == nil {
+				return
+			}
+
+			assert.Equal(t, len(statement.Entries), len(tt.want))
+			for i := range min(len(statement.Entries), len(tt.want)) {
+				assert.Equal(t, statement.Entries[i], tt.want[i])
+			}
+
+			if statement.LedgerBalance == nil {
+				t.Fatal("got no ledger balance")
+			}
+			assert.Equal(t, *statement.LedgerBalance, *tt.wantBalance)
+		})
+	}
+}
+
+func TestParseMT940_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: "file is not an MT940 statement",
+		},
+		{
+			name:     "not MT940",
+			input:    "Date,Title,Amount\n",
+			wantLine: 1,
+			wantErr:  "file is not an MT940 statement",
+		},
+		{
+			name:     "invalid statement line",
+			input:    ":20:X\n:61:250210X12,50NTRFNONREF\n",
+			wantLine: 2,
+			wantErr:  "invalid statement line",
+		},
+		{
+			name:     "invalid balance",
+			input:    ":20:X\n:62F:C250131EUR\n",
+			wantLine: 2,
+			wantErr:  "invalid balance",
+		},
+		{
+			name:    "missing title",
+			input:   ":20:X\n:61:250210C12,50NTRFNONREF\n",
+			wantErr: "entry 1: title is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMT940(strings.NewReader(tt.input))
+			assert.HasError(t, err)
+			if err == nil {
+				return
+			}
+			assert.StringContains(t, err.Error(), tt.wantErr)
+
+			if tt.wantLine > 0 {
+				var lineErr *LineError
+				assert.Equal(t, errors.As(err, &lineErr), true)
+				if lineErr != nil {
+					assert.Equal(t, lineErr.Line, tt.wantLine)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseOFX reads the transactions of an OFX or QFX download. Both OFX 1.x,
+// which is SGML whose elements may be left unclosed, and the XML based OFX 2.x
+// are accepted. Every statement in the file is read, and the ledger balance
+// is taken from the last one.
+func ParseOFX(r io.Reader) (*Statement, error) {
+	br := bufio.NewReader(r)
+
+	// Skip the OFX 1.x header lines or the XML prolog.
+	if _, err := br.ReadString('<'); err != nil {
+		return nil, errors.New("file is not OFX")
+	}
+	br.UnreadByte()
+
+	statement := &Statement{Entries: []Entry{}}
+	seenOFX := false
+
+	var transaction map[string]string
+	var balance map[string]string
+	var aggregates []string
+
+	for {
+		tag, value, err := nextOFXElement(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case tag == "OFX":
+			seenOFX = true
+		case tag == "STMTTRN":
+			transaction = map[string]string{}
+		case tag == "/STMTTRN":
+			if transaction == nil {
+				continue
+			}
+			entry, err := ofxEntry(transaction)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d: %w", len(statement.Entries)+1, err)
+			}
+			statement.Entries = append(statement.Entries, entry)
+			transaction = nil
+		case tag == "LEDGERBAL":
+			balance = map[string]string{}
+		case tag == "/LEDGERBAL":
+			if balance == nil {
+				continue
+			}
+			ledger, err := ofxBalance(balance)
+			if err != nil {
+				return nil, fmt.Errorf("ledger balance: %w", err)
+			}
+			statement.LedgerBalance = ledger
+			balance = nil
+		case strings.HasPrefix(tag, "/"):
+			if n := len(aggregates); n > 0 && aggregates[n-1] == tag[1:] {
+				aggregates = aggregates[:n-1]
+			}
+		case value == "":
+			aggregates = append(aggregates, tag)
+		case transaction != nil:
+			// The payee's name is nested in a PAYEE aggregate in place of NAME.
+			if tag == "NAME" && len(aggregates) > 0 && aggregates[len(aggregates)-1] == "PAYEE" {
+				tag = "PAYEE.NAME"
+			}
+			transaction[tag] = value
+		case balance != nil:
+			balance[tag] = value
+		}
+	}
+
+	if !seenOFX {
+		return nil, errors.New("file is not OFX")
+	}
+
+	return statement, nil
+}
+
+// nextOFXElement returns the next tag, without its angle brackets, along with
+// the text that follows it. Closing tags keep their leading slash.
+func nextOFXElement(br *bufio.Reader) (string, string, error) {
+	for {
+		if _, err := br.ReadString('<'); err != nil {
+			return "", "", err
+		}
+
+		tag, err := br.ReadString('>')
+		if err != nil {
+			return "", "", errors.New("unterminated tag")
+		}
+		tag = strings.TrimSuffix(tag, ">")
+
+		// Skip processing instructions and comments.
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+
+		// Attributes are not used by OFX, but tolerate them.
+		if name, _, found := strings.Cut(tag, " "); found {
+			tag = name
+		}
+
+		value, err := br.ReadString('<')
+		if err == nil {
+			br.UnreadByte()
+			value = value[:len(value)-1]
+		} else if !errors.Is(err, io.EOF) {
+			return "", "", err
+		}
+
+		return strings.ToUpper(tag), html.UnescapeString(strings.TrimSpace(value)), nil
+	}
+}
+
+func ofxEntry(fields map[string]string) (Entry, error) {
+	var entry Entry
+	var err error
+
+	entry.Date, err = parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.AmountCents, err = parseDecimalAmount(fields["TRNAMT"])
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.ExternalID = fields["FITID"]
+
+	entry.Title = fields["NAME"]
+	if entry.Title == "" {
+		entry.Title = fields["PAYEE.NAME"]
+	}
+
+	memo := fields["MEMO"]
+	switch {
+	case entry.Title == "" && memo != "":
+		entry.Title = memo
+	case memo != entry.Title:
+		entry.Note = memo
+	}
+
+	if entry.Title == "" {
+		entry.Title = fields["TRNTYPE"]
+	}
+	if entry.Title == "" {
+		return Entry{}, errors.New("title is empty")
+	}
+
+	return entry, nil
+}
+
+func ofxBalance(fields map[string]string) (*Balance, error) {
+	amount, err := parseDecimalAmount(fields["BALAMT"])
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &Balance{AmountCents: amount}
+	if fields["DTASOF"] != "" {
+		balance.Date, err = parseOFXDate(fields["DTASOF"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return balance, nil
+}
+
+// parseOFXDate reads the day of a datetime such as 20250131120000.000[-5:EST].
+// The time of day is dropped, as it is for every transaction date.
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	date, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        []Entry
+		wantBalance *Balance
+	}{
+		{
+			name: "OFX 1.x SGML",
+			input: "OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nCHARSET:1252\r\n\r\n" +
+				"<OFX>\r\n<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS></SONRS></SIGNONMSGSRSV1>\r\n" +
+				"<BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD<BANKTRANLIST>\r\n" +
+				"<STMTTRN>\r\n<TRNTYPE>DEBIT\r\n<DTPOSTED>20250103120000.000[-5:EST]\r\n<TRNAMT>-45.20\r\n<FITID>2025010301\r\n<NAME>Smith &amp; Sons\r\n<MEMO>Groceries\r\n</STMTTRN>\r\n" +
+				"<STMTTRN>\r\n<TRNTYPE>CREDIT\r\n<DTPOSTED>20250105\r\n<TRNAMT>2500\r\n<FITID>2025010501\r\n<NAME>Salary\r\n<MEMO>Salary\r\n</STMTTRN>\r\n" +
+				"</BANKTRANLIST>\r\n<LEDGERBAL><BALAMT>3120.55<DTASOF>20250131</LEDGERBAL>\r\n" +
+				"</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\r\n",
+			want: []Entry{
+				{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -4520, Title: "Smith & Sons", Note: "Groceries", ExternalID: "2025010301"},
+				{Date: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC), AmountCents: 250000, Title: "Salary", ExternalID: "2025010501"},
+			},
+			wantBalance: &Balance{Date: time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), AmountCents: 312055},
+		},
+		{
+			name: "OFX 2.x XML",
+			input: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<?OFX OFXHEADER=\"200\" VERSION=\"220\"?>\n" +
+				"<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><BANKTRANLIST>\n" +
+				"<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20250210</DTPOSTED><TRNAMT>-12,00</TRNAMT><FITID>A1</FITID>" +
+				"<PAYEE><NAME>Bookshop</NAME><ADDR1>Main St</ADDR1></PAYEE></STMTTRN>\n" +
+				"<STMTTRN><TRNTYPE>FEE</TRNTYPE><DTPOSTED>20250211</DTPOSTED><TRNAMT>-1.5</TRNAMT><FITID>A2</FITID><MEMO>Card fee</MEMO></STMTTRN>\n" +
+				"</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>\n",
+			want: []Entry{
+				{Date: time.Date(2025, time.February, 10, 0, 0, 0, 0, time.UTC), AmountCents: -1200, Title: "Bookshop", ExternalID: "A1"},
+				{Date: time.Date(2025, time.February, 11, 0, 0, 0, 0, time.UTC), AmountCents: -150, Title: "Card fee", ExternalID: "A2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, err := ParseOFX(strings.NewReader(tt.input))
+			assert.NilError(t, err)
+			if statement == nil {
+				return
+			}
+
+			assert.Equal(t, len(statement.Entries), len(tt.want))
+			for i := range min(len(statement.Entries), len(tt.want)) {
+				assert.Equal(t, statement.Entries[i], tt.want[i])
+			}
+
+			if tt.wantBalance == nil {
+				assert.Equal(t, statement.LedgerBalance, nil)
+			} else if statement.LedgerBalance != nil {
+				assert.Equal(t, *statement.LedgerBalance, *tt.wantBalance)
+			} else {
+				t.Error("got no ledger balance")
+			}
+		})
+	}
+}
+
+func TestParseOFX_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: "file is not OFX",
+		},
+		{
+			name:    "not OFX",
+			input:   "<html><body>Session expired</body></html>",
+			wantErr: "file is not OFX",
+		},
+		{
+			name:    "invalid date",
+			input:   "<OFX><STMTTRN><DTPOSTED>2025<TRNAMT>1.00<NAME>Coffee</STMTTRN></OFX>",
+			wantErr: "transaction 1: invalid date",
+		},
+		{
+			name:    "invalid amount",
+			input:   "<OFX><STMTTRN><DTPOSTED>20250101<TRNAMT>1.005<NAME>Coffee</STMTTRN></OFX>",
+			wantErr: "transaction 1: invalid amount",
+		},
+		{
+			name:    "invalid ledger balance",
+			input:   "<OFX><LEDGERBAL><BALAMT>abc<DTASOF>20250101</LEDGERBAL></OFX>",
+			wantErr: "ledger balance: invalid amount",
+		},
+		{
+			name:    "unterminated tag",
+			input:   "<OFX><STMTTRN",
+			wantErr: "unterminated tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOFX(strings.NewReader(tt.input))
+			assert.HasError(t, err)
+			if err != nil {
+				assert.StringContains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Account types of the QIF transaction sections.
+const (
+	QIFTypeBank  = "Bank"
+	QIFTypeCash  = "Cash"
+	QIFTypeCCard = "CCard"
+)
+
+// Orders of the day, month and year in QIF dates, which are written however
+// the exporting tool's locale writes them.
+const (
+	DateOrderMDY = "mdy"
+	DateOrderDMY = "dmy"
+	DateOrderYMD = "ymd"
+)
+
+// QIF is the transaction section of a QIF file.
+type QIF struct {
+	Type    string
+	Entries []Entry
+}
+
+// ParseQIF reads the transactions of a QIF file. Bank, cash and credit card
+// sections are read, lists such as !Type:Cat are skipped, and other account
+// types are rejected. Split lines are ignored, so split transactions keep the
+// category of their L line. Errors about a specific record are *LineError.
+func ParseQIF(r io.Reader, dateOrder string) (*QIF, error) {
+	if dateOrder != DateOrderMDY && dateOrder != DateOrderDMY && dateOrder != DateOrderYMD {
+		return nil, fmt.Errorf("invalid date order %q", dateOrder)
+	}
+
+	qif := &QIF{Entries: []Entry{}}
+	scanner := bufio.NewScanner(r)
+
+	// inTransactions is set within a transaction section, and skipping within
+	// any other section.
+	inTransactions, skipping := false, false
+	record := map[byte]string{}
+	recordLine := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\xef\xbb\xbf")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if text[0] == '!' {
+			header := strings.TrimSpace(text[1:])
+			name, value, _ := strings.Cut(header, ":")
+
+			switch {
+			case strings.EqualFold(name, "Type"):
+				qifType, ok := qifTransactionType(strings.TrimSpace(value))
+				switch {
+				case ok && qif.Type != "" && qif.Type != qifType:
+					return nil, &LineError{Line: line, Err: fmt.Errorf("file mixes %s and %s accounts", qif.Type, qifType)}
+				case ok:
+					qif.Type = qifType
+					inTransactions, skipping = true, false
+				case isQIFList(value):
+					inTransactions, skipping = false, true
+				default:
+					return nil, &LineError{Line: line, Err: fmt.Errorf("unsupported account type %q", value)}
+				}
+			case strings.EqualFold(name, "Account"):
+				inTransactions, skipping = false, true
+			}
+			continue
+		}
+
+		if !inTransactions && !skipping {
+			return nil, &LineError{Line: line, Err: errors.New("missing !Type header")}
+		}
+		if skipping {
+			continue
+		}
+
+		if text[0] != '^' {
+			if len(record) == 0 {
+				recordLine = line
+			}
+			record[text[0]] = strings.TrimSpace(text[1:])
+			continue
+		}
+
+		if len(record) > 0 {
+			entry, err := qifEntry(record, dateOrder)
+			if err != nil {
+				return nil, &LineError{Line: recordLine, Err: err}
+			}
+			qif.Entries = append(qif.Entries, entry)
+			record = map[byte]string{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(record) > 0 {
+		return nil, &LineError{Line: recordLine, Err: errors.New("record is not terminated by ^")}
+	}
+	if qif.Type == "" {
+		return nil, errors.New("file has no bank, cash or credit card transactions")
+	}
+
+	return qif, nil
+}
+
+func qifTransactionType(s string) (string, bool) {
+	for _, qifType := range []string{QIFTypeBank, QIFTypeCash, QIFTypeCCard} {
+		if strings.EqualFold(s, qifType) {
+			return qifType, true
+		}
+	}
+	return "", false
+}
+
+func isQIFList(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "cat", "class", "memorized", "prices", "security":
+		return true
+	}
+	return false
+}
+
+func qifEntry(record map[byte]string, dateOrder string) (Entry, error) {
+	var entry Entry
+	var err error
+
+	entry.Date, err = parseQIFDate(record['D'], dateOrder)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	amount := record['T']
+	if amount == "" {
+		amount = record['U']
+	}
+	entry.AmountCents, err = parseDecimalAmount(amount)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry.Title = record['P']
+	entry.Note = record['M']
+	if entry.Title == "" {
+		entry.Title, entry.Note = entry.Note, ""
+	}
+	if entry.Title == "" {
+		return Entry{}, errors.New("title is empty")
+	}
+
+	// Categories are written as Category:Subcategory/Class, and transfers
+	// as [Account].
+	category, _, _ := strings.Cut(record['L'], "/")
+	if !strings.HasPrefix(category, "[") {
+		entry.Category = strings.TrimSpace(category)
+	}
+
+	return entry, nil
+}
+
+// parseQIFDate reads dates such as 1/3/2025, 01-03-25 or Quicken's 1/ 3'25.
+// Two-digit years from 70 on are taken to be in the 1900s.
+func parseQIFDate(s string, dateOrder string) (time.Time, error) {
+	invalid := fmt.Errorf("invalid date %q", s)
+
+	normalized := strings.NewReplacer("'", "/", "-", "/", ".", "/", " ", "").Replace(s)
+	parts := strings.Split(normalized, "/")
+	if len(parts) != 3 {
+		return time.Time{}, invalid
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || !isDigits(part) {
+			return time.Time{}, invalid
+		}
+		numbers[i] = n
+	}
+
+	var year, month, day int
+	yearIndex := 2
+	switch dateOrder {
+	case DateOrderMDY:
+		month, day, year = numbers[0], numbers[1], numbers[2]
+	case DateOrderDMY:
+		day, month, year = numbers[0], numbers[1], numbers[2]
+	case DateOrderYMD:
+		year, month, day = numbers[0], numbers[1], numbers[2]
+		yearIndex = 0
+	}
+
+	switch len(parts[yearIndex]) {
+	case 2:
+		year += 2000
+		if year >= 2070 {
+			year -= 100
+		}
+	case 4:
+	default:
+		return time.Time{}, invalid
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, invalid
+	}
+
+	return date, nil
+}
+
+var qifLineReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// Encode writes q as QIF, with dates written month first as most tools expect.
+func (q *QIF) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	qifType := q.Type
+	if qifType == "" {
+		qifType = QIFTypeBank
+	}
+	fmt.Fprintf(bw, "!Type:%s\n", qifType)
+
+	for _, entry := range q.Entries {
+		fmt.Fprintf(bw, "D%s\n", entry.Date.Format("01/02/2006"))
+		fmt.Fprintf(bw, "T%s\n", formatQIFAmount(entry.AmountCents))
+		fmt.Fprintf(bw, "P%s\n", qifLineReplacer.Replace(entry.Title))
+		if entry.Note != "" {
+			fmt.Fprintf(bw, "M%s\n", qifLineReplacer.Replace(entry.Note))
+		}
+		if entry.Category != "" {
+			fmt.Fprintf(bw, "L%s\n", qifLineReplacer.Replace(entry.Category))
+		}
+		bw.WriteString("^\n")
+	}
+
+	return bw.Flush()
+}
+
+func formatQIFAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,168 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestParseQIF(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		dateOrder string
+		wantType  string
+		want      []Entry
+	}{
+		{
+			name: "bank account with categories",
+			input: "!Type:Cat\nNFood\nE\n^\n" +
+				"!Type:Bank\n" +
+				"D1/ 3'25\nT-1,045.20\nPSupermarket\nMWeekly shop\nLFood:Groceries/Home\nN101\nCX\n^\n" +
+				"D01/05/2025\nU2,500.00\nT2,500.00\nPSalary\nLIncome\n^\n" +
+				"D01/06/2025\nT-300.00\nMSavings\nL[Savings account]\n^\n",
+			dateOrder: DateOrderMDY,
+			wantType:  QIFTypeBank,
+			want: []Entry{
+				{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -104520, Title: "Supermarket", Note: "Weekly shop", Category: "Food:Groceries"},
+				{Date: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC), AmountCents: 250000, Title: "Salary", Category: "Income"},
+				{Date: time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC), AmountCents: -30000, Title: "Savings"},
+			},
+		},
+		{
+			name: "credit card with day first dates",
+			input: "\xef\xbb\xbf!Account\nNVisa\nTCCard\n^\n" +
+				"!type:CCard\r\n" +
+				"D31.01.98\r\nT-12,50\r\nPBookshop\r\n^\r\n",
+			dateOrder: DateOrderDMY,
+			wantType:  QIFTypeCCard,
+			want: []Entry{
+				{Date: time.Date(1998, time.January, 31, 0, 0, 0, 0, time.UTC), AmountCents: -1250, Title: "Bookshop"},
+			},
+		},
+		{
+			name:      "year first dates",
+			input:     "!Type:Cash\nD2025-02-10\nT-4.00\nPCoffee\n^\n",
+			dateOrder: DateOrderYMD,
+			wantType:  QIFTypeCash,
+			want: []Entry{
+				{Date: time.Date(2025, time.February, 10, 0, 0, 0, 0, time.UTC), AmountCents: -400, Title: "Coffee"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qif, err := ParseQIF(strings.NewReader(tt.input), tt.dateOrder)
+			assert.NilError(t, err)
+			if qif == nil {
+				return
+			}
+
+			assert.Equal(t, qif.Type, tt.wantType)
+			assert.Equal(t, len(qif.Entries), len(tt.want))
+			for i := range min(len(qif.Entries), len(tt.want)) {
+				assert.Equal(t, qif.Entries[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestParseQIF_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:    "empty file",
+			input:   "",
+			wantErr: "file has no bank, cash or credit card transactions",
+		},
+		{
+			name:     "missing header",
+			input:    "D01/03/2025\nT-1.00\nPCoffee\n^\n",
+			wantLine: 1,
+			wantErr:  "missing !Type header",
+		},
+		{
+			name:     "investment account",
+			input:    "!Type:Invst\nD01/03/2025\n^\n",
+			wantLine: 1,
+			wantErr:  `unsupported account type "Invst"`,
+		},
+		{
+			name:     "mixed account types",
+			input:    "!Type:Bank\nD01/03/2025\nT-1.00\nPCoffee\n^\n!Type:CCard\n",
+			wantLine: 6,
+			wantErr:  "file mixes Bank and CCard accounts",
+		},
+		{
+			name:     "invalid date",
+			input:    "!Type:Bank\nD13/01/2025\nT-1.00\nPCoffee\n^\n",
+			wantLine: 2,
+			wantErr:  "invalid date",
+		},
+		{
+			name:     "invalid amount",
+			input:    "!Type:Bank\nD01/03/2025\nT-1.00\nPCoffee\n^\nD01/04/2025\nTabc\nPTea\n^\n",
+			wantLine: 6,
+			wantErr:  "invalid amount",
+		},
+		{
+			name:     "unterminated record",
+			input:    "!Type:Bank\nD01/03/2025\nT-1.00\nPCoffee\n",
+			wantLine: 2,
+			wantErr:  "record is not terminated by ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQIF(strings.NewReader(tt.input), DateOrderMDY)
+			assert.HasError(t, err)
+			if err == nil {
+				return
+			}
+			assert.StringContains(t, err.Error(), tt.wantErr)
+
+			if tt.wantLine > 0 {
+				var lineErr *LineError
+				assert.Equal(t, errors.As(err, &lineErr), true)
+				if lineErr != nil {
+					assert.Equal(t, lineErr.Line, tt.wantLine)
+				}
+			}
+		})
+	}
+}
+
+func TestQIF_Encode(t *testing.T) {
+	qif := &QIF{
+		Type: QIFTypeCCard,
+		Entries: []Entry{
+			{Date: time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), AmountCents: -4520, Title: "Supermarket", Note: "Weekly\nshop", Category: "Food"},
+			{Date: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC), AmountCents: 5, Title: "Interest"},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NilError(t, qif.Encode(&buf))
+
+	want := "!Type:CCard\n" +
+		"D01/03/2025\nT-45.20\nPSupermarket\nMWeekly shop\nLFood\n^\n" +
+		"D01/05/2025\nT0.05\nPInterest\n^\n"
+	assert.Equal(t, buf.String(), want)
+
+	parsed, err := ParseQIF(&buf, DateOrderMDY)
+	assert.NilError(t, err)
+	if parsed != nil {
+		assert.Equal(t, parsed.Type, qif.Type)
+		assert.Equal(t, len(parsed.Entries), 2)
+	}
+}
@@ -36,7 +36,7 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		user, err := m.service.User.GetForToken(token)
+		user, err := m.service.User.GetForToken(service.ScopeAuthentication, token)
 		if err != nil {
 			switch {
 			case errors.Is(err, database.ErrRecordNotFound):
@@ -0,0 +1,381 @@
+// Package recurrence computes the dates on which a recurring transaction is
+// scheduled.
+package recurrence
+
+import (
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+)
+
+// Date truncates t to midnight UTC, which is how occurrence dates are stored.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Schedule yields every date on which rt is scheduled, in order, honouring
+// end_date and max_occurrences. Daily and weekly rules without end may yield
+// forever, so callers must stop iterating on their own.
+func Schedule(rt *store.RecurringTransaction) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if rt.Interval < 1 {
+			return
+		}
+
+		start := Date(rt.StartDate)
+
+		var end time.Time
+		if rt.EndDate != nil {
+			end = Date(*rt.EndDate)
+		}
+
+		count := 0
+		for k := 0; ; k++ {
+			if rt.MaxOccurrences != nil && count >= int(*rt.MaxOccurrences) {
+				return
+			}
+
+			date, ok := candidate(rt, start, k*int(rt.Interval))
+			if !ok {
+				return
+			}
+			if date.Before(start) {
+				continue
+			}
+			if !end.IsZero() && date.After(end) {
+				return
+			}
+
+			count++
+			if !yield(date) {
+				return
+			}
+		}
+	}
+}
+
+// candidate returns the date of the period that is offset periods after the
+// one containing start.
+func candidate(rt *store.RecurringTransaction, start time.Time, offset int) (time.Time, bool) {
+	switch rt.Frequency {
+	case store.RecurrenceFrequencyDaily:
+		return start.AddDate(0, 0, offset), true
+
+	case store.RecurrenceFrequencyWeekly:
+		first := start
+		if rt.DayWeek != nil {
+			shift := (int(*rt.DayWeek) - int(start.Weekday()) + 7) % 7
+			first = start.AddDate(0, 0, shift)
+		}
+		return first.AddDate(0, 0, 7*offset), true
+
+	case store.RecurrenceFrequencyMonthly:
+		day := start.Day()
+		if rt.DayMonth != nil {
+			day = int(*rt.DayMonth)
+		}
+		return clampedDate(start.Year(), start.Month()+time.Month(offset), day), true
+
+	case store.RecurrenceFrequencyYearly:
+		day := start.Day()
+		if rt.DayMonth != nil {
+			day = int(*rt.DayMonth)
+		}
+		return clampedDate(start.Year()+offset, start.Month(), day), true
+
+	default:
+		return time.Time{}, false
+	}
+}
+
+// clampedDate builds a date, moving day back to the last day of the month when
+// the month is too short (e.g. the 31st in April becomes the 30th).
+func clampedDate(year int, month time.Month, day int) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// Next returns up to count scheduled dates on or after from.
+func Next(rt *store.RecurringTransaction, from time.Time, count int) []time.Time {
+	from = Date(from)
+	dates := []time.Time{}
+
+	if count < 1 {
+		return dates
+	}
+
+	for date := range Schedule(rt) {
+		if date.Before(from) {
+			continue
+		}
+		dates = append(dates, date)
+		if len(dates) == count {
+			break
+		}
+	}
+
+	return dates
+}
+
+// Between returns every scheduled date in the inclusive range [from, to].
+func Between(rt *store.RecurringTransaction, from, to time.Time) []time.Time {
+	from, to = Date(from), Date(to)
+	dates := []time.Time{}
+
+	for date := range Schedule(rt) {
+		if date.After(to) {
+			break
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
+
+// Latest returns the last scheduled date on or before t.
+func Latest(rt *store.RecurringTransaction, t time.Time) (time.Time, bool) {
+	t = Date(t)
+
+	var latest time.Time
+	found := false
+
+	for date := range Schedule(rt) {
+		if date.After(t) {
+			break
+		}
+		latest = date
+		found = true
+	}
+
+	return latest, found
+}
+
+// Missed returns the scheduled dates on or before now that come after last,
+// the date of the most recently posted occurrence (nil if none was posted).
+// Dates older than horizon before now are not returned, and neither are the
+// ones before rt was resumed, as the user paused it on purpose. A zero horizon
+// disables catching up, so at most the latest due date is returned.
+func Missed(rt *store.RecurringTransaction, last *time.Time, now time.Time, horizon time.Duration) []time.Time {
+	now = Date(now)
+
+	from := Date(rt.StartDate)
+	if last != nil {
+		from = Date(*last).AddDate(0, 0, 1)
+	}
+	if rt.ResumedAt != nil && from.Before(Date(*rt.ResumedAt)) {
+		from = Date(*rt.ResumedAt)
+	}
+
+	if horizon <= 0 {
+		latest, ok := Latest(rt, now)
+		if !ok || latest.Before(from) {
+			return []time.Time{}
+		}
+		return []time.Time{latest}
+	}
+
+	if oldest := Date(now.Add(-horizon)); from.Before(oldest) {
+		from = oldest
+	}
+
+	return Between(rt, from, now)
+}
+
+// IsScheduled reports whether rt is scheduled on date.
+func IsScheduled(rt *store.RecurringTransaction, date time.Time) bool {
+	date = Date(date)
+
+	for scheduled := range Schedule(rt) {
+		if !scheduled.Before(date) {
+			return scheduled.Equal(date)
+		}
+	}
+
+	return false
+}
+
+// Occurrence is a scheduled date of a recurring transaction once its exception,
+// if any, has been applied.
+type Occurrence struct {
+	ScheduledDate time.Time `json:"scheduled_date"`
+	Date          time.Time `json:"date"`
+	AmountCents   int32     `json:"amount_cents"`
+}
+
+type exceptionsByDate map[time.Time]*store.RecurringTransactionException
+
+func indexExceptions(exceptions []store.RecurringTransactionException) exceptionsByDate {
+	index := make(exceptionsByDate, len(exceptions))
+	for i := range exceptions {
+		index[Date(exceptions[i].OccurrenceDate)] = &exceptions[i]
+	}
+	return index
+}
+
+// apply returns the occurrence scheduled on date, or false if it was skipped.
+func (e exceptionsByDate) apply(rt *store.RecurringTransaction, date time.Time) (Occurrence, bool) {
+	occurrence := Occurrence{
+		ScheduledDate: date,
+		Date:          date,
+		AmountCents:   rt.AmountCents,
+	}
+
+	exception, ok := e[date]
+	if !ok {
+		return occurrence, true
+	}
+	if exception.Skip {
+		return Occurrence{}, false
+	}
+	if exception.NewDate != nil {
+		occurrence.Date = Date(*exception.NewDate)
+	}
+	if exception.AmountCents != nil {
+		occurrence.AmountCents = *exception.AmountCents
+	}
+
+	return occurrence, true
+}
+
+func sortOccurrences(occurrences []Occurrence) {
+	slices.SortFunc(occurrences, func(a, b Occurrence) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return a.ScheduledDate.Compare(b.ScheduledDate)
+	})
+}
+
+// NextOccurrences returns up to count occurrences on or after from, ordered by
+// the date they happen on. Skipped occurrences are left out and moved ones are
+// placed on their new date.
+func NextOccurrences(rt *store.RecurringTransaction, exceptions []store.RecurringTransactionException, from time.Time, count int) []Occurrence {
+	from = Date(from)
+	occurrences := []Occurrence{}
+
+	if count < 1 {
+		return occurrences
+	}
+
+	index := indexExceptions(exceptions)
+
+	// Occurrences scheduled before from may have been moved past it.
+	for date, exception := range index {
+		if date.Before(from) && exception.NewDate != nil && !Date(*exception.NewDate).Before(from) {
+			if occurrence, ok := index.apply(rt, date); ok {
+				occurrences = append(occurrences, occurrence)
+			}
+		}
+	}
+
+	var last time.Time
+	for date := range Schedule(rt) {
+		if date.Before(from) {
+			continue
+		}
+		// An occurrence moved forward can come after later scheduled ones, so
+		// keep going until nothing scheduled can land before the ones found.
+		if len(occurrences) >= count && date.After(last) {
+			break
+		}
+
+		occurrence, ok := index.apply(rt, date)
+		if !ok || occurrence.Date.Before(from) {
+			continue
+		}
+		occurrences = append(occurrences, occurrence)
+		if occurrence.Date.After(last) {
+			last = occurrence.Date
+		}
+	}
+
+	sortOccurrences(occurrences)
+	if len(occurrences) > count {
+		occurrences = occurrences[:count]
+	}
+
+	return occurrences
+}
+
+// OccurrencesBetween returns the occurrences that happen in the inclusive range
+// [from, to], ordered by date, with exceptions applied.
+func OccurrencesBetween(rt *store.RecurringTransaction, exceptions []store.RecurringTransactionException, from, to time.Time) []Occurrence {
+	from, to = Date(from), Date(to)
+
+	index := indexExceptions(exceptions)
+	occurrences := []Occurrence{}
+
+	inRange := func(o Occurrence) bool {
+		return !o.Date.Before(from) && !o.Date.After(to)
+	}
+
+	// Moved occurrences can land in the range from anywhere in the schedule.
+	for date, exception := range index {
+		if exception.NewDate == nil || !IsScheduled(rt, date) {
+			continue
+		}
+		if occurrence, ok := index.apply(rt, date); ok && inRange(occurrence) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	for date := range Schedule(rt) {
+		if date.After(to) {
+			break
+		}
+		if exception, ok := index[date]; ok && exception.NewDate != nil {
+			continue
+		}
+		if occurrence, ok := index.apply(rt, date); ok && inRange(occurrence) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sortOccurrences(occurrences)
+
+	return occurrences
+}
+
+// Due returns the occurrences that should be posted at now: the dates returned
+// by Missed that were not skipped or moved, plus the moved occurrences whose new
+// date falls between now minus horizon and now. Occurrences that were already
+// posted are not filtered out for moved dates, so callers must check for them.
+func Due(rt *store.RecurringTransaction, exceptions []store.RecurringTransactionException, last *time.Time, now time.Time, horizon time.Duration) []Occurrence {
+	now = Date(now)
+	oldest := Date(now.Add(-max(horizon, 0)))
+
+	index := indexExceptions(exceptions)
+	occurrences := []Occurrence{}
+
+	for _, date := range Missed(rt, last, now, horizon) {
+		if exception, ok := index[date]; ok && exception.NewDate != nil {
+			continue
+		}
+		if occurrence, ok := index.apply(rt, date); ok {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	for date, exception := range index {
+		if exception.Skip || exception.NewDate == nil || !IsScheduled(rt, date) {
+			continue
+		}
+		newDate := Date(*exception.NewDate)
+		if newDate.Before(oldest) || newDate.After(now) {
+			continue
+		}
+		if occurrence, ok := index.apply(rt, date); ok {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sortOccurrences(occurrences)
+
+	return occurrences
+}
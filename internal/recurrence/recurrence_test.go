@@ -0,0 +1,683 @@
+package recurrence
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func formatDates(dates []time.Time) string {
+	s := make([]string, len(dates))
+	for i, d := range dates {
+		s[i] = d.Format(time.DateOnly)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  store.RecurringTransaction
+		from  string
+		count int
+		want  string
+	}{
+		{
+			name: "daily",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  1,
+				StartDate: date("2025-01-30"),
+			},
+			from:  "2025-01-01",
+			count: 4,
+			want:  "2025-01-30,2025-01-31,2025-02-01,2025-02-02",
+		},
+		{
+			name: "every third day from a later date",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  3,
+				StartDate: date("2025-01-01"),
+			},
+			from:  "2025-01-05",
+			count: 3,
+			want:  "2025-01-07,2025-01-10,2025-01-13",
+		},
+		{
+			name: "weekly on start weekday",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyWeekly,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-01-01,2025-01-08,2025-01-15",
+		},
+		{
+			name: "weekly on day_week",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyWeekly,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+				DayWeek:   ptr[int32](1),
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-01-06,2025-01-13,2025-01-20",
+		},
+		{
+			name: "biweekly on sunday",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyWeekly,
+				Interval:  2,
+				StartDate: date("2025-01-05"),
+				DayWeek:   ptr[int32](0),
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-01-05,2025-01-19,2025-02-02",
+		},
+		{
+			name: "monthly on start day",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  1,
+				StartDate: date("2025-01-15"),
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-01-15,2025-02-15,2025-03-15",
+		},
+		{
+			name: "monthly day 31 clamps to short months",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+				DayMonth:  ptr[int32](31),
+			},
+			from:  "2025-01-01",
+			count: 5,
+			want:  "2025-01-31,2025-02-28,2025-03-31,2025-04-30,2025-05-31",
+		},
+		{
+			name: "monthly day 29 in leap year",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  1,
+				StartDate: date("2024-01-29"),
+			},
+			from:  "2024-01-01",
+			count: 3,
+			want:  "2024-01-29,2024-02-29,2024-03-29",
+		},
+		{
+			name: "monthly day before start skips first month",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  1,
+				StartDate: date("2025-01-15"),
+				DayMonth:  ptr[int32](1),
+			},
+			from:  "2025-01-01",
+			count: 2,
+			want:  "2025-02-01,2025-03-01",
+		},
+		{
+			name: "quarterly",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  3,
+				StartDate: date("2025-11-30"),
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-11-30,2026-02-28,2026-05-30",
+		},
+		{
+			name: "yearly on leap day",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyYearly,
+				Interval:  1,
+				StartDate: date("2024-02-29"),
+			},
+			from:  "2024-01-01",
+			count: 3,
+			want:  "2024-02-29,2025-02-28,2026-02-28",
+		},
+		{
+			name: "yearly with day_month",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyYearly,
+				Interval:  2,
+				StartDate: date("2025-06-01"),
+				DayMonth:  ptr[int32](20),
+			},
+			from:  "2025-01-01",
+			count: 2,
+			want:  "2025-06-20,2027-06-20",
+		},
+		{
+			name: "end_date is inclusive",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  1,
+				StartDate: date("2025-01-10"),
+				EndDate:   ptr(date("2025-03-10")),
+			},
+			from:  "2025-01-01",
+			count: 10,
+			want:  "2025-01-10,2025-02-10,2025-03-10",
+		},
+		{
+			name: "max_occurrences counts from start",
+			rule: store.RecurringTransaction{
+				Frequency:      store.RecurrenceFrequencyDaily,
+				Interval:       1,
+				StartDate:      date("2025-01-01"),
+				MaxOccurrences: ptr[int32](5),
+			},
+			from:  "2025-01-04",
+			count: 10,
+			want:  "2025-01-04,2025-01-05",
+		},
+		{
+			name: "time of day is ignored",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  1,
+				StartDate: time.Date(2025, time.January, 1, 23, 30, 0, 0, time.UTC),
+			},
+			from:  "2025-01-01",
+			count: 2,
+			want:  "2025-01-01,2025-01-02",
+		},
+		{
+			name: "invalid interval",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				StartDate: date("2025-01-01"),
+			},
+			from:  "2025-01-01",
+			count: 2,
+			want:  "",
+		},
+		{
+			name: "unknown frequency",
+			rule: store.RecurringTransaction{
+				Frequency: "hourly",
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+			},
+			from:  "2025-01-01",
+			count: 2,
+			want:  "",
+		},
+		{
+			name: "zero count",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+			},
+			from:  "2025-01-01",
+			count: 0,
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Next(&tt.rule, date(tt.from), tt.count)
+			assert.Equal(t, formatDates(got), tt.want)
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	rule := store.RecurringTransaction{
+		Frequency: store.RecurrenceFrequencyWeekly,
+		Interval:  1,
+		StartDate: date("2025-01-01"),
+		DayWeek:   ptr[int32](5),
+	}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{"inclusive range", "2025-01-03", "2025-01-17", "2025-01-03,2025-01-10,2025-01-17"},
+		{"before start", "2024-12-01", "2024-12-31", ""},
+		{"single day", "2025-01-10", "2025-01-10", "2025-01-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Between(&rule, date(tt.from), date(tt.to))
+			assert.Equal(t, formatDates(got), tt.want)
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   store.RecurringTransaction
+		now    string
+		want   string
+		wantOK bool
+	}{
+		{
+			name: "latest monthly occurrence",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyMonthly,
+				Interval:  1,
+				StartDate: date("2025-01-31"),
+			},
+			now:    "2025-04-29",
+			want:   "2025-03-31",
+			wantOK: true,
+		},
+		{
+			name: "occurrence today",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+			},
+			now:    "2025-01-10",
+			want:   "2025-01-10",
+			wantOK: true,
+		},
+		{
+			name: "not started",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+			},
+			now:    "2024-12-31",
+			wantOK: false,
+		},
+		{
+			name: "stops at end_date",
+			rule: store.RecurringTransaction{
+				Frequency: store.RecurrenceFrequencyDaily,
+				Interval:  1,
+				StartDate: date("2025-01-01"),
+				EndDate:   ptr(date("2025-01-05")),
+			},
+			now:    "2025-02-01",
+			want:   "2025-01-05",
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Latest(&tt.rule, date(tt.now))
+			assert.Equal(t, ok, tt.wantOK)
+			if tt.wantOK {
+				assert.Equal(t, got.Format(time.DateOnly), tt.want)
+			}
+		})
+	}
+}
+
+func TestMissed(t *testing.T) {
+	daily := store.RecurringTransaction{
+		Frequency: store.RecurrenceFrequencyDaily,
+		Interval:  1,
+		StartDate: date("2025-01-01"),
+	}
+	weekly := store.RecurringTransaction{
+		Frequency: store.RecurrenceFrequencyWeekly,
+		Interval:  1,
+		StartDate: date("2025-01-01"),
+		DayWeek:   ptr[int32](1),
+	}
+	ended := store.RecurringTransaction{
+		Frequency: store.RecurrenceFrequencyDaily,
+		Interval:  1,
+		StartDate: date("2025-01-01"),
+		EndDate:   ptr(date("2025-01-12")),
+	}
+	resumed := store.RecurringTransaction{
+		Frequency: store.RecurrenceFrequencyDaily,
+		Interval:  1,
+		StartDate: date("2025-01-01"),
+		ResumedAt: ptr(date("2025-01-13").Add(15 * time.Hour)),
+	}
+
+	tests := []struct {
+		name    string
+		rule    store.RecurringTransaction
+		last    *time.Time
+		now     string
+		horizon time.Duration
+		want    string
+	}{
+		{
+			name:    "week of downtime",
+			rule:    daily,
+			last:    ptr(date("2025-01-10")),
+			now:     "2025-01-14",
+			horizon: 30 * 24 * time.Hour,
+			want:    "2025-01-11,2025-01-12,2025-01-13,2025-01-14",
+		},
+		{
+			name:    "weekly since last occurrence",
+			rule:    weekly,
+			last:    ptr(date("2025-01-06")),
+			now:     "2025-01-28",
+			horizon: 30 * 24 * time.Hour,
+			want:    "2025-01-13,2025-01-20,2025-01-27",
+		},
+		{
+			name:    "up to date",
+			rule:    daily,
+			last:    ptr(date("2025-01-14")),
+			now:     "2025-01-14",
+			horizon: 30 * 24 * time.Hour,
+			want:    "",
+		},
+		{
+			name:    "never posted starts at start_date",
+			rule:    daily,
+			now:     "2025-01-03",
+			horizon: 30 * 24 * time.Hour,
+			want:    "2025-01-01,2025-01-02,2025-01-03",
+		},
+		{
+			name:    "limited by horizon",
+			rule:    daily,
+			last:    ptr(date("2025-01-01")),
+			now:     "2025-02-01",
+			horizon: 2 * 24 * time.Hour,
+			want:    "2025-01-30,2025-01-31,2025-02-01",
+		},
+		{
+			name:    "rule ended during downtime",
+			rule:    ended,
+			last:    ptr(date("2025-01-09")),
+			now:     "2025-01-20",
+			horizon: 30 * 24 * time.Hour,
+			want:    "2025-01-10,2025-01-11,2025-01-12",
+		},
+		{
+			name:    "paused dates are not caught up",
+			rule:    resumed,
+			last:    ptr(date("2025-01-05")),
+			now:     "2025-01-14",
+			horizon: 30 * 24 * time.Hour,
+			want:    "2025-01-13,2025-01-14",
+		},
+		{
+			name:    "resumed before last occurrence",
+			rule:    resumed,
+			last:    ptr(date("2025-01-13")),
+			now:     "2025-01-14",
+			horizon: 30 * 24 * time.Hour,
+			want:    "2025-01-14",
+		},
+		{
+			name: "no horizon posts latest only",
+			rule: daily,
+			last: ptr(date("2025-01-10")),
+			now:  "2025-01-14",
+			want: "2025-01-14",
+		},
+		{
+			name: "no horizon and already posted",
+			rule: weekly,
+			last: ptr(date("2025-01-13")),
+			now:  "2025-01-15",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Missed(&tt.rule, tt.last, date(tt.now), tt.horizon)
+			assert.Equal(t, formatDates(got), tt.want)
+		})
+	}
+}
+
+func formatOccurrences(occurrences []Occurrence) string {
+	s := make([]string, len(occurrences))
+	for i, o := range occurrences {
+		s[i] = fmt.Sprintf("%s:%s:%d", o.ScheduledDate.Format(time.DateOnly), o.Date.Format(time.DateOnly), o.AmountCents)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestIsScheduled(t *testing.T) {
+	rule := store.RecurringTransaction{
+		Frequency: store.RecurrenceFrequencyMonthly,
+		Interval:  1,
+		StartDate: date("2025-01-31"),
+	}
+
+	assert.Equal(t, IsScheduled(&rule, date("2025-01-31")), true)
+	assert.Equal(t, IsScheduled(&rule, date("2025-02-28")), true)
+	assert.Equal(t, IsScheduled(&rule, date("2025-03-30")), false)
+	assert.Equal(t, IsScheduled(&rule, date("2024-12-31")), false)
+}
+
+func TestNextOccurrences(t *testing.T) {
+	rule := store.RecurringTransaction{
+		Frequency:   store.RecurrenceFrequencyMonthly,
+		Interval:    1,
+		StartDate:   date("2025-01-01"),
+		AmountCents: -100,
+	}
+
+	tests := []struct {
+		name       string
+		exceptions []store.RecurringTransactionException
+		from       string
+		count      int
+		want       string
+	}{
+		{
+			name:  "no exceptions",
+			from:  "2025-01-01",
+			count: 2,
+			want:  "2025-01-01:2025-01-01:-100,2025-02-01:2025-02-01:-100",
+		},
+		{
+			name: "skip",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-02-01"), Skip: true},
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-01-01:2025-01-01:-100,2025-03-01:2025-03-01:-100,2025-04-01:2025-04-01:-100",
+		},
+		{
+			name: "override amount",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-01"), AmountCents: ptr[int32](-250)},
+			},
+			from:  "2025-01-01",
+			count: 2,
+			want:  "2025-01-01:2025-01-01:-250,2025-02-01:2025-02-01:-100",
+		},
+		{
+			name: "moved past later occurrences",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-01"), NewDate: ptr(date("2025-02-15"))},
+			},
+			from:  "2025-01-01",
+			count: 3,
+			want:  "2025-02-01:2025-02-01:-100,2025-01-01:2025-02-15:-100,2025-03-01:2025-03-01:-100",
+		},
+		{
+			name: "moved from before from",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-01"), NewDate: ptr(date("2025-01-20"))},
+			},
+			from:  "2025-01-10",
+			count: 2,
+			want:  "2025-01-01:2025-01-20:-100,2025-02-01:2025-02-01:-100",
+		},
+		{
+			name: "moved before from",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-02-01"), NewDate: ptr(date("2025-01-05"))},
+			},
+			from:  "2025-01-10",
+			count: 1,
+			want:  "2025-03-01:2025-03-01:-100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextOccurrences(&rule, tt.exceptions, date(tt.from), tt.count)
+			assert.Equal(t, formatOccurrences(got), tt.want)
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	rule := store.RecurringTransaction{
+		Frequency:   store.RecurrenceFrequencyDaily,
+		Interval:    1,
+		StartDate:   date("2025-01-01"),
+		AmountCents: -100,
+	}
+	horizon := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		exceptions []store.RecurringTransactionException
+		last       *time.Time
+		now        string
+		want       string
+	}{
+		{
+			name: "skip and override",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-02"), Skip: true},
+				{OccurrenceDate: date("2025-01-03"), AmountCents: ptr[int32](-5)},
+			},
+			last: ptr(date("2025-01-01")),
+			now:  "2025-01-03",
+			want: "2025-01-03:2025-01-03:-5",
+		},
+		{
+			name: "moved later is not due yet",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-02"), NewDate: ptr(date("2025-01-05"))},
+			},
+			last: ptr(date("2025-01-01")),
+			now:  "2025-01-03",
+			want: "2025-01-03:2025-01-03:-100",
+		},
+		{
+			name: "moved occurrence becomes due after last",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-02"), NewDate: ptr(date("2025-01-05"))},
+			},
+			last: ptr(date("2025-01-04")),
+			now:  "2025-01-05",
+			want: "2025-01-02:2025-01-05:-100,2025-01-05:2025-01-05:-100",
+		},
+		{
+			name: "moved earlier",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2025-01-10"), NewDate: ptr(date("2025-01-03")), AmountCents: ptr[int32](-7)},
+			},
+			last: ptr(date("2025-01-02")),
+			now:  "2025-01-03",
+			want: "2025-01-03:2025-01-03:-100,2025-01-10:2025-01-03:-7",
+		},
+		{
+			name: "exception for a date that is not scheduled",
+			exceptions: []store.RecurringTransactionException{
+				{OccurrenceDate: date("2024-12-31"), NewDate: ptr(date("2025-01-03"))},
+			},
+			last: ptr(date("2025-01-02")),
+			now:  "2025-01-03",
+			want: "2025-01-03:2025-01-03:-100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Due(&rule, tt.exceptions, tt.last, date(tt.now), horizon)
+			assert.Equal(t, formatOccurrences(got), tt.want)
+		})
+	}
+}
+
+func TestOccurrencesBetween(t *testing.T) {
+	rule := store.RecurringTransaction{
+		Frequency:   store.RecurrenceFrequencyWeekly,
+		Interval:    1,
+		StartDate:   date("2025-01-01"),
+		DayWeek:     ptr[int32](3),
+		AmountCents: -100,
+	}
+	exceptions := []store.RecurringTransactionException{
+		{OccurrenceDate: date("2025-01-08"), Skip: true},
+		{OccurrenceDate: date("2025-01-15"), AmountCents: ptr[int32](-300)},
+		{OccurrenceDate: date("2025-01-29"), NewDate: ptr(date("2025-01-20"))},
+		{OccurrenceDate: date("2025-01-22"), NewDate: ptr(date("2025-02-03"))},
+	}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "exceptions applied",
+			from: "2025-01-01",
+			to:   "2025-01-25",
+			want: "2025-01-01:2025-01-01:-100,2025-01-15:2025-01-15:-300,2025-01-29:2025-01-20:-100",
+		},
+		{
+			name: "moved out of range",
+			from: "2025-01-21",
+			to:   "2025-01-31",
+			want: "",
+		},
+		{
+			name: "moved into range",
+			from: "2025-02-01",
+			to:   "2025-02-05",
+			want: "2025-01-22:2025-02-03:-100,2025-02-05:2025-02-05:-100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OccurrencesBetween(&rule, exceptions, date(tt.from), date(tt.to))
+			assert.Equal(t, formatOccurrences(got), tt.want)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Quak1/gokei/internal/service"
+)
+
+// Scheduler periodically materializes due recurring transactions into
+// regular transactions.
+type Scheduler struct {
+	recurringService *service.RecurringTransactionService
+	logger           *slog.Logger
+	interval         time.Duration
+	backfill         time.Duration
+}
+
+// New creates a scheduler that runs every interval. Occurrences missed while
+// the server was down are posted as long as they're not older than backfill.
+func New(svc *service.RecurringTransactionService, logger *slog.Logger, interval, backfill time.Duration) *Scheduler {
+	return &Scheduler{
+		recurringService: svc,
+		logger:           logger,
+		interval:         interval,
+		backfill:         backfill,
+	}
+}
+
+// Start runs the scheduler in the background until ctx is cancelled. The first
+// run happens immediately.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.run()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) run() {
+	occurrences, err := s.recurringService.PostDue(time.Now(), s.backfill)
+	if err != nil {
+		s.logger.Error("failed to post recurring transactions", "error", err.Error())
+	}
+
+	for _, occurrence := range occurrences {
+		s.logger.Info("posted recurring transaction",
+			"recurring_transaction_id", occurrence.RecurringTransactionID,
+			"transaction_id", *occurrence.TransactionID,
+			"occurrence_date", occurrence.OccurrenceDate.Format(time.DateOnly),
+		)
+	}
+}
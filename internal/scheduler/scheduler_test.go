@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/service"
+	"github.com/Quak1/gokei/internal/testutils"
+	"github.com/Quak1/gokei/pkg/assert"
+)
+
+func TestScheduler_Run(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+	defer cleanup()
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	recurring := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+
+	s := New(svc.Recurring, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour, 0)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(s.run)
+	}
+	wg.Wait()
+
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(transactions), 2)
+
+	updatedAccount, err := svc.Account.GetByID(account.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, updatedAccount.BalanceCents, account.BalanceCents+int64(recurring.AmountCents))
+
+	s.run()
+
+	transactions, err = svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(transactions), 2)
+}
+
+func TestScheduler_DeletedOccurrence(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+	defer cleanup()
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+
+	now := time.Date(2025, time.April, 15, 0, 0, 0, 0, time.UTC)
+	backfill := 60 * 24 * time.Hour
+
+	occurrences, err := svc.Recurring.PostDue(now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(occurrences), 1)
+	if len(occurrences) != 1 {
+		return
+	}
+
+	err = svc.Transaction.DeleteByID(*occurrences[0].TransactionID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the occurrence, the deleted date and the ones before it within
+	// the backfill would be posted.
+	occurrences, err = svc.Recurring.PostDue(now, backfill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(occurrences), 0)
+
+	// Only the initial balance is left.
+	transactions, err := svc.Transaction.GetAllTRansactionsForAccountID(account.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(transactions), 1)
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup, err := testutils.NewTestDB()
+	if err != nil {
+		t.Fatalf("test db setup failed: %v", err)
+	}
+	defer cleanup()
+
+	svc := service.New(db, blob.NewLocalStore(t.TempDir()))
+
+	user := testutils.CreateTestUser(t, svc.User, "testuser")
+	account := testutils.CreateTestAccount(t, svc.Account, user.ID)
+	category := testutils.CreateTestCategory(t, svc.Category, user.ID)
+	recurring := testutils.CreateTestRecurringTransaction(t, svc.Recurring, user.ID, account.ID, category.ID)
+
+	backfill := 60 * 24 * time.Hour
+
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	exceptions := []service.CreateRecurringTransactionExceptionParams{
+		{OccurrenceDate: date(time.March, 1), Skip: true},
+		{OccurrenceDate: date(time.May, 1), NewDate: ptr(date(time.May, 20))},
+	}
+	for _, exception := range exceptions {
+		_, err := svc.Recurring.CreateException(user.ID, recurring.ID, &exception)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{
+		{"limited by backfill", date(time.April, 15), []string{"2025-04-01"}},
+		{"nothing new", date(time.April, 20), []string{}},
+		{"moved occurrence not due", date(time.May, 10), []string{}},
+		{"from last occurrence", date(time.June, 2), []string{"2025-05-20", "2025-06-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences, err := svc.Recurring.PostDue(tt.now, backfill)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, len(occurrences), len(tt.want))
+			for i, occurrence := range occurrences {
+				assert.Equal(t, occurrence.RecurringTransactionID, recurring.ID)
+
+				transaction, err := svc.Transaction.GetByID(*occurrence.TransactionID, user.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, transaction.Date.Format(time.DateOnly), tt.want[i])
+			}
+		})
+	}
+
+	updatedAccount, err := svc.Account.GetByID(account.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, updatedAccount.BalanceCents, account.BalanceCents+3*int64(recurring.AmountCents))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/pkg/validator"
@@ -14,12 +14,14 @@ import (
 type AccountService struct {
 	queries store.QuerierTx
 	DB      *sql.DB
+	blobs   blob.Store
 }
 
-func NewAccountService(queries store.QuerierTx, db *sql.DB) *AccountService {
+func NewAccountService(queries store.QuerierTx, db *sql.DB, blobs blob.Store) *AccountService {
 	return &AccountService{
 		queries: queries,
 		DB:      db,
+		blobs:   blobs,
 	}
 }
 
@@ -114,7 +116,26 @@ func (s *AccountService) DeleteByID(accountID, userID int32) error {
 		return database.ErrRecordNotFound
 	}
 
-	result, err := s.queries.DeleteAccountById(context.Background(), store.DeleteAccountByIdParams{
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	// The attachments go along with the transactions, but their files have
+	// to be removed once the rows are gone.
+	attachmentKeys, err := qtx.GetAccountAttachmentKeys(ctx, store.GetAccountAttachmentKeysParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := qtx.DeleteAccountById(ctx, store.DeleteAccountByIdParams{
 		ID:     accountID,
 		UserID: userID,
 	})
@@ -131,6 +152,13 @@ func (s *AccountService) DeleteByID(accountID, userID int32) error {
 		return database.ErrRecordNotFound
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
 	return nil
 }
 
@@ -215,6 +243,8 @@ func (s *AccountService) UpdateByID(accountID, userID int32, updateParams *Updat
 	return &account, nil
 }
 
+// TransferParams is the older way of making a transfer, from the account in
+// the path. TransferService.Create also takes a fee.
 type TransferParams struct {
 	AmountCents int64 `json:"amount"`
 	RecipientID int32 `json:"recipient_id"`
@@ -239,72 +269,10 @@ func (s *AccountService) TransferByID(userID, accountID int32, params *TransferP
 	}
 	defer tx.Rollback()
 
-	qtx := s.queries.WithTx(tx)
-	ctx := context.Background()
-
-	senderAccount, err := qtx.GetAccountByID(ctx, store.GetAccountByIDParams{
-		ID:     accountID,
-		UserID: userID,
-	})
-	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return nil, database.ErrRecordNotFound
-		default:
-			return nil, err
-		}
-	}
-
-	recipientAccount, err := qtx.GetAccountByID(ctx, store.GetAccountByIDParams{
-		ID:     params.RecipientID,
-		UserID: userID,
-	})
-	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return nil, database.ErrRecordNotFound
-		default:
-			return nil, err
-		}
-	}
-
-	title := fmt.Sprintf("[TRANSFER] FROM '%s' TO '%s'", senderAccount.Name, recipientAccount.Name)
-
-	sendTransaction, err := qtx.CreateTransaction(ctx, store.CreateTransactionParams{
-		AccountID:   senderAccount.ID,
-		AmountCents: -params.AmountCents,
-		CategoryID:  database.InitialCategoryID(),
-		Title:       title,
-		Attachment:  "",
-		Note:        "",
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
-		ID:     sendTransaction.AccountID,
-		UserID: userID,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	receiveTransaction, err := qtx.CreateTransaction(ctx, store.CreateTransactionParams{
-		AccountID:   recipientAccount.ID,
-		AmountCents: params.AmountCents,
-		CategoryID:  database.InitialCategoryID(),
-		Title:       title,
-		Attachment:  "",
-		Note:        "",
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
-		ID:     receiveTransaction.AccountID,
-		UserID: userID,
+	transfer, err := createTransfer(context.Background(), s.queries.WithTx(tx), userID, &CreateTransferParams{
+		FromAccountID: accountID,
+		ToAccountID:   params.RecipientID,
+		AmountCents:   params.AmountCents,
 	})
 	if err != nil {
 		return nil, err
@@ -315,5 +283,5 @@ func (s *AccountService) TransferByID(userID, accountID int32, params *TransferP
 		return nil, err
 	}
 
-	return &sendTransaction, nil
+	return &transfer.From, nil
 }
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+const (
+	MaxAttachmentBytes           = 10 << 20
+	MaxAttachmentsPerTransaction = 20
+)
+
+// attachmentContentTypes are the types attachments may have, as sniffed from
+// their content. Receipts are photos or PDFs, and serving anything that a
+// browser could run as a page is avoided.
+var attachmentContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+}
+
+type AttachmentService struct {
+	queries store.QuerierTx
+	DB      *sql.DB
+	blobs   blob.Store
+}
+
+func NewAttachmentService(queries store.QuerierTx, db *sql.DB, blobs blob.Store) *AttachmentService {
+	return &AttachmentService{
+		queries: queries,
+		DB:      db,
+		blobs:   blobs,
+	}
+}
+
+// AttachmentUpload is a file to attach, as received from the client. Size is
+// the size the client declared, which is checked before reading Content.
+type AttachmentUpload struct {
+	Filename string
+	Size     int64
+	Content  io.Reader
+}
+
+func validateAttachmentUploads(v *validator.Validator, uploads []AttachmentUpload) {
+	v.Check(len(uploads) > 0, "file", "Must be provided")
+
+	for _, upload := range uploads {
+		v.Check(upload.Size > 0, "file", "Must not be empty")
+		v.Check(upload.Size <= MaxAttachmentBytes, "file", fmt.Sprintf("Must not be larger than %d bytes", MaxAttachmentBytes))
+		v.Check(validator.MaxLength(upload.Filename, 255), "file", "Filename must not be more than 255 bytes long")
+	}
+}
+
+// Create stores the uploads and attaches them to the transaction. The type of
+// each file is worked out from its first bytes rather than trusted from the
+// client.
+func (s *AttachmentService) Create(userID, transactionID int32, uploads []AttachmentUpload) ([]store.TransactionAttachment, error) {
+	v := validator.New()
+	if validateAttachmentUploads(v, uploads); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	readers := make([]*bufio.Reader, len(uploads))
+	contentTypes := make([]string, len(uploads))
+	for i, upload := range uploads {
+		readers[i] = bufio.NewReaderSize(io.LimitReader(upload.Content, MaxAttachmentBytes), 512)
+
+		// Peek returns what there is along with io.EOF for files shorter than
+		// what DetectContentType looks at.
+		head, err := readers[i].Peek(512)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		contentTypes[i], _, _ = mime.ParseMediaType(http.DetectContentType(head))
+		if !validator.PermittedValue(contentTypes[i], attachmentContentTypes...) {
+			v.AddError("file", "Must be a JPEG, PNG, GIF or WebP image, or a PDF")
+			return nil, v.GetErrors()
+		}
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	err = s.checkTransaction(ctx, qtx, userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := qtx.GetTransactionAttachments(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing)+len(uploads) > MaxAttachmentsPerTransaction {
+		v.AddError("file", fmt.Sprintf("A transaction must not have more than %d attachments", MaxAttachmentsPerTransaction))
+		return nil, v.GetErrors()
+	}
+
+	// The files are written before the rows, so they are removed again if
+	// anything fails before the rows are committed.
+	var keys []string
+	committed := false
+	defer func() {
+		if !committed {
+			removeBlobs(s.blobs, keys)
+		}
+	}()
+
+	attachments := make([]store.TransactionAttachment, len(uploads))
+	for i, upload := range uploads {
+		key, err := newBlobKey()
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.blobs.Put(ctx, key, readers[i])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		attachments[i], err = qtx.CreateTransactionAttachment(ctx, store.CreateTransactionAttachmentParams{
+			TransactionID: transactionID,
+			Filename:      attachmentFilename(upload.Filename),
+			ContentType:   contentTypes[i],
+			SizeBytes:     upload.Size,
+			StorageKey:    key,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	committed = true
+
+	return attachments, nil
+}
+
+func (s *AttachmentService) GetAll(userID, transactionID int32) ([]store.TransactionAttachment, error) {
+	ctx := context.Background()
+
+	err := s.checkTransaction(ctx, s.queries, userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.queries.GetTransactionAttachments(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return orEmpty(attachments), nil
+}
+
+// Open returns the attachment along with its content, which the caller must
+// close.
+func (s *AttachmentService) Open(userID, transactionID, attachmentID int32) (*store.TransactionAttachment, io.ReadCloser, error) {
+	ctx := context.Background()
+
+	err := s.checkTransaction(ctx, s.queries, userID, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.queries.GetTransactionAttachmentByID(ctx, store.GetTransactionAttachmentByIDParams{
+		ID:            attachmentID,
+		TransactionID: transactionID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, database.ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	content, err := s.blobs.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &attachment, content, nil
+}
+
+func (s *AttachmentService) DeleteByID(userID, transactionID, attachmentID int32) error {
+	ctx := context.Background()
+
+	err := s.checkTransaction(ctx, s.queries, userID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.queries.DeleteTransactionAttachment(ctx, store.DeleteTransactionAttachmentParams{
+		ID:            attachmentID,
+		TransactionID: transactionID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return database.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	removeBlobs(s.blobs, []string{key})
+
+	return nil
+}
+
+// checkTransaction makes sure the transaction exists and belongs to the user.
+func (s *AttachmentService) checkTransaction(ctx context.Context, q store.Querier, userID, transactionID int32) error {
+	_, err := q.GetTransactionByID(ctx, store.GetTransactionByIDParams{
+		ID:     transactionID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return database.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attachmentFilename keeps the name of the file without any directories the
+// client may have sent along with it.
+func attachmentFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
+
+func newBlobKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// removeBlobs deletes the files of rows that are already gone. It is best
+// effort: the rows are what the API serves, so a file left behind only takes
+// up space.
+func removeBlobs(blobs blob.Store, keys []string) {
+	for _, key := range keys {
+		_ = blobs.Delete(context.Background(), key)
+	}
+}
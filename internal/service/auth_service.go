@@ -68,7 +68,7 @@ func (s *AuthService) CreateAuthToken(username, password string) (*Token, error)
 		return nil, ErrInvalidCredentials
 	}
 
-	token, err := s.tokenService.New(int(user.ID), 24*time.Hour)
+	token, err := s.tokenService.New(int(user.ID), 24*time.Hour, ScopeAuthentication)
 	if err != nil {
 		return nil, err
 	}
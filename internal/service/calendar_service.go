@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/ical"
+	"github.com/Quak1/gokei/internal/recurrence"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+const calendarTokenTTL = 365 * 24 * time.Hour
+
+type CalendarService struct {
+	queries      store.QuerierTx
+	tokenService *TokenService
+	userService  *UserService
+}
+
+func NewCalendarService(queries store.QuerierTx, tokenService *TokenService, userService *UserService) *CalendarService {
+	return &CalendarService{
+		queries:      queries,
+		tokenService: tokenService,
+		userService:  userService,
+	}
+}
+
+// CreateToken creates a long-lived token that only grants access to the user's
+// calendar feed, so it can be given to calendar clients.
+func (s *CalendarService) CreateToken(userID int32) (*Token, error) {
+	return s.tokenService.New(int(userID), calendarTokenTTL, ScopeCalendar)
+}
+
+func validateCalendarDays(v *validator.Validator, days int) {
+	v.Check(days > 0, "days", "Must be greater than zero")
+	v.Check(days <= 366, "days", "Must not be more than 366")
+}
+
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Feed returns the occurrences of the active recurring transactions of the
+// token's owner from today until days from now, as calendar events.
+func (s *CalendarService) Feed(token string, today time.Time, days int) (*ical.Calendar, error) {
+	v := validator.New()
+	if ValidateTokenPlaintext(v, token); !v.Valid() {
+		return nil, database.ErrRecordNotFound
+	}
+	if validateCalendarDays(v, days); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	user, err := s.userService.GetForToken(ScopeCalendar, token)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	transactions, err := s.queries.GetUserRecurringTransactions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.queries.GetUserAccounts(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	accountNames := make(map[int32]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.Name
+	}
+
+	categories, err := s.queries.GetAllCategories(ctx, store.GetAllCategoriesParams{
+		AdminID: database.AdminUserID(),
+		UserID:  user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[int32]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	from := recurrence.Date(today)
+	to := from.AddDate(0, 0, days)
+
+	calendar := &ical.Calendar{
+		Name:  "gokei bills",
+		Stamp: today,
+	}
+
+	for _, transaction := range transactions {
+		if !transaction.IsActive {
+			continue
+		}
+
+		exceptions, err := s.queries.GetRecurringTransactionExceptions(ctx, transaction.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, occurrence := range recurrence.OccurrencesBetween(&transaction, exceptions, from, to) {
+			description := []string{
+				"Amount: " + formatCents(int64(occurrence.AmountCents)),
+				"Account: " + accountNames[transaction.AccountID],
+				"Category: " + categoryNames[transaction.CategoryID],
+			}
+			if transaction.Note != "" {
+				description = append(description, "", transaction.Note)
+			}
+
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:         fmt.Sprintf("recurring-%d-%s@gokei", transaction.ID, occurrence.ScheduledDate.Format("20060102")),
+				Date:        occurrence.Date,
+				Summary:     transaction.Title,
+				Description: strings.Join(description, "\n"),
+			})
+		}
+	}
+
+	return calendar, nil
+}
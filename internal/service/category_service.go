@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
@@ -104,6 +105,9 @@ func (s *CategoryService) DeleteByID(userID, categoryID int32) error {
 		UserID: userID,
 	})
 	if err != nil {
+		if database.IsForeignKeyViolation(err, "transaction_splits_category_id_fkey") {
+			return database.ErrCategoryInUse
+		}
 		return err
 	}
 
@@ -186,3 +190,37 @@ func (s *CategoryService) UpdateByID(userId, categoryID int32, updateParams *Upd
 
 	return &category, nil
 }
+
+// Report totals the user's transactions per category between from and to,
+// both inclusive and optional. Split transactions count towards the
+// categories of their lines instead of their own.
+func (s *CategoryService) Report(userID int32, from, to time.Time) ([]store.GetCategoryReportRow, error) {
+	v := validator.New()
+	if !from.IsZero() && !to.IsZero() {
+		v.Check(!to.Before(from), "date_to", "Must not be before date_from")
+	}
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	arg := store.GetCategoryReportParams{
+		UserID:            userID,
+		InitialCategoryID: database.InitialCategoryID(),
+	}
+	if !from.IsZero() {
+		arg.DateFrom = sql.NullTime{Time: from, Valid: true}
+	}
+	if !to.IsZero() {
+		arg.DateTo = sql.NullTime{Time: to.AddDate(0, 0, 1), Valid: true}
+	}
+
+	report, err := s.queries.GetCategoryReport(context.Background(), arg)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		report = []store.GetCategoryReportRow{}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Quak1/gokei/internal/database/store"
+)
+
+// DuplicateWindowDays is how many days apart a transaction and a probable
+// duplicate of it may be dated.
+const DuplicateWindowDays = 3
+
+// duplicateCheck describes a transaction to look for probable duplicates of.
+type duplicateCheck struct {
+	AmountCents int64
+	Date        time.Time
+	Title       string
+}
+
+// findDuplicates returns, for each check, the transactions of the account with
+// the same amount, a date within DuplicateWindowDays and a similar title,
+// closest date first. The transaction with excludeID, if any, is never
+// reported.
+func findDuplicates(ctx context.Context, q store.Querier, accountID int32, checks []duplicateCheck, excludeID int32) ([][]store.Transaction, error) {
+	duplicates := make([][]store.Transaction, len(checks))
+	if len(checks) == 0 {
+		return duplicates, nil
+	}
+
+	dateFrom, dateTo := checks[0].Date, checks[0].Date
+	amounts := make([]int64, 0, len(checks))
+	for _, check := range checks {
+		if check.Date.Before(dateFrom) {
+			dateFrom = check.Date
+		}
+		if check.Date.After(dateTo) {
+			dateTo = check.Date
+		}
+		amounts = append(amounts, check.AmountCents)
+	}
+
+	candidates, err := q.GetDuplicateCandidates(ctx, store.GetDuplicateCandidatesParams{
+		AccountID: accountID,
+		DateFrom:  startOfDay(dateFrom).AddDate(0, 0, -DuplicateWindowDays),
+		DateTo:    startOfDay(dateTo).AddDate(0, 0, DuplicateWindowDays+1),
+		Amounts:   amounts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, check := range checks {
+		for _, candidate := range candidates {
+			if candidate.ID == excludeID || candidate.AmountCents != check.AmountCents {
+				continue
+			}
+			if daysApart(candidate.Date, check.Date) > DuplicateWindowDays {
+				continue
+			}
+			if !similarTitles(candidate.Title, check.Title) {
+				continue
+			}
+			duplicates[i] = append(duplicates[i], candidate)
+		}
+
+		slices.SortStableFunc(duplicates[i], func(a, b store.Transaction) int {
+			return daysApart(a.Date, check.Date) - daysApart(b.Date, check.Date)
+		})
+	}
+
+	return duplicates, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysApart counts the calendar days between a and b, ignoring the time of day.
+func daysApart(a, b time.Time) int {
+	days := int(startOfDay(a).Sub(startOfDay(b)).Hours() / 24)
+	return max(days, -days)
+}
+
+// similarTitles reports whether two titles likely describe the same
+// transaction. Case, punctuation and spacing are ignored, and one title may
+// be a shortened form of the other, as banks often truncate or append to the
+// merchant's name. Otherwise at least half of their words must be shared.
+func similarTitles(a, b string) bool {
+	wordsA, wordsB := titleWords(a), titleWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return false
+	}
+
+	joinedA, joinedB := strings.Join(wordsA, ""), strings.Join(wordsB, "")
+	if joinedA == joinedB {
+		return true
+	}
+
+	shorter, longer := joinedA, joinedB
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) >= 4 && strings.Contains(longer, shorter) {
+		return true
+	}
+
+	shared := 0
+	for _, word := range wordsA {
+		if slices.Contains(wordsB, word) {
+			shared++
+		}
+	}
+	union := len(wordsA) + len(wordsB) - shared
+
+	return shared*2 >= union
+}
+
+// titleWords returns the distinct words of a title, in order and lowercase.
+func titleWords(title string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !slices.Contains(words, field) {
+			words = append(words, field)
+		}
+	}
+	return words
+}
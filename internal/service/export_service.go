@@ -0,0 +1,165 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/importer"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+type ExportService struct {
+	queries store.QuerierTx
+	DB      *sql.DB
+}
+
+func NewExportService(queries store.QuerierTx, db *sql.DB) *ExportService {
+	return &ExportService{
+		queries: queries,
+		DB:      db,
+	}
+}
+
+// QIF returns the transactions of an account as a QIF file, oldest first. The
+// legs of a transfer are written as transfers to the other account, and the
+// initial balance as a transfer from the account itself, the way desktop
+// tools write opening balances. Reconciliation adjustments have no category.
+func (s *ExportService) QIF(userID, accountID int32) (*importer.QIF, error) {
+	ctx := context.Background()
+
+	account, err := s.queries.GetAccountByID(ctx, store.GetAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	rows, err := s.queries.GetTransactionsByAccountID(ctx, store.GetTransactionsByAccountIDParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.queries.GetAllCategories(ctx, store.GetAllCategoriesParams{
+		AdminID: database.AdminUserID(),
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	categoryNames := make(map[int32]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+	categoryNames[database.InitialCategoryID()] = "[" + account.Name + "]"
+
+	// Transfer legs and reconciliation adjustments share the initial
+	// category with the initial balance, so they are told apart by ID.
+	transferNames, err := s.queries.GetTransferAccountNames(ctx, store.GetTransferAccountNamesParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations, err := s.queries.ListReconciliations(ctx, store.ListReconciliationsParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transactionCategories := make(map[int32]string, len(transferNames)+len(reconciliations))
+	for _, leg := range transferNames {
+		transactionCategories[leg.TransactionID] = "[" + leg.AccountName + "]"
+	}
+	for _, reconciliation := range reconciliations {
+		if reconciliation.AdjustmentTransactionID != nil {
+			transactionCategories[*reconciliation.AdjustmentTransactionID] = ""
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b store.GetTransactionsByAccountIDRow) int {
+		return cmp.Or(a.Transaction.Date.Compare(b.Transaction.Date), cmp.Compare(a.Transaction.ID, b.Transaction.ID))
+	})
+
+	qif := &importer.QIF{Entries: make([]importer.Entry, len(rows))}
+	for qifType, accountType := range qifAccountTypes {
+		if accountType == account.Type {
+			qif.Type = qifType
+		}
+	}
+
+	for i, row := range rows {
+		category, ok := transactionCategories[row.Transaction.ID]
+		if !ok {
+			category = categoryNames[row.Transaction.CategoryID]
+		}
+
+		qif.Entries[i] = importer.Entry{
+			Date:        row.Transaction.Date,
+			AmountCents: row.Transaction.AmountCents,
+			Title:       row.Transaction.Title,
+			Note:        row.Transaction.Note,
+			Category:    category,
+		}
+	}
+
+	return qif, nil
+}
+
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+type ExportParams struct {
+	Format   string
+	DateFrom time.Time
+	DateTo   time.Time
+}
+
+func validateExport(v *validator.Validator, params *ExportParams) {
+	v.Check(validator.PermittedValue(params.Format, ExportFormatJSON, ExportFormatCSV), "format", "Invalid format. Valid values are json and csv")
+
+	if !params.DateFrom.IsZero() && !params.DateTo.IsZero() {
+		v.Check(!params.DateTo.Before(params.DateFrom), "date_to", "Must not be before date_from")
+	}
+}
+
+// Export returns all of the user's data, ready to be written out in the
+// requested format. The date range only limits the transactions; accounts,
+// categories, tags and recurring transactions are always exported whole.
+func (s *ExportService) Export(userID int32, params *ExportParams) (*Export, error) {
+	v := validator.New()
+	if validateExport(v, params); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	return &Export{
+		Format:     params.Format,
+		ExportedAt: time.Now().UTC(),
+		DateFrom:   params.DateFrom,
+		DateTo:     params.DateTo,
+		queries:    s.queries,
+		db:         s.DB,
+		userID:     userID,
+	}, nil
+}
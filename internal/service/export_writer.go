@@ -0,0 +1,589 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+)
+
+// ExportVersion is the version of the JSON export document. It changes when
+// fields are removed or change meaning, not when fields are added.
+const ExportVersion = 1
+
+// exportPageSize is how many transactions are read at a time while writing an
+// export, which bounds the memory an export uses however large it is.
+const exportPageSize = 500
+
+// Export is a user's data waiting to be written. Nothing is read until Encode
+// is called, and transactions are read a page at a time while writing.
+type Export struct {
+	Format     string
+	ExportedAt time.Time
+	DateFrom   time.Time
+	DateTo     time.Time
+
+	queries store.QuerierTx
+	db      *sql.DB
+	userID  int32
+}
+
+// exportData holds everything but the transactions, which are few enough to
+// keep in memory.
+type exportData struct {
+	Accounts                   []store.Account                        `json:"accounts"`
+	Categories                 []store.Category                       `json:"categories"`
+	Tags                       []store.Tag                            `json:"tags"`
+	RecurringTransactions      []store.RecurringTransaction           `json:"recurring_transactions"`
+	RecurringExceptions        []store.RecurringTransactionException  `json:"recurring_transaction_exceptions"`
+	RecurringOccurrences       []store.RecurringTransactionOccurrence `json:"recurring_transaction_occurrences"`
+	TransactionRefunds         []store.TransactionRefund              `json:"transaction_refunds"`
+	Transfers                  []store.Transfer                       `json:"transfers"`
+	Reconciliations            []store.Reconciliation                 `json:"reconciliations"`
+	ReconciliationTransactions []store.ReconciliationTransaction      `json:"reconciliation_transactions"`
+}
+
+// exportTransaction refers to tags by ID, as the tags are exported on their
+// own.
+type exportTransaction struct {
+	store.Transaction
+	Splits []store.TransactionSplit `json:"splits"`
+	TagIDs []int32                  `json:"tag_ids"`
+}
+
+// Encode writes the export as a JSON document or as a zip of CSV files. Every
+// file is read within one read-only transaction, so they agree with each
+// other even if the user's data changes while the export is being written.
+func (e *Export) Encode(w io.Writer) error {
+	ctx := context.Background()
+
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := e.queries.WithTx(tx)
+
+	data, err := e.load(ctx, qtx)
+	if err != nil {
+		return err
+	}
+
+	switch e.Format {
+	case ExportFormatCSV:
+		return e.encodeCSV(ctx, qtx, w, data)
+	default:
+		return e.encodeJSON(ctx, qtx, w, data)
+	}
+}
+
+func (e *Export) load(ctx context.Context, q store.Querier) (*exportData, error) {
+	var data exportData
+	var err error
+
+	data.Accounts, err = q.GetUserAccounts(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Categories, err = q.GetAllCategories(ctx, store.GetAllCategoriesParams{
+		AdminID: database.AdminUserID(),
+		UserID:  e.userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data.Tags, err = q.GetUserTags(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.RecurringTransactions, err = q.GetUserRecurringTransactions(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.RecurringExceptions = []store.RecurringTransactionException{}
+	data.RecurringOccurrences = []store.RecurringTransactionOccurrence{}
+	for _, rt := range data.RecurringTransactions {
+		exceptions, err := q.GetRecurringTransactionExceptions(ctx, rt.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.RecurringExceptions = append(data.RecurringExceptions, exceptions...)
+
+		// The occurrences record which transactions a rule has already
+		// created, so that a restored rule doesn't create them again.
+		occurrences, err := q.GetOccurrences(ctx, rt.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.RecurringOccurrences = append(data.RecurringOccurrences, occurrences...)
+	}
+
+	data.TransactionRefunds, err = q.GetUserTransactionRefunds(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Transfers, err = q.GetUserTransfers(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Reconciliations, err = q.GetUserReconciliations(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ReconciliationTransactions, err = q.GetUserReconciliationTransactions(ctx, e.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Accounts = orEmpty(data.Accounts)
+	data.Categories = orEmpty(data.Categories)
+	data.Tags = orEmpty(data.Tags)
+	data.RecurringTransactions = orEmpty(data.RecurringTransactions)
+	data.TransactionRefunds = orEmpty(data.TransactionRefunds)
+	data.Transfers = orEmpty(data.Transfers)
+	data.Reconciliations = orEmpty(data.Reconciliations)
+	data.ReconciliationTransactions = orEmpty(data.ReconciliationTransactions)
+
+	return &data, nil
+}
+
+// eachTransactionPage calls fn with the user's transactions in the date range,
+// oldest first, a page at a time.
+func (e *Export) eachTransactionPage(ctx context.Context, q store.Querier, fn func([]*TransactionDetails) error) error {
+	arg := store.ListTransactionsParams{
+		UserID:   e.userID,
+		Sort:     "date",
+		PageSize: exportPageSize,
+	}
+	if !e.DateFrom.IsZero() {
+		arg.DateFrom = sql.NullTime{Time: e.DateFrom, Valid: true}
+	}
+	if !e.DateTo.IsZero() {
+		arg.DateTo = sql.NullTime{Time: e.DateTo.AddDate(0, 0, 1), Valid: true}
+	}
+
+	for {
+		data, err := q.ListTransactions(ctx, arg)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return nil
+		}
+
+		rows := make([]store.Transaction, len(data))
+		for i, v := range data {
+			rows[i] = v.Transaction
+		}
+
+		transactions, err := withDetails(ctx, q, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(transactions); err != nil {
+			return err
+		}
+
+		if len(data) < exportPageSize {
+			return nil
+		}
+
+		last := rows[len(rows)-1]
+		arg.CursorID = sql.NullInt32{Int32: last.ID, Valid: true}
+		arg.CursorDate = sql.NullTime{Time: last.Date, Valid: true}
+	}
+}
+
+// encodeJSON writes a single document. Everything but the transactions is
+// marshaled at once, and the transactions are appended to it one by one.
+func (e *Export) encodeJSON(ctx context.Context, q store.Querier, w io.Writer, data *exportData) error {
+	document := struct {
+		Version           int     `json:"version"`
+		ExportedAt        string  `json:"exported_at"`
+		DateFrom          *string `json:"date_from"`
+		DateTo            *string `json:"date_to"`
+		InitialCategoryID int32   `json:"initial_category_id"`
+		*exportData
+	}{
+		Version:           ExportVersion,
+		ExportedAt:        e.ExportedAt.Format(time.RFC3339),
+		DateFrom:          formatOptionalDate(e.DateFrom),
+		DateTo:            formatOptionalDate(e.DateTo),
+		InitialCategoryID: database.InitialCategoryID(),
+		exportData:        data,
+	}
+
+	head, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	// Reopen the document to add the transactions as its last field.
+	bw.Write(head[:len(head)-1])
+	bw.WriteString(`,"transactions":[`)
+
+	first := true
+	err = e.eachTransactionPage(ctx, q, func(transactions []*TransactionDetails) error {
+		for _, transaction := range transactions {
+			if !first {
+				bw.WriteByte(',')
+			}
+			first = false
+
+			js, err := json.Marshal(newExportTransaction(transaction))
+			if err != nil {
+				return err
+			}
+			if _, err := bw.Write(js); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
+
+func newExportTransaction(transaction *TransactionDetails) exportTransaction {
+	tagIDs := make([]int32, len(transaction.Tags))
+	for i, tag := range transaction.Tags {
+		tagIDs[i] = tag.ID
+	}
+
+	return exportTransaction{
+		Transaction: transaction.Transaction,
+		Splits:      transaction.Splits,
+		TagIDs:      tagIDs,
+	}
+}
+
+// encodeCSV writes a zip with a CSV file per table. A zip can only write one
+// file at a time, so the transactions are read twice: once for their own
+// file and once for their splits.
+func (e *Export) encodeCSV(ctx context.Context, q store.Querier, w io.Writer, data *exportData) error {
+	zw := zip.NewWriter(w)
+
+	err := writeCSVFile(zw, "accounts.csv", []string{"id", "type", "name", "balance_cents", "cleared_balance_cents"}, func(cw *csv.Writer) error {
+		for _, account := range data.Accounts {
+			err := cw.Write([]string{
+				formatInt(account.ID),
+				string(account.Type),
+				account.Name,
+				strconv.FormatInt(account.BalanceCents, 10),
+				strconv.FormatInt(account.ClearedBalanceCents, 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeCSVFile(zw, "categories.csv", []string{"id", "name", "color", "icon"}, func(cw *csv.Writer) error {
+		for _, category := range data.Categories {
+			err := cw.Write([]string{
+				formatInt(category.ID),
+				category.Name,
+				category.Color,
+				category.Icon,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeCSVFile(zw, "tags.csv", []string{"id", "name"}, func(cw *csv.Writer) error {
+		for _, tag := range data.Tags {
+			if err := cw.Write([]string{formatInt(tag.ID), tag.Name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	transactionsHeader := []string{"id", "date", "account_id", "category_id", "amount_cents", "title", "note", "status", "tag_ids"}
+	err = writeCSVFile(zw, "transactions.csv", transactionsHeader, func(cw *csv.Writer) error {
+		return e.eachTransactionPage(ctx, q, func(transactions []*TransactionDetails) error {
+			for _, transaction := range transactions {
+				tagIDs := make([]string, len(transaction.Tags))
+				for i, tag := range transaction.Tags {
+					tagIDs[i] = formatInt(tag.ID)
+				}
+
+				err := cw.Write([]string{
+					formatInt(transaction.ID),
+					transaction.Date.Format(time.RFC3339),
+					formatInt(transaction.AccountID),
+					formatInt(transaction.CategoryID),
+					strconv.FormatInt(transaction.AmountCents, 10),
+					transaction.Title,
+					transaction.Note,
+					string(transaction.Status),
+					strings.Join(tagIDs, ";"),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	splitsHeader := []string{"id", "transaction_id", "category_id", "amount_cents", "note"}
+	err = writeCSVFile(zw, "transaction_splits.csv", splitsHeader, func(cw *csv.Writer) error {
+		return e.eachTransactionPage(ctx, q, func(transactions []*TransactionDetails) error {
+			for _, transaction := range transactions {
+				for _, split := range transaction.Splits {
+					err := cw.Write([]string{
+						formatInt(split.ID),
+						formatInt(split.TransactionID),
+						formatInt(split.CategoryID),
+						strconv.FormatInt(split.AmountCents, 10),
+						split.Note,
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	recurringHeader := []string{
+		"id", "account_id", "category_id", "amount_cents", "title", "note", "frequency", "interval",
+		"start_date", "end_date", "day_month", "day_week", "max_occurrences", "is_active",
+	}
+	err = writeCSVFile(zw, "recurring_transactions.csv", recurringHeader, func(cw *csv.Writer) error {
+		for _, rt := range data.RecurringTransactions {
+			err := cw.Write([]string{
+				formatInt(rt.ID),
+				formatInt(rt.AccountID),
+				formatInt(rt.CategoryID),
+				formatInt(rt.AmountCents),
+				rt.Title,
+				rt.Note,
+				string(rt.Frequency),
+				formatInt(rt.Interval),
+				rt.StartDate.Format(time.DateOnly),
+				formatOptional(rt.EndDate, func(t time.Time) string { return t.Format(time.DateOnly) }),
+				formatOptional(rt.DayMonth, formatInt),
+				formatOptional(rt.DayWeek, formatInt),
+				formatOptional(rt.MaxOccurrences, formatInt),
+				strconv.FormatBool(rt.IsActive),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	exceptionsHeader := []string{"id", "recurring_transaction_id", "occurrence_date", "skip", "new_date", "amount_cents"}
+	err = writeCSVFile(zw, "recurring_transaction_exceptions.csv", exceptionsHeader, func(cw *csv.Writer) error {
+		for _, exception := range data.RecurringExceptions {
+			err := cw.Write([]string{
+				formatInt(exception.ID),
+				formatInt(exception.RecurringTransactionID),
+				exception.OccurrenceDate.Format(time.DateOnly),
+				strconv.FormatBool(exception.Skip),
+				formatOptional(exception.NewDate, func(t time.Time) string { return t.Format(time.DateOnly) }),
+				formatOptional(exception.AmountCents, formatInt),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	occurrencesHeader := []string{"id", "recurring_transaction_id", "transaction_id", "occurrence_date"}
+	err = writeCSVFile(zw, "recurring_transaction_occurrences.csv", occurrencesHeader, func(cw *csv.Writer) error {
+		for _, occurrence := range data.RecurringOccurrences {
+			err := cw.Write([]string{
+				formatInt(occurrence.ID),
+				formatInt(occurrence.RecurringTransactionID),
+				formatOptional(occurrence.TransactionID, formatInt),
+				occurrence.OccurrenceDate.Format(time.DateOnly),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	refundsHeader := []string{"id", "transaction_id", "refund_transaction_id", "reason"}
+	err = writeCSVFile(zw, "transaction_refunds.csv", refundsHeader, func(cw *csv.Writer) error {
+		for _, refund := range data.TransactionRefunds {
+			err := cw.Write([]string{
+				formatInt(refund.ID),
+				formatInt(refund.TransactionID),
+				formatInt(refund.RefundTransactionID),
+				refund.Reason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	transfersHeader := []string{"id", "from_transaction_id", "to_transaction_id", "fee_transaction_id"}
+	err = writeCSVFile(zw, "transfers.csv", transfersHeader, func(cw *csv.Writer) error {
+		for _, transfer := range data.Transfers {
+			err := cw.Write([]string{
+				formatInt(transfer.ID),
+				formatInt(transfer.FromTransactionID),
+				formatInt(transfer.ToTransactionID),
+				formatOptional(transfer.FeeTransactionID, formatInt),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	reconciliationsHeader := []string{"id", "account_id", "statement_date", "statement_balance_cents", "reconciled_balance_cents", "difference_cents", "adjustment_transaction_id"}
+	err = writeCSVFile(zw, "reconciliations.csv", reconciliationsHeader, func(cw *csv.Writer) error {
+		for _, reconciliation := range data.Reconciliations {
+			err := cw.Write([]string{
+				formatInt(reconciliation.ID),
+				formatInt(reconciliation.AccountID),
+				reconciliation.StatementDate.Format(time.DateOnly),
+				strconv.FormatInt(reconciliation.StatementBalanceCents, 10),
+				strconv.FormatInt(reconciliation.ReconciledBalanceCents, 10),
+				strconv.FormatInt(reconciliation.DifferenceCents, 10),
+				formatOptional(reconciliation.AdjustmentTransactionID, formatInt),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	reconciliationTransactionsHeader := []string{"reconciliation_id", "transaction_id"}
+	err = writeCSVFile(zw, "reconciliation_transactions.csv", reconciliationTransactionsHeader, func(cw *csv.Writer) error {
+		for _, link := range data.ReconciliationTransactions {
+			err := cw.Write([]string{
+				formatInt(link.ReconciliationID),
+				formatInt(link.TransactionID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeCSVFile adds a CSV file to the zip, writing its header and then
+// calling write for the rows.
+func writeCSVFile(zw *zip.Writer, name string, header []string, write func(*csv.Writer) error) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := write(cw); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatInt(n int32) string {
+	return strconv.FormatInt(int64(n), 10)
+}
+
+func formatOptional[T any](value *T, format func(T) string) string {
+	if value == nil {
+		return ""
+	}
+	return format(*value)
+}
+
+func formatOptionalDate(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.Format(time.DateOnly)
+	return &s
+}
+
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
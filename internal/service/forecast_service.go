@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/forecast"
+	"github.com/Quak1/gokei/internal/recurrence"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+const maxForecastDays = 731
+
+type ForecastService struct {
+	queries store.QuerierTx
+	account *AccountService
+}
+
+func NewForecastService(queries store.QuerierTx, accountService *AccountService) *ForecastService {
+	return &ForecastService{
+		queries: queries,
+		account: accountService,
+	}
+}
+
+func validateForecastRange(v *validator.Validator, today, until time.Time) {
+	v.Check(until.After(today), "until", "Must be after today")
+	v.Check(!until.After(today.AddDate(0, 0, maxForecastDays)), "until", "Must not be more than two years from today")
+}
+
+// rules returns the user's recurring transactions that can have occurrences in
+// the inclusive range [from, until].
+func (s *ForecastService) rules(userID int32, from, until time.Time) ([]forecast.Rule, error) {
+	ctx := context.Background()
+
+	transactions, err := s.queries.GetActiveRecurringTransactions(ctx, store.GetActiveRecurringTransactionsParams{
+		UserID:     userID,
+		Now:        until,
+		EndedAfter: from,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]forecast.Rule, len(transactions))
+	for i := range transactions {
+		exceptions, err := s.queries.GetRecurringTransactionExceptions(ctx, transactions[i].ID)
+		if err != nil {
+			return nil, err
+		}
+
+		rules[i] = forecast.Rule{
+			Transaction: &transactions[i],
+			Exceptions:  exceptions,
+		}
+	}
+
+	return rules, nil
+}
+
+// ForAccount projects the balance of an account for every day after today up
+// to until. Occurrences due today are assumed to be posted already.
+func (s *ForecastService) ForAccount(userID, accountID int32, today, until time.Time) (*forecast.Account, error) {
+	today, until = recurrence.Date(today), recurrence.Date(until)
+
+	v := validator.New()
+	if validateForecastRange(v, today, until); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	account, err := s.account.GetByID(accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := today.AddDate(0, 0, 1)
+
+	rules, err := s.rules(userID, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	return forecast.ForAccount(account, rules, from, until), nil
+}
+
+// ForUser projects the balance of all of the user's accounts, see ForAccount.
+func (s *ForecastService) ForUser(userID int32, today, until time.Time) (*forecast.Forecast, error) {
+	today, until = recurrence.Date(today), recurrence.Date(until)
+
+	v := validator.New()
+	if validateForecastRange(v, today, until); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	accounts, err := s.account.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := today.AddDate(0, 0, 1)
+
+	rules, err := s.rules(userID, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := make([]*forecast.Account, len(accounts))
+	for i, account := range accounts {
+		forecasts[i] = forecast.ForAccount(account, rules, from, until)
+	}
+
+	return forecast.Combine(forecasts), nil
+}
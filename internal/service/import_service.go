@@ -0,0 +1,855 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/importer"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+var (
+	ErrDuplicateImportProfileName = errors.New("duplicate import profile name")
+)
+
+const MaxImportEntries = 5000
+
+// importedCategoryColor is the color of the categories created for imported
+// transactions.
+const importedCategoryColor = "#888"
+
+type ImportService struct {
+	queries store.QuerierTx
+	DB      *sql.DB
+}
+
+func NewImportService(queries store.QuerierTx, db *sql.DB) *ImportService {
+	return &ImportService{
+		queries: queries,
+		DB:      db,
+	}
+}
+
+// setMappingDefaults fills in the settings most exports share.
+func setMappingDefaults(m *importer.CSVMapping) {
+	if m.Delimiter == "" {
+		m.Delimiter = ","
+	}
+	if m.DateFormat == "" {
+		m.DateFormat = "YYYY-MM-DD"
+	}
+	if m.DecimalSeparator == "" {
+		m.DecimalSeparator = "."
+	}
+	if m.SignConvention == "" {
+		m.SignConvention = importer.SignSigned
+	}
+}
+
+func validateCSVMapping(v *validator.Validator, m *importer.CSVMapping) {
+	v.Check(utf8.RuneCountInString(m.Delimiter) == 1, "delimiter", "Must be a single character")
+	v.Check(!validator.PermittedValue(m.Delimiter, "\"", "\r", "\n"), "delimiter", "Can't be a quote or a line break")
+
+	v.Check(m.SkipRows >= 0 && m.SkipRows <= 100, "skip_rows", "Must be between 0 and 100")
+
+	v.Check(validator.NonZero(m.DateColumn), "date_column", "Must be provided")
+	_, err := importer.DateLayout(m.DateFormat)
+	v.Check(err == nil, "date_format", "Must be made of YYYY, YY, MM, M, DD and D, such as DD/MM/YYYY")
+
+	v.Check(validator.NonZero(m.TitleColumn), "title_column", "Must be provided")
+
+	v.Check(validator.PermittedValue(m.DecimalSeparator, ".", ","), "decimal_separator", "Must be . or ,")
+
+	switch m.SignConvention {
+	case importer.SignSigned, importer.SignInverted:
+		v.Check(validator.NonZero(m.AmountColumn), "amount_column", "Must be provided")
+	case importer.SignDebitCredit:
+		v.Check(validator.NonZero(m.DebitColumn), "debit_column", "Must be provided")
+		v.Check(validator.NonZero(m.CreditColumn), "credit_column", "Must be provided")
+	default:
+		v.AddError("sign_convention", "Invalid sign convention. Valid values are signed, inverted and debit_credit")
+	}
+
+	if !m.HasHeader {
+		columns := map[string]string{
+			"date_column":   m.DateColumn,
+			"amount_column": m.AmountColumn,
+			"debit_column":  m.DebitColumn,
+			"credit_column": m.CreditColumn,
+			"title_column":  m.TitleColumn,
+			"note_column":   m.NoteColumn,
+		}
+		for key, column := range columns {
+			if n, err := strconv.Atoi(column); column != "" && (err != nil || n < 1) {
+				v.AddError(key, "Must be a column position starting at 1 when there is no header")
+			}
+		}
+	}
+}
+
+func profileMapping(profile *store.ImportProfile) importer.CSVMapping {
+	return importer.CSVMapping{
+		Delimiter:        profile.Delimiter,
+		HasHeader:        profile.HasHeader,
+		SkipRows:         profile.SkipRows,
+		DateColumn:       profile.DateColumn,
+		DateFormat:       profile.DateFormat,
+		AmountColumn:     profile.AmountColumn,
+		DebitColumn:      profile.DebitColumn,
+		CreditColumn:     profile.CreditColumn,
+		TitleColumn:      profile.TitleColumn,
+		NoteColumn:       profile.NoteColumn,
+		DecimalSeparator: profile.DecimalSeparator,
+		SignConvention:   profile.SignConvention,
+	}
+}
+
+type CreateImportProfileParams struct {
+	Name string `json:"name"`
+	importer.CSVMapping
+}
+
+func (s *ImportService) CreateProfile(userID int32, params *CreateImportProfileParams) (*store.ImportProfile, error) {
+	mapping := params.CSVMapping
+	setMappingDefaults(&mapping)
+
+	v := validator.New()
+	v.Check(validator.NonZero(params.Name), "name", "Must be provided")
+	v.Check(validator.MaxLength(params.Name, 50), "name", "Must not be more than 50 bytes long")
+	if validateCSVMapping(v, &mapping); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	profile, err := s.queries.CreateImportProfile(context.Background(), store.CreateImportProfileParams{
+		UserID:           userID,
+		Name:             params.Name,
+		Delimiter:        mapping.Delimiter,
+		HasHeader:        mapping.HasHeader,
+		SkipRows:         mapping.SkipRows,
+		DateColumn:       mapping.DateColumn,
+		DateFormat:       mapping.DateFormat,
+		AmountColumn:     mapping.AmountColumn,
+		DebitColumn:      mapping.DebitColumn,
+		CreditColumn:     mapping.CreditColumn,
+		TitleColumn:      mapping.TitleColumn,
+		NoteColumn:       mapping.NoteColumn,
+		DecimalSeparator: mapping.DecimalSeparator,
+		SignConvention:   mapping.SignConvention,
+	})
+	if err != nil {
+		if database.IsUniqueContraintViolation(err) {
+			return nil, ErrDuplicateImportProfileName
+		}
+		return nil, database.HandleForeignKeyError(err)
+	}
+
+	return &profile, nil
+}
+
+func (s *ImportService) GetProfiles(userID int32) ([]store.ImportProfile, error) {
+	profiles, err := s.queries.GetImportProfiles(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if profiles == nil {
+		profiles = []store.ImportProfile{}
+	}
+
+	return profiles, nil
+}
+
+func (s *ImportService) GetProfileByID(userID, profileID int32) (*store.ImportProfile, error) {
+	if userID < 1 || profileID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	profile, err := s.queries.GetImportProfileByID(context.Background(), store.GetImportProfileByIDParams{
+		ID:     profileID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &profile, nil
+}
+
+type UpdateImportProfileParams struct {
+	Name             *string `json:"name"`
+	Delimiter        *string `json:"delimiter"`
+	HasHeader        *bool   `json:"has_header"`
+	SkipRows         *int32  `json:"skip_rows"`
+	DateColumn       *string `json:"date_column"`
+	DateFormat       *string `json:"date_format"`
+	AmountColumn     *string `json:"amount_column"`
+	DebitColumn      *string `json:"debit_column"`
+	CreditColumn     *string `json:"credit_column"`
+	TitleColumn      *string `json:"title_column"`
+	NoteColumn       *string `json:"note_column"`
+	DecimalSeparator *string `json:"decimal_separator"`
+	SignConvention   *string `json:"sign_convention"`
+}
+
+func (s *ImportService) UpdateProfileByID(userID, profileID int32, params *UpdateImportProfileParams) (*store.ImportProfile, error) {
+	profile, err := s.GetProfileByID(userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	set(&profile.Name, params.Name)
+	set(&profile.Delimiter, params.Delimiter)
+	set(&profile.DateColumn, params.DateColumn)
+	set(&profile.DateFormat, params.DateFormat)
+	set(&profile.AmountColumn, params.AmountColumn)
+	set(&profile.DebitColumn, params.DebitColumn)
+	set(&profile.CreditColumn, params.CreditColumn)
+	set(&profile.TitleColumn, params.TitleColumn)
+	set(&profile.NoteColumn, params.NoteColumn)
+	set(&profile.DecimalSeparator, params.DecimalSeparator)
+	set(&profile.SignConvention, params.SignConvention)
+	if params.HasHeader != nil {
+		profile.HasHeader = *params.HasHeader
+	}
+	if params.SkipRows != nil {
+		profile.SkipRows = *params.SkipRows
+	}
+
+	mapping := profileMapping(profile)
+
+	v := validator.New()
+	v.Check(validator.NonZero(profile.Name), "name", "Must be provided")
+	v.Check(validator.MaxLength(profile.Name, 50), "name", "Must not be more than 50 bytes long")
+	if validateCSVMapping(v, &mapping); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	result, err := s.queries.UpdateImportProfileByID(context.Background(), store.UpdateImportProfileByIDParams{
+		Name:             profile.Name,
+		Delimiter:        profile.Delimiter,
+		HasHeader:        profile.HasHeader,
+		SkipRows:         profile.SkipRows,
+		DateColumn:       profile.DateColumn,
+		DateFormat:       profile.DateFormat,
+		AmountColumn:     profile.AmountColumn,
+		DebitColumn:      profile.DebitColumn,
+		CreditColumn:     profile.CreditColumn,
+		TitleColumn:      profile.TitleColumn,
+		NoteColumn:       profile.NoteColumn,
+		DecimalSeparator: profile.DecimalSeparator,
+		SignConvention:   profile.SignConvention,
+		ID:               profile.ID,
+		UserID:           userID,
+		Version:          profile.Version,
+	})
+	if err != nil {
+		if database.IsUniqueContraintViolation(err) {
+			return nil, ErrDuplicateImportProfileName
+		}
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, database.ErrEditConflict
+	}
+
+	profile.Version++
+
+	return profile, nil
+}
+
+func (s *ImportService) DeleteProfileByID(userID, profileID int32) error {
+	if userID < 1 || profileID < 1 {
+		return database.ErrRecordNotFound
+	}
+
+	result, err := s.queries.DeleteImportProfileByID(context.Background(), store.DeleteImportProfileByIDParams{
+		ID:     profileID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Decisions for the entries that are probable duplicates of transactions
+// already in the account.
+const (
+	DuplicateSkip   = "skip"
+	DuplicateImport = "import"
+	// DuplicateMerge keeps the existing transaction, taking the statement's
+	// date along with its note when the transaction has none.
+	DuplicateMerge = "merge"
+)
+
+// ImportParams are the options shared by every statement format.
+type ImportParams struct {
+	CategoryID int32 `json:"category_id"`
+	DryRun     bool  `json:"dry_run"`
+	// DuplicateDecisions holds the decision for probable duplicates by their
+	// index in the statement. Those without one are skipped.
+	DuplicateDecisions map[int]string `json:"duplicate_decisions"`
+}
+
+type ImportCSVParams struct {
+	ImportParams
+
+	// Either a saved profile or a mapping for this file only.
+	ProfileID int32                `json:"profile_id"`
+	Mapping   *importer.CSVMapping `json:"mapping"`
+}
+
+type ImportResult struct {
+	DryRun       bool                `json:"dry_run"`
+	Entries      []importer.Entry    `json:"entries"`
+	Transactions []store.Transaction `json:"transactions"`
+	// Skipped holds the entries that were already imported into the account.
+	Skipped []importer.Entry `json:"skipped"`
+	// NewCategories names the categories created for the entries.
+	NewCategories []string          `json:"new_categories"`
+	Duplicates    []ImportDuplicate `json:"duplicates"`
+	// Merged holds the existing transactions that entries were merged into.
+	Merged       []store.Transaction `json:"merged"`
+	BalanceCheck *BalanceCheck       `json:"balance_check,omitempty"`
+}
+
+// ImportDuplicate is an entry that is a probable duplicate of transactions
+// already in the account, along with what was done with it.
+type ImportDuplicate struct {
+	// Index is the position of the entry in the statement, starting at 0.
+	Index      int                 `json:"index"`
+	Entry      importer.Entry      `json:"entry"`
+	Candidates []store.Transaction `json:"candidates"`
+	Decision   string              `json:"decision"`
+}
+
+// BalanceCheck compares the ledger balance reported by a statement with the
+// account's balance once the statement is imported. Transactions of the account
+// dated after the statement are part of the difference.
+type BalanceCheck struct {
+	StatementBalance    importer.Balance `json:"statement_balance"`
+	AccountBalanceCents int64            `json:"account_balance_cents"`
+	DifferenceCents     int64            `json:"difference_cents"`
+}
+
+func (s *ImportService) ImportCSV(userID, accountID int32, file io.Reader, params *ImportCSVParams) (*ImportResult, error) {
+	v := validator.New()
+	v.Check(params.ProfileID != 0 || params.Mapping != nil, "mapping", "Must be provided unless profile_id is")
+	v.Check(params.ProfileID == 0 || params.Mapping == nil, "mapping", "Can't be used with profile_id")
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	var mapping importer.CSVMapping
+	if params.ProfileID != 0 {
+		profile, err := s.GetProfileByID(userID, params.ProfileID)
+		if err != nil {
+			if errors.Is(err, database.ErrRecordNotFound) {
+				v.AddError("profile_id", "Does not exist")
+				return nil, v.GetErrors()
+			}
+			return nil, err
+		}
+		mapping = profileMapping(profile)
+	} else {
+		mapping = *params.Mapping
+		setMappingDefaults(&mapping)
+		if validateCSVMapping(v, &mapping); !v.Valid() {
+			return nil, v.GetErrors()
+		}
+	}
+
+	entries, err := importer.ParseCSV(file, mapping)
+	if err != nil {
+		v.AddError("file", err.Error())
+		return nil, v.GetErrors()
+	}
+
+	return s.importStatement(userID, accountID, &importer.Statement{Entries: entries}, &params.ImportParams)
+}
+
+func (s *ImportService) ImportOFX(userID, accountID int32, file io.Reader, params *ImportParams) (*ImportResult, error) {
+	statement, err := importer.ParseOFX(file)
+	if err != nil {
+		v := validator.New()
+		v.AddError("file", err.Error())
+		return nil, v.GetErrors()
+	}
+
+	return s.importStatement(userID, accountID, statement, params)
+}
+
+func (s *ImportService) ImportCAMT053(userID, accountID int32, file io.Reader, params *ImportParams) (*ImportResult, error) {
+	statement, err := importer.ParseCAMT053(file)
+	if err != nil {
+		v := validator.New()
+		v.AddError("file", err.Error())
+		return nil, v.GetErrors()
+	}
+
+	return s.importStatement(userID, accountID, statement, params)
+}
+
+func (s *ImportService) ImportMT940(userID, accountID int32, file io.Reader, params *ImportParams) (*ImportResult, error) {
+	statement, err := importer.ParseMT940(file)
+	if err != nil {
+		v := validator.New()
+		v.AddError("file", err.Error())
+		return nil, v.GetErrors()
+	}
+
+	return s.importStatement(userID, accountID, statement, params)
+}
+
+type ImportQIFParams struct {
+	ImportParams
+	DateOrder string `json:"date_order"`
+}
+
+// qifAccountTypes maps the QIF transaction sections to account types.
+var qifAccountTypes = map[string]store.AccountType{
+	importer.QIFTypeBank:  store.AccountTypeDebit,
+	importer.QIFTypeCash:  store.AccountTypeCash,
+	importer.QIFTypeCCard: store.AccountTypeCredit,
+}
+
+// ImportQIF imports a QIF file into an account of the matching type. The
+// categories of the file are created for the user when they don't exist, and
+// params.CategoryID is only needed for the transactions without one.
+func (s *ImportService) ImportQIF(userID, accountID int32, file io.Reader, params *ImportQIFParams) (*ImportResult, error) {
+	if params.DateOrder == "" {
+		params.DateOrder = importer.DateOrderMDY
+	}
+
+	v := validator.New()
+	v.Check(validator.PermittedValue(params.DateOrder, importer.DateOrderMDY, importer.DateOrderDMY, importer.DateOrderYMD), "date_order", "Invalid date order. Valid values are mdy, dmy and ymd")
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	account, err := s.queries.GetAccountByID(context.Background(), store.GetAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	qif, err := importer.ParseQIF(file, params.DateOrder)
+	if err != nil {
+		v.AddError("file", err.Error())
+		return nil, v.GetErrors()
+	}
+
+	if accountType := qifAccountTypes[qif.Type]; accountType != account.Type {
+		v.AddError("file", fmt.Sprintf("Holds %s transactions, which can only be imported into a %s account", qif.Type, accountType))
+		return nil, v.GetErrors()
+	}
+
+	return s.importStatement(userID, accountID, &importer.Statement{Entries: qif.Entries}, &params.ImportParams)
+}
+
+// importStatement turns the entries read from a statement into transactions of
+// the account, recomputing its balance once they are all in. Entries whose
+// external ID was already imported into the account are skipped, so importing
+// the same statement twice is harmless, and probable duplicates of the
+// account's transactions are handled as params.DuplicateDecisions says.
+// Nothing is written on a dry run.
+func (s *ImportService) importStatement(userID, accountID int32, statement *importer.Statement, params *ImportParams) (*ImportResult, error) {
+	uncategorized := slices.ContainsFunc(statement.Entries, func(entry importer.Entry) bool {
+		return entry.Category == ""
+	})
+
+	v := validator.New()
+	v.Check(!uncategorized || validator.NonZero(params.CategoryID), "category_id", "Must be provided")
+	v.Check(params.CategoryID != database.InitialCategoryID(), "category_id", "Can't use the initial category")
+	for _, entry := range statement.Entries {
+		if !validator.MaxLength(entry.Category, 20) {
+			v.AddError("file", fmt.Sprintf("Category %q must not be more than 20 bytes long", entry.Category))
+			break
+		}
+	}
+	v.Check(len(statement.Entries) <= MaxImportEntries, "file", fmt.Sprintf("Must not have more than %d transactions", MaxImportEntries))
+	for index, decision := range params.DuplicateDecisions {
+		v.Check(index >= 0 && index < len(statement.Entries), "duplicate_decisions", fmt.Sprintf("Index %d is not an entry of the statement", index))
+		v.Check(validator.PermittedValue(decision, DuplicateSkip, DuplicateImport, DuplicateMerge), "duplicate_decisions", "Invalid decision. Valid values are skip, import and merge")
+	}
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	account, err := qtx.GetAccountByID(ctx, store.GetAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	indexes, skipped, err := newEntries(ctx, qtx, accountID, statement.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]duplicateCheck, len(indexes))
+	for i, index := range indexes {
+		entry := statement.Entries[index]
+		checks[i] = duplicateCheck{AmountCents: entry.AmountCents, Date: entry.Date, Title: entry.Title}
+	}
+	duplicates, err := findDuplicates(ctx, qtx, accountID, checks, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DryRun:       params.DryRun,
+		Entries:      []importer.Entry{},
+		Transactions: []store.Transaction{},
+		Skipped:      skipped,
+		Duplicates:   []ImportDuplicate{},
+		Merged:       []store.Transaction{},
+	}
+
+	// Each transaction takes at most one entry, so an entry is merged into
+	// the first of its candidates that no earlier entry was merged into.
+	var merges []ImportDuplicate
+	merged := make(map[int32]bool)
+	for i, index := range indexes {
+		entry := statement.Entries[index]
+		if len(duplicates[i]) == 0 {
+			result.Entries = append(result.Entries, entry)
+			continue
+		}
+
+		duplicate := ImportDuplicate{
+			Index:      index,
+			Entry:      entry,
+			Candidates: duplicates[i],
+			Decision:   params.DuplicateDecisions[index],
+		}
+		if duplicate.Decision == "" {
+			duplicate.Decision = DuplicateSkip
+		}
+
+		if duplicate.Decision == DuplicateMerge {
+			duplicate.Candidates = slices.DeleteFunc(duplicate.Candidates, func(candidate store.Transaction) bool {
+				return merged[candidate.ID]
+			})
+			if len(duplicate.Candidates) == 0 {
+				v.AddError("duplicate_decisions", fmt.Sprintf("Entry %d has no transaction left to merge into", index))
+				return nil, v.GetErrors()
+			}
+			merged[duplicate.Candidates[0].ID] = true
+		}
+		result.Duplicates = append(result.Duplicates, duplicate)
+
+		switch duplicate.Decision {
+		case DuplicateImport:
+			result.Entries = append(result.Entries, entry)
+		case DuplicateMerge:
+			merges = append(merges, duplicate)
+		}
+	}
+	entries := result.Entries
+
+	categoryIDs, newCategories, err := entryCategories(ctx, qtx, userID, entries, params.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	result.NewCategories = newCategories
+
+	if params.DryRun {
+		balance := account.BalanceCents
+		for _, entry := range entries {
+			balance += entry.AmountCents
+		}
+		result.BalanceCheck = checkBalance(statement.LedgerBalance, balance)
+		return result, nil
+	}
+
+	for _, entry := range entries {
+		categoryID := params.CategoryID
+		if entry.Category != "" {
+			categoryID = categoryIDs[strings.ToLower(entry.Category)]
+		}
+
+		transaction, err := qtx.CreateTransactionWithDate(ctx, store.CreateTransactionWithDateParams{
+			AccountID:   accountID,
+			AmountCents: entry.AmountCents,
+			CategoryID:  categoryID,
+			Title:       entry.Title,
+			Note:        entry.Note,
+			Date:        entry.Date,
+		})
+		if err != nil {
+			return nil, database.HandleForeignKeyError(err)
+		}
+		result.Transactions = append(result.Transactions, transaction)
+
+		if entry.ExternalID != "" {
+			err = qtx.CreateImportedTransaction(ctx, store.CreateImportedTransactionParams{
+				TransactionID: transaction.ID,
+				AccountID:     accountID,
+				ExternalID:    entry.ExternalID,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, merge := range merges {
+		transaction, err := mergeEntry(ctx, qtx, userID, merge.Candidates[0], merge.Entry)
+		if err != nil {
+			return nil, err
+		}
+		result.Merged = append(result.Merged, *transaction)
+	}
+
+	err = updateBalances(ctx, qtx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if statement.LedgerBalance != nil {
+		account, err = qtx.GetAccountByID(ctx, store.GetAccountByIDParams{
+			ID:     accountID,
+			UserID: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.BalanceCheck = checkBalance(statement.LedgerBalance, account.BalanceCents)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// newEntries returns the indexes of the entries to import, leaving out the
+// ones already imported into the account, or repeated within the statement,
+// which are returned as skipped.
+func newEntries(ctx context.Context, q store.Querier, accountID int32, entries []importer.Entry) ([]int, []importer.Entry, error) {
+	var externalIDs []string
+	for _, entry := range entries {
+		if entry.ExternalID != "" {
+			externalIDs = append(externalIDs, entry.ExternalID)
+		}
+	}
+
+	seen := make(map[string]bool, len(externalIDs))
+	if len(externalIDs) > 0 {
+		imported, err := q.GetImportedExternalIDs(ctx, store.GetImportedExternalIDsParams{
+			AccountID:   accountID,
+			ExternalIds: externalIDs,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range imported {
+			seen[id] = true
+		}
+	}
+
+	indexes := []int{}
+	skipped := []importer.Entry{}
+	for i, entry := range entries {
+		if entry.ExternalID == "" {
+			indexes = append(indexes, i)
+			continue
+		}
+		if seen[entry.ExternalID] {
+			skipped = append(skipped, entry)
+			continue
+		}
+		seen[entry.ExternalID] = true
+		indexes = append(indexes, i)
+	}
+
+	return indexes, skipped, nil
+}
+
+// mergeEntry merges an entry into the transaction it duplicates, which takes
+// the statement's date, and its note when it has none. Being on a statement,
+// a pending transaction is cleared by it. A reconciled transaction is left as
+// it is. A leg or the fee of a transfer is merged along with the rest of the
+// transfer, so that it all keeps the same date. The entry's external ID is
+// recorded against the transaction, so it is skipped on later imports.
+func mergeEntry(ctx context.Context, q store.Querier, userID int32, transaction store.Transaction, entry importer.Entry) (*store.Transaction, error) {
+	transactions := []store.Transaction{transaction}
+	editable := transaction.Status != store.TransactionStatusReconciled
+
+	transfer, err := q.GetTransferByTransactionID(ctx, transaction.ID)
+	switch {
+	case err == nil:
+		details, err := withLegs(ctx, q, userID, []store.Transfer{transfer})
+		if err != nil {
+			return nil, err
+		}
+		transactions = []store.Transaction{details[0].From, details[0].To}
+		if details[0].Fee != nil {
+			transactions = append(transactions, *details[0].Fee)
+		}
+		editable = checkTransferNotReconciled(details[0]) == nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	if editable {
+		for _, t := range transactions {
+			t.Date = entry.Date
+			if t.ID == transaction.ID {
+				if t.Note == "" {
+					t.Note = entry.Note
+				}
+				if t.Status == store.TransactionStatusPending {
+					t.Status = store.TransactionStatusCleared
+				}
+			}
+
+			err := writeTransaction(ctx, q, userID, t)
+			if err != nil {
+				return nil, err
+			}
+			t.Version++
+
+			if t.ID == transaction.ID {
+				transaction = t
+			}
+		}
+	}
+
+	if entry.ExternalID != "" {
+		err := q.CreateImportedTransaction(ctx, store.CreateImportedTransactionParams{
+			TransactionID: transaction.ID,
+			AccountID:     transaction.AccountID,
+			ExternalID:    entry.ExternalID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &transaction, nil
+}
+
+// entryCategories looks up the categories named by the entries, ignoring case,
+// and creates the ones the user doesn't have yet, unless on a dry run. It
+// returns the category IDs by lowercase name along with the names of the new
+// categories.
+func entryCategories(ctx context.Context, q store.Querier, userID int32, entries []importer.Entry, dryRun bool) (map[string]int32, []string, error) {
+	categories, err := q.GetAllCategories(ctx, store.GetAllCategoriesParams{
+		AdminID: database.AdminUserID(),
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make(map[string]int32, len(categories))
+	for _, category := range categories {
+		if category.ID != database.InitialCategoryID() {
+			ids[strings.ToLower(category.Name)] = category.ID
+		}
+	}
+
+	newCategories := []string{}
+	for _, entry := range entries {
+		if entry.Category == "" {
+			continue
+		}
+		key := strings.ToLower(entry.Category)
+		if _, ok := ids[key]; ok {
+			continue
+		}
+
+		newCategories = append(newCategories, entry.Category)
+		if dryRun {
+			ids[key] = 0
+			continue
+		}
+
+		icon, _ := utf8.DecodeRuneInString(entry.Category)
+		category, err := q.CreateCategory(ctx, store.CreateCategoryParams{
+			UserID: userID,
+			Name:   entry.Category,
+			Color:  importedCategoryColor,
+			Icon:   strings.ToUpper(string(icon)),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[key] = category.ID
+	}
+
+	return ids, newCategories, nil
+}
+
+func checkBalance(statementBalance *importer.Balance, accountBalanceCents int64) *BalanceCheck {
+	if statementBalance == nil {
+		return nil
+	}
+
+	return &BalanceCheck{
+		StatementBalance:    *statementBalance,
+		AccountBalanceCents: accountBalanceCents,
+		DifferenceCents:     accountBalanceCents - statementBalance.AmountCents,
+	}
+}
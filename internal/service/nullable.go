@@ -0,0 +1,17 @@
+package service
+
+import "encoding/json"
+
+// Nullable is a field of an update that can be left out, set, or cleared by
+// sending it as null. Set tells whether it was sent at all, and Value is nil
+// when it was sent as null.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// UnmarshalJSON is only called for fields present in the document.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
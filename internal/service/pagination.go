@@ -0,0 +1,42 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Pagination is returned with keyset paginated listings. NextCursor is passed
+// back as the cursor parameter to get the page that follows.
+type Pagination struct {
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// encodeCursor serializes the sort key of the last row of a page into an
+// opaque token.
+func encodeCursor(key any) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string, key any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, key); err != nil {
+		return errInvalidCursor
+	}
+
+	return nil
+}
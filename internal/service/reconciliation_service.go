@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+var (
+	ErrNotLatestReconciliation = errors.New("Only the latest reconciliation of an account can be deleted")
+)
+
+type ReconciliationService struct {
+	queries store.QuerierTx
+	DB      *sql.DB
+	blobs   blob.Store
+}
+
+func NewReconciliationService(queries store.QuerierTx, db *sql.DB, blobs blob.Store) *ReconciliationService {
+	return &ReconciliationService{
+		queries: queries,
+		DB:      db,
+		blobs:   blobs,
+	}
+}
+
+// ReconciliationDetails is a reconciliation along with the transactions it
+// reconciled, its adjustment included.
+type ReconciliationDetails struct {
+	store.Reconciliation
+	TransactionIDs []int32 `json:"transaction_ids"`
+}
+
+type CreateReconciliationParams struct {
+	StatementDate         time.Time `json:"statement_date"`
+	StatementBalanceCents int64     `json:"statement_balance_cents"`
+
+	// TransactionIDs are the cleared transactions the statement shows. Along
+	// with the transactions reconciled before, they must add up to the
+	// statement balance.
+	TransactionIDs []int32 `json:"transaction_ids"`
+
+	// CreateAdjustment makes up any difference left with a reconciled
+	// transaction dated on the statement date.
+	CreateAdjustment bool `json:"create_adjustment"`
+
+	// DryRun works out the difference without reconciling anything.
+	DryRun bool `json:"dry_run"`
+}
+
+func validateReconciliation(v *validator.Validator, params *CreateReconciliationParams) {
+	v.Check(!params.StatementDate.IsZero(), "statement_date", "Must be provided")
+
+	ids := slices.Clone(params.TransactionIDs)
+	slices.Sort(ids)
+	v.Check(len(slices.Compact(ids)) == len(params.TransactionIDs), "transaction_ids", "Must not repeat a transaction")
+}
+
+func adjustmentTitle(statementDate time.Time) string {
+	return fmt.Sprintf("[RECONCILIATION] STATEMENT OF %s", statementDate.Format(time.DateOnly))
+}
+
+// Create reconciles an account against a statement: the ticked transactions
+// and the adjustment, if one is needed, are marked as reconciled and recorded
+// along with the statement in a single transaction. The account is locked
+// meanwhile, so two reconciliations of the same account can't interleave.
+func (s *ReconciliationService) Create(userID, accountID int32, params *CreateReconciliationParams) (*ReconciliationDetails, error) {
+	v := validator.New()
+	if validateReconciliation(v, params); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	if accountID < 1 || userID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	_, err = qtx.GetAccountByIDForUpdate(ctx, store.GetAccountByIDForUpdateParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	previous, err := qtx.ListReconciliations(ctx, store.ListReconciliationsParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(previous) > 0 {
+		v.Check(!params.StatementDate.Before(previous[0].StatementDate), "statement_date", "Must not be before the statement date of the last reconciliation")
+	}
+
+	ticked, err := qtx.GetTransactionsByIDs(ctx, store.GetTransactionsByIDsParams{
+		Ids:    params.TransactionIDs,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The statement date includes the whole day.
+	statementEnd := params.StatementDate.AddDate(0, 0, 1)
+
+	var tickedCents int64
+	for _, row := range ticked {
+		transaction := row.Transaction
+		v.Check(transaction.AccountID == accountID, "transaction_ids", "Must be transactions of the account")
+		v.Check(transaction.Status == store.TransactionStatusCleared, "transaction_ids", "Must be cleared transactions that aren't reconciled yet")
+		v.Check(transaction.Date.Before(statementEnd), "transaction_ids", "Must be dated on or before statement_date")
+		tickedCents += transaction.AmountCents
+	}
+	v.Check(len(ticked) == len(params.TransactionIDs), "transaction_ids", "Must be transactions of the account")
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	reconciledCents, err := qtx.GetReconciledBalance(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := &ReconciliationDetails{
+		Reconciliation: store.Reconciliation{
+			AccountID:              accountID,
+			StatementDate:          params.StatementDate,
+			StatementBalanceCents:  params.StatementBalanceCents,
+			ReconciledBalanceCents: reconciledCents + tickedCents,
+		},
+		TransactionIDs: orEmpty(params.TransactionIDs),
+	}
+	reconciliation.DifferenceCents = params.StatementBalanceCents - reconciliation.ReconciledBalanceCents
+
+	if params.DryRun {
+		return reconciliation, nil
+	}
+
+	if reconciliation.DifferenceCents != 0 && !params.CreateAdjustment {
+		v.AddError("statement_balance_cents", fmt.Sprintf("Differs by %d from the reconciled transactions. Tick the missing transactions or create an adjustment", reconciliation.DifferenceCents))
+		return nil, v.GetErrors()
+	}
+
+	rowsAffected, err := qtx.ReconcileTransactions(ctx, store.ReconcileTransactionsParams{
+		AccountID: accountID,
+		Ids:       params.TransactionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The transactions themselves aren't locked, so one may have changed
+	// since it was checked.
+	if rowsAffected != int64(len(params.TransactionIDs)) {
+		return nil, database.ErrEditConflict
+	}
+
+	if reconciliation.DifferenceCents != 0 {
+		// Like the initial balance, the adjustment corrects the balance
+		// rather than being income or spending.
+		adjustment, err := qtx.CreateTransactionWithDate(ctx, store.CreateTransactionWithDateParams{
+			AccountID:   accountID,
+			AmountCents: reconciliation.DifferenceCents,
+			CategoryID:  database.InitialCategoryID(),
+			Title:       adjustmentTitle(params.StatementDate),
+			Date:        params.StatementDate,
+			Status:      store.NullTransactionStatus{TransactionStatus: store.TransactionStatusReconciled, Valid: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		reconciliation.AdjustmentTransactionID = &adjustment.ID
+		reconciliation.TransactionIDs = append(reconciliation.TransactionIDs, adjustment.ID)
+	}
+
+	reconciliation.Reconciliation, err = qtx.CreateReconciliation(ctx, store.CreateReconciliationParams{
+		AccountID:               reconciliation.AccountID,
+		StatementDate:           reconciliation.StatementDate,
+		StatementBalanceCents:   reconciliation.StatementBalanceCents,
+		ReconciledBalanceCents:  reconciliation.ReconciledBalanceCents,
+		DifferenceCents:         reconciliation.DifferenceCents,
+		AdjustmentTransactionID: reconciliation.AdjustmentTransactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = qtx.AddReconciliationTransactions(ctx, store.AddReconciliationTransactionsParams{
+		ReconciliationID: reconciliation.ID,
+		TransactionIds:   reconciliation.TransactionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateBalances(ctx, qtx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciliation, nil
+}
+
+// List returns the reconciliations of an account, latest statement first.
+// Their differences show how far the account had drifted from each statement.
+func (s *ReconciliationService) List(userID, accountID int32) ([]store.Reconciliation, error) {
+	if accountID < 1 || userID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	ctx := context.Background()
+
+	_, err := s.queries.GetAccountByID(ctx, store.GetAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	reconciliations, err := s.queries.ListReconciliations(ctx, store.ListReconciliationsParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orEmpty(reconciliations), nil
+}
+
+func (s *ReconciliationService) GetByID(userID, accountID, reconciliationID int32) (*ReconciliationDetails, error) {
+	if reconciliationID < 1 || accountID < 1 || userID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	ctx := context.Background()
+
+	reconciliation, err := s.queries.GetReconciliationByID(ctx, store.GetReconciliationByIDParams{
+		ID:        reconciliationID,
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	transactionIDs, err := s.queries.GetReconciliationTransactionIDs(ctx, reconciliation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconciliationDetails{
+		Reconciliation: reconciliation,
+		TransactionIDs: orEmpty(transactionIDs),
+	}, nil
+}
+
+// DeleteByID undoes the latest reconciliation of an account: its transactions
+// are cleared again and its adjustment, if it has one, is deleted. Earlier
+// reconciliations can't be deleted, as the later ones build on them.
+func (s *ReconciliationService) DeleteByID(userID, accountID, reconciliationID int32) error {
+	if reconciliationID < 1 || accountID < 1 || userID < 1 {
+		return database.ErrRecordNotFound
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	_, err = qtx.GetAccountByIDForUpdate(ctx, store.GetAccountByIDForUpdateParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return database.ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	reconciliations, err := qtx.ListReconciliations(ctx, store.ListReconciliationsParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(reconciliations, func(r store.Reconciliation) bool {
+		return r.ID == reconciliationID
+	})
+	switch {
+	case index == -1:
+		return database.ErrRecordNotFound
+	case index > 0:
+		return ErrNotLatestReconciliation
+	}
+	reconciliation := reconciliations[index]
+
+	transactionIDs, err := qtx.GetReconciliationTransactionIDs(ctx, reconciliation.ID)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.UnreconcileTransactions(ctx, store.UnreconcileTransactionsParams{
+		AccountID: accountID,
+		Ids:       transactionIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	var attachmentKeys []string
+	if reconciliation.AdjustmentTransactionID != nil {
+		attachmentKeys, err = deleteTransactionsUnchecked(ctx, qtx, userID, *reconciliation.AdjustmentTransactionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = qtx.DeleteReconciliation(ctx, reconciliation.ID)
+	if err != nil {
+		return err
+	}
+
+	err = updateBalances(ctx, qtx, userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
+	return nil
+}
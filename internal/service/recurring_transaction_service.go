@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/ical"
+	"github.com/Quak1/gokei/internal/recurrence"
 	"github.com/Quak1/gokei/pkg/validator"
 )
 
@@ -23,7 +28,52 @@ func NewRecurringTransactionService(queries store.QuerierTx, db *sql.DB) *Recurr
 }
 
 func validateRecurringTransaction(v *validator.Validator, transaction *store.RecurringTransaction) {
-	// TODO validate transaction
+	v.Check(validator.NonZero(transaction.AccountID), "account_id", "Must be provided")
+
+	v.Check(validator.NonZero(transaction.CategoryID), "category_id", "Must be provided")
+
+	v.Check(validator.NonZero(transaction.Title), "title", "Must be provided")
+	v.Check(validator.MaxLength(transaction.Title, 100), "title", "Must not be more than 100 bytes long")
+
+	v.Check(validator.PermittedValue(transaction.Frequency,
+		store.RecurrenceFrequencyDaily,
+		store.RecurrenceFrequencyWeekly,
+		store.RecurrenceFrequencyMonthly,
+		store.RecurrenceFrequencyYearly,
+	), "frequency", "Invalid frequency. Valid frequencies are daily, weekly, monthly, and yearly")
+
+	v.Check(transaction.Interval > 0, "interval", "Must be greater than zero")
+
+	v.Check(validator.NonZero(transaction.StartDate), "start_date", "Must be provided")
+	if transaction.EndDate != nil {
+		v.Check(!transaction.EndDate.Before(transaction.StartDate), "end_date", "Must not be before start_date")
+	}
+
+	if transaction.DayMonth != nil {
+		v.Check(*transaction.DayMonth >= 1 && *transaction.DayMonth <= 31, "day_month", "Must be between 1 and 31")
+		v.Check(validator.PermittedValue(transaction.Frequency,
+			store.RecurrenceFrequencyMonthly,
+			store.RecurrenceFrequencyYearly,
+		), "day_month", "Only allowed for monthly and yearly frequencies")
+	}
+
+	if transaction.DayWeek != nil {
+		v.Check(*transaction.DayWeek >= 0 && *transaction.DayWeek <= 6, "day_week", "Must be between 0 (Sunday) and 6 (Saturday)")
+		v.Check(transaction.Frequency == store.RecurrenceFrequencyWeekly, "day_week", "Only allowed for weekly frequency")
+	} else {
+		v.Check(transaction.Frequency != store.RecurrenceFrequencyWeekly, "day_week", "Must be provided for weekly frequency")
+	}
+
+	if transaction.MaxOccurrences != nil {
+		v.Check(*transaction.MaxOccurrences > 0, "max_occurrences", "Must be greater than zero")
+	}
+}
+
+// validateRecurringAmount checks the amount as received from the client, before
+// it's narrowed to the INT column.
+func validateRecurringAmount(v *validator.Validator, amountCents int64) {
+	v.Check(amountCents != 0, "amount_cents", "Must not be zero")
+	v.Check(amountCents >= math.MinInt32 && amountCents <= math.MaxInt32, "amount_cents", fmt.Sprintf("Must be between %d and %d", math.MinInt32, math.MaxInt32))
 }
 
 func (s *RecurringTransactionService) GetAll(userID int32) ([]*store.RecurringTransaction, error) {
@@ -40,7 +90,53 @@ func (s *RecurringTransactionService) GetAll(userID int32) ([]*store.RecurringTr
 	return transactions, nil
 }
 
-func (s *RecurringTransactionService) Create(userID int32, params *store.CreateRecurringTransactionParams) (*store.RecurringTransaction, error) {
+type CreateRecurringTransactionParams struct {
+	AccountID      int32                     `json:"account_id"`
+	AmountCents    int64                     `json:"amount_cents"`
+	CategoryID     int32                     `json:"category_id"`
+	Title          string                    `json:"title"`
+	Note           string                    `json:"note"`
+	Frequency      store.RecurrenceFrequency `json:"frequency"`
+	Interval       *int32                    `json:"interval"`
+	StartDate      time.Time                 `json:"start_date"`
+	EndDate        *time.Time                `json:"end_date"`
+	DayMonth       *int32                    `json:"day_month"`
+	DayWeek        *int32                    `json:"day_week"`
+	MaxOccurrences *int32                    `json:"max_occurrences"`
+	IsActive       *bool                     `json:"is_active"`
+	// RRule can be sent instead of the schedule fields.
+	RRule string `json:"rrule"`
+}
+
+// applyRRULE fills the schedule fields of params from its RRULE.
+func applyRRULE(v *validator.Validator, params *CreateRecurringTransactionParams) {
+	if params.Frequency != "" || params.Interval != nil || params.EndDate != nil ||
+		params.DayMonth != nil || params.DayWeek != nil || params.MaxOccurrences != nil {
+		v.AddError("rrule", "Can't be combined with frequency, interval, end_date, day_month, day_week or max_occurrences")
+		return
+	}
+
+	rule, err := ical.ParseRRULE(params.RRule)
+	if err != nil {
+		v.AddError("rrule", err.Error())
+		return
+	}
+
+	params.Frequency = rule.Frequency
+	params.Interval = &rule.Interval
+	params.EndDate = rule.EndDate
+	params.DayMonth = rule.DayMonth
+	params.DayWeek = rule.DayWeek
+	params.MaxOccurrences = rule.MaxOccurrences
+
+	// Weekly rules without BYDAY repeat on the weekday they start on.
+	if params.Frequency == store.RecurrenceFrequencyWeekly && params.DayWeek == nil && !params.StartDate.IsZero() {
+		dayWeek := int32(params.StartDate.Weekday())
+		params.DayWeek = &dayWeek
+	}
+}
+
+func (s *RecurringTransactionService) Create(userID int32, params *CreateRecurringTransactionParams) (*store.RecurringTransaction, error) {
 	if params.CategoryID == database.InitialCategoryID() {
 		return nil, ErrTransactionWithInitialCategory
 	}
@@ -48,16 +144,24 @@ func (s *RecurringTransactionService) Create(userID int32, params *store.CreateR
 		return nil, database.ErrInvalidCategory
 	}
 
+	v := validator.New()
+
+	if params.RRule != "" {
+		if applyRRULE(v, params); !v.Valid() {
+			return nil, v.GetErrors()
+		}
+	}
+
 	transaction := &store.RecurringTransaction{
 		AccountID: params.AccountID,
 
 		Title:       params.Title,
 		CategoryID:  params.CategoryID,
-		AmountCents: params.AmountCents,
+		AmountCents: int32(params.AmountCents),
 		Note:        params.Note,
 
 		Frequency: params.Frequency,
-		Interval:  params.Interval,
+		Interval:  1,
 		StartDate: params.StartDate,
 		EndDate:   params.EndDate,
 
@@ -65,10 +169,16 @@ func (s *RecurringTransactionService) Create(userID int32, params *store.CreateR
 		DayWeek:  params.DayWeek,
 
 		MaxOccurrences: params.MaxOccurrences,
-		IsActive:       params.IsActive,
+		IsActive:       true,
+	}
+	if params.Interval != nil {
+		transaction.Interval = *params.Interval
+	}
+	if params.IsActive != nil {
+		transaction.IsActive = *params.IsActive
 	}
 
-	v := validator.New()
+	validateRecurringAmount(v, params.AmountCents)
 	if validateRecurringTransaction(v, transaction); !v.Valid() {
 		return nil, v.GetErrors()
 	}
@@ -88,9 +198,23 @@ func (s *RecurringTransactionService) Create(userID int32, params *store.CreateR
 		}
 	}
 
-	newTransaction, err := s.queries.CreateRecurringTransaction(ctx, *params)
+	newTransaction, err := s.queries.CreateRecurringTransaction(ctx, store.CreateRecurringTransactionParams{
+		AccountID:      transaction.AccountID,
+		AmountCents:    transaction.AmountCents,
+		CategoryID:     transaction.CategoryID,
+		Title:          transaction.Title,
+		Note:           transaction.Note,
+		Frequency:      transaction.Frequency,
+		Interval:       transaction.Interval,
+		StartDate:      transaction.StartDate,
+		EndDate:        transaction.EndDate,
+		DayMonth:       transaction.DayMonth,
+		DayWeek:        transaction.DayWeek,
+		MaxOccurrences: transaction.MaxOccurrences,
+		IsActive:       transaction.IsActive,
+	})
 	if err != nil {
-		return nil, database.HandleForeignKeyError(err)
+		return nil, database.HandleForeignKeyError(database.HandleCheckConstraintError(err))
 	}
 
 	return &newTransaction, nil
@@ -116,3 +240,445 @@ func (s *RecurringTransactionService) GetByID(userID, transactionID int32) (*sto
 
 	return &transaction, nil
 }
+
+func (s *RecurringTransactionService) DeleteByID(userID, transactionID int32) error {
+	if transactionID < 1 || userID < 1 {
+		return database.ErrRecordNotFound
+	}
+
+	result, err := s.queries.DeleteRecurringTransaction(context.Background(), store.DeleteRecurringTransactionParams{
+		ID:     transactionID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type UpdateRecurringTransactionParams struct {
+	AmountCents *int64                     `json:"amount_cents"`
+	CategoryID  *int32                     `json:"category_id"`
+	Title       *string                    `json:"title"`
+	Note        *string                    `json:"note"`
+	Frequency   *store.RecurrenceFrequency `json:"frequency"`
+	Interval    *int32                     `json:"interval"`
+	IsActive    *bool                      `json:"is_active"`
+
+	// The limits and days of the rule are optional, so they are cleared by
+	// sending them as null.
+	EndDate        Nullable[time.Time] `json:"end_date"`
+	DayMonth       Nullable[int32]     `json:"day_month"`
+	DayWeek        Nullable[int32]     `json:"day_week"`
+	MaxOccurrences Nullable[int32]     `json:"max_occurrences"`
+}
+
+func (s *RecurringTransactionService) UpdateByID(userID, transactionID int32, updateParams *UpdateRecurringTransactionParams) (*store.RecurringTransaction, error) {
+	if transactionID < 1 || userID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	ctx := context.Background()
+
+	transaction, err := s.queries.GetRecurringTransactionByID(ctx, store.GetRecurringTransactionByIDParams{
+		ID:     transactionID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	v := validator.New()
+
+	if updateParams.AmountCents != nil {
+		validateRecurringAmount(v, *updateParams.AmountCents)
+		transaction.AmountCents = int32(*updateParams.AmountCents)
+	}
+	if updateParams.CategoryID != nil {
+		if *updateParams.CategoryID == database.InitialCategoryID() {
+			return nil, ErrTransactionWithInitialCategory
+		}
+		transaction.CategoryID = *updateParams.CategoryID
+	}
+	if updateParams.Title != nil {
+		transaction.Title = *updateParams.Title
+	}
+	if updateParams.Note != nil {
+		transaction.Note = *updateParams.Note
+	}
+	if updateParams.Frequency != nil && *updateParams.Frequency != transaction.Frequency {
+		// The day fields only make sense for some frequencies, so they are
+		// dropped on a change unless they're sent along with it.
+		transaction.Frequency = *updateParams.Frequency
+		transaction.DayMonth = nil
+		transaction.DayWeek = nil
+	}
+	if updateParams.Interval != nil {
+		transaction.Interval = *updateParams.Interval
+	}
+	if updateParams.EndDate.Set {
+		transaction.EndDate = updateParams.EndDate.Value
+	}
+	if updateParams.DayMonth.Set {
+		transaction.DayMonth = updateParams.DayMonth.Value
+	}
+	if updateParams.DayWeek.Set {
+		transaction.DayWeek = updateParams.DayWeek.Value
+	}
+	if updateParams.MaxOccurrences.Set {
+		transaction.MaxOccurrences = updateParams.MaxOccurrences.Value
+	}
+	if updateParams.IsActive != nil {
+		// What was due while the rule was paused is left out of the catch up.
+		if *updateParams.IsActive && !transaction.IsActive {
+			now := time.Now().UTC()
+			transaction.ResumedAt = &now
+		}
+		transaction.IsActive = *updateParams.IsActive
+	}
+
+	if validateRecurringTransaction(v, &transaction); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	result, err := s.queries.UpdateRecurringTransaction(ctx, store.UpdateRecurringTransactionParams{
+		AmountCents:    transaction.AmountCents,
+		CategoryID:     transaction.CategoryID,
+		Title:          transaction.Title,
+		Note:           transaction.Note,
+		Frequency:      transaction.Frequency,
+		Interval:       transaction.Interval,
+		EndDate:        transaction.EndDate,
+		DayMonth:       transaction.DayMonth,
+		DayWeek:        transaction.DayWeek,
+		MaxOccurrences: transaction.MaxOccurrences,
+		IsActive:       transaction.IsActive,
+		ResumedAt:      transaction.ResumedAt,
+		ID:             transaction.ID,
+		UserID:         userID,
+		Version:        transaction.Version,
+	})
+	if err != nil {
+		return nil, database.HandleForeignKeyError(database.HandleCheckConstraintError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, database.ErrEditConflict
+	}
+
+	return &transaction, nil
+}
+
+func (s *RecurringTransactionService) SetActiveByID(userID, transactionID int32, isActive bool) (*store.RecurringTransaction, error) {
+	return s.UpdateByID(userID, transactionID, &UpdateRecurringTransactionParams{
+		IsActive: &isActive,
+	})
+}
+
+// PostDue materializes every occurrence of the active recurring transactions
+// that is due on or before now and hasn't been posted yet, going back at most
+// backfill (see recurrence.Due). Each occurrence is posted in its own database
+// transaction, dated on the day it was due, so it's safe to run from several
+// server replicas at the same time.
+func (s *RecurringTransactionService) PostDue(now time.Time, backfill time.Duration) ([]*store.RecurringTransactionOccurrence, error) {
+	ctx := context.Background()
+
+	users, err := s.queries.GetAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var posted []*store.RecurringTransactionOccurrence
+	var errs []error
+
+	for _, user := range users {
+		transactions, err := s.queries.GetActiveRecurringTransactions(ctx, store.GetActiveRecurringTransactionsParams{
+			UserID:     user.ID,
+			Now:        now,
+			EndedAfter: recurrence.Date(now.Add(-backfill)),
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, transaction := range transactions {
+			var last *time.Time
+			occurrence, err := s.queries.GetLastOccurrence(ctx, transaction.ID)
+			if err == nil {
+				last = &occurrence.OccurrenceDate
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				errs = append(errs, fmt.Errorf("recurring transaction %d: %w", transaction.ID, err))
+				continue
+			}
+
+			exceptions, err := s.queries.GetRecurringTransactionExceptions(ctx, transaction.ID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("recurring transaction %d: %w", transaction.ID, err))
+				continue
+			}
+
+			for _, due := range recurrence.Due(&transaction, exceptions, last, now, backfill) {
+				occurrence, err := s.postOccurrence(user.ID, transaction.ID, due)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("recurring transaction %d: %w", transaction.ID, err))
+					break
+				}
+				if occurrence != nil {
+					posted = append(posted, occurrence)
+				}
+			}
+		}
+	}
+
+	return posted, errors.Join(errs...)
+}
+
+// postOccurrence creates the transaction for a single occurrence, records it and
+// updates the account balance. It returns nil when the occurrence was already
+// posted or another process is currently posting it.
+func (s *RecurringTransactionService) postOccurrence(userID, transactionID int32, due recurrence.Occurrence) (*store.RecurringTransactionOccurrence, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	recurring, err := qtx.LockRecurringTransaction(ctx, transactionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+	if !recurring.IsActive {
+		return nil, nil
+	}
+
+	_, err = qtx.GetOccurrenceForDate(ctx, store.GetOccurrenceForDateParams{
+		RecurringTransactionID: recurring.ID,
+		OccurrenceDate:         due.ScheduledDate,
+	})
+	if err == nil {
+		return nil, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	transaction, err := qtx.CreateTransactionWithDate(ctx, store.CreateTransactionWithDateParams{
+		AccountID:   recurring.AccountID,
+		AmountCents: int64(due.AmountCents),
+		CategoryID:  recurring.CategoryID,
+		Title:       recurring.Title,
+		Note:        recurring.Note,
+		Date:        due.Date,
+	})
+	if err != nil {
+		return nil, database.HandleForeignKeyError(err)
+	}
+
+	occurrence, err := qtx.CreateOccurrence(ctx, store.CreateOccurrenceParams{
+		RecurringTransactionID: recurring.ID,
+		TransactionID:          &transaction.ID,
+		OccurrenceDate:         due.ScheduledDate,
+	})
+	if err != nil {
+		if database.IsUniqueContraintViolation(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
+		ID:     recurring.AccountID,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &occurrence, nil
+}
+
+func validatePreviewCount(v *validator.Validator, count int) {
+	v.Check(count > 0, "count", "Must be greater than zero")
+	v.Check(count <= 100, "count", "Must not be more than 100")
+}
+
+// Preview returns the next count occurrences on or after from, with the
+// recurring transaction's exceptions applied.
+func (s *RecurringTransactionService) Preview(userID, transactionID int32, from time.Time, count int) ([]recurrence.Occurrence, error) {
+	v := validator.New()
+	if validatePreviewCount(v, count); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	transaction, err := s.GetByID(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := s.queries.GetRecurringTransactionExceptions(context.Background(), transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return recurrence.NextOccurrences(transaction, exceptions, from, count), nil
+}
+
+func (s *RecurringTransactionService) GetExceptions(userID, transactionID int32) ([]*store.RecurringTransactionException, error) {
+	transaction, err := s.GetByID(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.queries.GetRecurringTransactionExceptions(context.Background(), transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := make([]*store.RecurringTransactionException, len(data))
+	for i, v := range data {
+		exceptions[i] = &v
+	}
+
+	return exceptions, nil
+}
+
+type CreateRecurringTransactionExceptionParams struct {
+	OccurrenceDate time.Time  `json:"occurrence_date"`
+	Skip           bool       `json:"skip"`
+	NewDate        *time.Time `json:"new_date"`
+	AmountCents    *int64     `json:"amount_cents"`
+}
+
+func validateRecurringTransactionException(v *validator.Validator, transaction *store.RecurringTransaction, params *CreateRecurringTransactionExceptionParams) {
+	v.Check(validator.NonZero(params.OccurrenceDate), "occurrence_date", "Must be provided")
+	if validator.NonZero(params.OccurrenceDate) {
+		v.Check(recurrence.IsScheduled(transaction, params.OccurrenceDate), "occurrence_date", "Not a scheduled date of this recurring transaction")
+	}
+
+	if params.Skip {
+		v.Check(params.NewDate == nil && params.AmountCents == nil, "skip", "Can't be combined with new_date or amount_cents")
+	} else {
+		v.Check(params.NewDate != nil || params.AmountCents != nil, "skip", "Must be set when neither new_date nor amount_cents are provided")
+	}
+
+	if params.AmountCents != nil {
+		validateRecurringAmount(v, *params.AmountCents)
+	}
+}
+
+// CreateException records a change to a single occurrence of a recurring
+// transaction: skipping it, moving it to another date or changing its amount.
+// Occurrences that were already posted can't be changed.
+func (s *RecurringTransactionService) CreateException(userID, transactionID int32, params *CreateRecurringTransactionExceptionParams) (*store.RecurringTransactionException, error) {
+	transaction, err := s.GetByID(userID, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+	if validateRecurringTransactionException(v, transaction, params); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	ctx := context.Background()
+	occurrenceDate := recurrence.Date(params.OccurrenceDate)
+
+	_, err = s.queries.GetOccurrenceForDate(ctx, store.GetOccurrenceForDateParams{
+		RecurringTransactionID: transaction.ID,
+		OccurrenceDate:         occurrenceDate,
+	})
+	if err == nil {
+		v.AddError("occurrence_date", "This occurrence was already posted")
+		return nil, v.GetErrors()
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	exceptionParams := store.CreateRecurringTransactionExceptionParams{
+		RecurringTransactionID: transaction.ID,
+		OccurrenceDate:         occurrenceDate,
+		Skip:                   params.Skip,
+	}
+	if params.NewDate != nil {
+		newDate := recurrence.Date(*params.NewDate)
+		exceptionParams.NewDate = &newDate
+	}
+	if params.AmountCents != nil {
+		amountCents := int32(*params.AmountCents)
+		exceptionParams.AmountCents = &amountCents
+	}
+
+	exception, err := s.queries.CreateRecurringTransactionException(ctx, exceptionParams)
+	if err != nil {
+		if database.IsUniqueContraintViolation(err) {
+			v.AddError("occurrence_date", "An exception already exists for this occurrence")
+			return nil, v.GetErrors()
+		}
+		return nil, database.HandleCheckConstraintError(err)
+	}
+
+	return &exception, nil
+}
+
+func (s *RecurringTransactionService) DeleteException(userID, transactionID, exceptionID int32) error {
+	if exceptionID < 1 {
+		return database.ErrRecordNotFound
+	}
+
+	transaction, err := s.GetByID(userID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.queries.DeleteRecurringTransactionException(context.Background(), store.DeleteRecurringTransactionExceptionParams{
+		ID:                     exceptionID,
+		RecurringTransactionID: transaction.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,677 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+// gokeiExport is a JSON export as read back for a restore, but for its
+// transactions. Those come last in the document and can be many, so they are
+// read and restored one at a time.
+type gokeiExport struct {
+	Version           int     `json:"version"`
+	DateFrom          *string `json:"date_from"`
+	DateTo            *string `json:"date_to"`
+	InitialCategoryID int32   `json:"initial_category_id"`
+	exportData
+}
+
+type RestoreResult struct {
+	Accounts              []store.Account  `json:"accounts"`
+	NewCategories         []store.Category `json:"new_categories"`
+	Tags                  int              `json:"tags"`
+	Transactions          int              `json:"transactions"`
+	RecurringTransactions int              `json:"recurring_transactions"`
+}
+
+// ImportGokei restores a JSON export into the user's data, in a single
+// transaction. Every account, transaction and recurring transaction is
+// created anew, so the IDs differ from the ones in the file. Categories and
+// tags are matched by name with the ones the user can already use, and only
+// created when missing.
+func (s *ImportService) ImportGokei(userID int32, file io.Reader) (*RestoreResult, error) {
+	reader := newExportReader(file)
+
+	export, err := reader.head()
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := checkGokeiExport(export)
+	if err != nil {
+		return nil, restoreFileError(err.Error())
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	result := &RestoreResult{
+		Accounts:              []store.Account{},
+		Tags:                  len(export.Tags),
+		RecurringTransactions: len(export.RecurringTransactions),
+	}
+
+	categoryIDs, newCategories, err := restoreCategories(ctx, qtx, userID, export)
+	if err != nil {
+		return nil, err
+	}
+	result.NewCategories = newCategories
+
+	tagIDs := make(map[int32]int32, len(export.Tags))
+	for _, tag := range export.Tags {
+		restored, err := qtx.UpsertTag(ctx, store.UpsertTagParams{
+			UserID: userID,
+			Name:   tag.Name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tagIDs[tag.ID] = restored.ID
+	}
+
+	accountIDs := make(map[int32]int32, len(export.Accounts))
+	for _, account := range export.Accounts {
+		// The balance is restored as it was instead of being worked out from
+		// the transactions, so the account shows what it showed before.
+		restored, err := qtx.CreateAccount(ctx, store.CreateAccountParams{
+			Type:         account.Type,
+			Name:         account.Name,
+			UserID:       userID,
+			BalanceCents: account.BalanceCents,
+		})
+		if err != nil {
+			return nil, err
+		}
+		accountIDs[account.ID] = restored.ID
+		result.Accounts = append(result.Accounts, restored)
+	}
+
+	transactionIDs := make(map[int32]int32)
+	pendingCents := make(map[int32]int64)
+	err = reader.transactions(func(transaction *exportTransaction) error {
+		// Transactions exported before they had a status were all cleared.
+		if transaction.Status == "" {
+			transaction.Status = store.TransactionStatusCleared
+		}
+
+		if err := refs.checkTransaction(transaction); err != nil {
+			return restoreFileError(err.Error())
+		}
+
+		restored, err := qtx.CreateTransactionWithDate(ctx, store.CreateTransactionWithDateParams{
+			AccountID:   accountIDs[transaction.AccountID],
+			AmountCents: transaction.AmountCents,
+			CategoryID:  categoryIDs[transaction.CategoryID],
+			Title:       transaction.Title,
+			Attachment:  transaction.Attachment,
+			Note:        transaction.Note,
+			Date:        transaction.Date,
+			Status:      store.NullTransactionStatus{TransactionStatus: transaction.Status, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		transactionIDs[transaction.ID] = restored.ID
+
+		if transaction.Status == store.TransactionStatusPending {
+			pendingCents[restored.AccountID] += transaction.AmountCents
+		}
+
+		for _, split := range transaction.Splits {
+			_, err := qtx.CreateTransactionSplit(ctx, store.CreateTransactionSplitParams{
+				TransactionID: restored.ID,
+				CategoryID:    categoryIDs[split.CategoryID],
+				AmountCents:   split.AmountCents,
+				Note:          split.Note,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, tagID := range transaction.TagIDs {
+			err := qtx.AddTransactionTag(ctx, store.AddTransactionTagParams{
+				TransactionID: restored.ID,
+				TagID:         tagIDs[tagID],
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Transactions++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := refs.checkLinks(export); err != nil {
+		return nil, restoreFileError(err.Error())
+	}
+
+	// The accounts were created with their balance as cleared, which leaves
+	// out the pending transactions.
+	for i, account := range result.Accounts {
+		if pendingCents[account.ID] == 0 {
+			continue
+		}
+
+		result.Accounts[i].ClearedBalanceCents, err = qtx.UpdateClearedBalance(ctx, store.UpdateClearedBalanceParams{
+			ClearedBalanceCents: -pendingCents[account.ID],
+			ID:                  account.ID,
+			UserID:              userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	recurringIDs := make(map[int32]int32, len(export.RecurringTransactions))
+	for _, rt := range export.RecurringTransactions {
+		restored, err := qtx.CreateRecurringTransaction(ctx, store.CreateRecurringTransactionParams{
+			AccountID:      accountIDs[rt.AccountID],
+			AmountCents:    rt.AmountCents,
+			CategoryID:     categoryIDs[rt.CategoryID],
+			Title:          rt.Title,
+			Note:           rt.Note,
+			Frequency:      rt.Frequency,
+			Interval:       rt.Interval,
+			StartDate:      rt.StartDate,
+			EndDate:        rt.EndDate,
+			DayMonth:       rt.DayMonth,
+			DayWeek:        rt.DayWeek,
+			MaxOccurrences: rt.MaxOccurrences,
+			IsActive:       rt.IsActive,
+			ResumedAt:      rt.ResumedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		recurringIDs[rt.ID] = restored.ID
+	}
+
+	for _, exception := range export.RecurringExceptions {
+		_, err := qtx.CreateRecurringTransactionException(ctx, store.CreateRecurringTransactionExceptionParams{
+			RecurringTransactionID: recurringIDs[exception.RecurringTransactionID],
+			OccurrenceDate:         exception.OccurrenceDate,
+			Skip:                   exception.Skip,
+			NewDate:                exception.NewDate,
+			AmountCents:            exception.AmountCents,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, occurrence := range export.RecurringOccurrences {
+		arg := store.CreateOccurrenceParams{
+			RecurringTransactionID: recurringIDs[occurrence.RecurringTransactionID],
+			OccurrenceDate:         occurrence.OccurrenceDate,
+		}
+		if occurrence.TransactionID != nil {
+			transactionID := transactionIDs[*occurrence.TransactionID]
+			arg.TransactionID = &transactionID
+		}
+
+		_, err := qtx.CreateOccurrence(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, refund := range export.TransactionRefunds {
+		_, err := qtx.CreateTransactionRefund(ctx, store.CreateTransactionRefundParams{
+			TransactionID:       transactionIDs[refund.TransactionID],
+			RefundTransactionID: transactionIDs[refund.RefundTransactionID],
+			Reason:              refund.Reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, transfer := range export.Transfers {
+		arg := store.CreateTransferParams{
+			FromTransactionID: transactionIDs[transfer.FromTransactionID],
+			ToTransactionID:   transactionIDs[transfer.ToTransactionID],
+		}
+		if transfer.FeeTransactionID != nil {
+			feeID := transactionIDs[*transfer.FeeTransactionID]
+			arg.FeeTransactionID = &feeID
+		}
+
+		_, err := qtx.CreateTransfer(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reconciliationIDs := make(map[int32]int32, len(export.Reconciliations))
+	for _, reconciliation := range export.Reconciliations {
+		arg := store.CreateReconciliationParams{
+			AccountID:              accountIDs[reconciliation.AccountID],
+			StatementDate:          reconciliation.StatementDate,
+			StatementBalanceCents:  reconciliation.StatementBalanceCents,
+			ReconciledBalanceCents: reconciliation.ReconciledBalanceCents,
+			DifferenceCents:        reconciliation.DifferenceCents,
+		}
+		if reconciliation.AdjustmentTransactionID != nil {
+			adjustmentID := transactionIDs[*reconciliation.AdjustmentTransactionID]
+			arg.AdjustmentTransactionID = &adjustmentID
+		}
+
+		restored, err := qtx.CreateReconciliation(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		reconciliationIDs[reconciliation.ID] = restored.ID
+	}
+
+	for _, link := range export.ReconciliationTransactions {
+		err := qtx.AddReconciliationTransactions(ctx, store.AddReconciliationTransactionsParams{
+			ReconciliationID: reconciliationIDs[link.ReconciliationID],
+			TransactionIds:   []int32{transactionIDs[link.TransactionID]},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// exportRefs are the IDs of the items checked so far in an export, which
+// later items may refer to.
+type exportRefs struct {
+	accounts     map[int32]bool
+	categories   map[int32]bool
+	tags         map[int32]bool
+	recurring    map[int32]bool
+	transactions map[int32]bool
+}
+
+// checkGokeiExport makes sure the export, but for its transactions, can be
+// restored: every item must be valid and refer only to items within the
+// file, as IDs from another instance mean nothing here. It returns the first
+// problem found, or the IDs the transactions may refer to.
+//
+// The items referring to transactions are only checked once they have all
+// been read, by exportRefs.checkLinks.
+func checkGokeiExport(export *gokeiExport) (*exportRefs, error) {
+	if export.Version < 1 || export.Version > ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+	if export.DateFrom != nil || export.DateTo != nil {
+		return nil, errors.New("must be a full export, without date_from or date_to")
+	}
+
+	accounts := make(map[int32]bool, len(export.Accounts))
+	for _, account := range export.Accounts {
+		if err := checkExportItem("account", account.ID, func(v *validator.Validator) {
+			validateAccount(v, &account)
+		}); err != nil {
+			return nil, err
+		}
+		accounts[account.ID] = true
+	}
+
+	categories := map[int32]bool{export.InitialCategoryID: true}
+	for _, category := range export.Categories {
+		if category.ID == export.InitialCategoryID {
+			continue
+		}
+		if err := checkExportItem("category", category.ID, func(v *validator.Validator) {
+			validateCategory(v, &category)
+		}); err != nil {
+			return nil, err
+		}
+		categories[category.ID] = true
+	}
+
+	tags := make(map[int32]bool, len(export.Tags))
+	for _, tag := range export.Tags {
+		if err := checkExportItem("tag", tag.ID, func(v *validator.Validator) {
+			validateTagName(v, "name", tag.Name)
+		}); err != nil {
+			return nil, err
+		}
+		tags[tag.ID] = true
+	}
+
+	recurring := make(map[int32]bool, len(export.RecurringTransactions))
+	for _, rt := range export.RecurringTransactions {
+		err := checkExportItem("recurring transaction", rt.ID, func(v *validator.Validator) {
+			validateRecurringTransaction(v, &rt)
+			validateRecurringAmount(v, int64(rt.AmountCents))
+
+			v.Check(accounts[rt.AccountID], "account_id", "Must be an account in the file")
+			v.Check(categories[rt.CategoryID], "category_id", "Must be a category in the file")
+		})
+		if err != nil {
+			return nil, err
+		}
+		recurring[rt.ID] = true
+	}
+
+	for _, exception := range export.RecurringExceptions {
+		if !recurring[exception.RecurringTransactionID] {
+			return nil, fmt.Errorf("recurring transaction exception %d: recurring_transaction_id: Must be a recurring transaction in the file", exception.ID)
+		}
+	}
+
+	return &exportRefs{
+		accounts:     accounts,
+		categories:   categories,
+		tags:         tags,
+		recurring:    recurring,
+		transactions: make(map[int32]bool),
+	}, nil
+}
+
+// checkTransaction checks a transaction of the export and records it, so
+// that the items linking transactions can refer to it.
+func (refs *exportRefs) checkTransaction(transaction *exportTransaction) error {
+	err := checkExportItem("transaction", transaction.ID, func(v *validator.Validator) {
+		validateTransaction(v, &transaction.Transaction)
+		validateSplits(v, transaction.AmountCents, splitParams(transaction.Splits))
+
+		v.Check(refs.accounts[transaction.AccountID], "account_id", "Must be an account in the file")
+		v.Check(refs.categories[transaction.CategoryID], "category_id", "Must be a category in the file")
+		for _, split := range transaction.Splits {
+			v.Check(refs.categories[split.CategoryID], "splits", "Every line must have a category in the file")
+		}
+		for _, tagID := range transaction.TagIDs {
+			v.Check(refs.tags[tagID], "tag_ids", "Must be tags in the file")
+		}
+	})
+	if err != nil {
+		return err
+	}
+	refs.transactions[transaction.ID] = true
+
+	return nil
+}
+
+// checkLinks checks the items of the export that link transactions, once all
+// of them have been read.
+func (refs *exportRefs) checkLinks(export *gokeiExport) error {
+	for _, occurrence := range export.RecurringOccurrences {
+		if !refs.recurring[occurrence.RecurringTransactionID] {
+			return fmt.Errorf("recurring transaction occurrence %d: recurring_transaction_id: Must be a recurring transaction in the file", occurrence.ID)
+		}
+		if id := occurrence.TransactionID; id != nil && !refs.transactions[*id] {
+			return fmt.Errorf("recurring transaction occurrence %d: transaction_id: Must be a transaction in the file", occurrence.ID)
+		}
+	}
+
+	refunded := make(map[int32]bool, len(export.TransactionRefunds))
+	for _, refund := range export.TransactionRefunds {
+		if !refs.transactions[refund.TransactionID] {
+			return fmt.Errorf("transaction refund %d: transaction_id: Must be a transaction in the file", refund.ID)
+		}
+		if !refs.transactions[refund.RefundTransactionID] || refunded[refund.RefundTransactionID] {
+			return fmt.Errorf("transaction refund %d: refund_transaction_id: Must be a transaction in the file that refunds only one other", refund.ID)
+		}
+		refunded[refund.RefundTransactionID] = true
+	}
+
+	inTransfer := make(map[int32]bool, len(export.Transfers)*2)
+	for _, transfer := range export.Transfers {
+		legs := map[string]*int32{
+			"from_transaction_id": &transfer.FromTransactionID,
+			"to_transaction_id":   &transfer.ToTransactionID,
+			"fee_transaction_id":  transfer.FeeTransactionID,
+		}
+		for _, field := range slices.Sorted(maps.Keys(legs)) {
+			id := legs[field]
+			if id == nil {
+				continue
+			}
+			if !refs.transactions[*id] || inTransfer[*id] {
+				return fmt.Errorf("transfer %d: %s: Must be a transaction in the file that is part of no other transfer", transfer.ID, field)
+			}
+			inTransfer[*id] = true
+		}
+	}
+
+	reconciliations := make(map[int32]bool, len(export.Reconciliations))
+	adjusted := make(map[int32]bool, len(export.Reconciliations))
+	for _, reconciliation := range export.Reconciliations {
+		if !refs.accounts[reconciliation.AccountID] {
+			return fmt.Errorf("reconciliation %d: account_id: Must be an account in the file", reconciliation.ID)
+		}
+		if id := reconciliation.AdjustmentTransactionID; id != nil {
+			if !refs.transactions[*id] || adjusted[*id] {
+				return fmt.Errorf("reconciliation %d: adjustment_transaction_id: Must be a transaction in the file that adjusts no other reconciliation", reconciliation.ID)
+			}
+			adjusted[*id] = true
+		}
+		reconciliations[reconciliation.ID] = true
+	}
+
+	reconciled := make(map[[2]int32]bool, len(export.ReconciliationTransactions))
+	for _, link := range export.ReconciliationTransactions {
+		if !reconciliations[link.ReconciliationID] {
+			return fmt.Errorf("reconciliation %d: id: Must be a reconciliation in the file", link.ReconciliationID)
+		}
+		key := [2]int32{link.ReconciliationID, link.TransactionID}
+		if !refs.transactions[link.TransactionID] || reconciled[key] {
+			return fmt.Errorf("reconciliation %d: transaction_id: Must be a transaction in the file, listed once", link.ReconciliationID)
+		}
+		reconciled[key] = true
+	}
+
+	return nil
+}
+
+// restoreFileError reports a problem with the file being restored.
+func restoreFileError(message string) error {
+	v := validator.New()
+	v.AddError("file", message)
+	return v.GetErrors()
+}
+
+// checkExportItem runs the usual validation of an item on one read from an
+// export, returning its first error along with which item it was found in.
+func checkExportItem(item string, id int32, validate func(v *validator.Validator)) error {
+	v := validator.New()
+	if validate(v); v.Valid() {
+		return nil
+	}
+
+	var validationErr *validator.ValidationError
+	errors.As(v.GetErrors(), &validationErr)
+
+	field := slices.Min(slices.Collect(maps.Keys(validationErr.Errors)))
+	return fmt.Errorf("%s %d: %s: %s", item, id, field, validationErr.Errors[field])
+}
+
+// restoreCategories maps the categories of the export to the ones the user
+// can use, matching names regardless of case and creating the missing ones.
+// Categories are only matched against the ones that existed before the
+// restore, so two categories sharing a name in the file stay apart.
+func restoreCategories(ctx context.Context, q store.Querier, userID int32, export *gokeiExport) (map[int32]int32, []store.Category, error) {
+	existing, err := q.GetAllCategories(ctx, store.GetAllCategoriesParams{
+		AdminID: database.AdminUserID(),
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byName := make(map[string]int32, len(existing))
+	for _, category := range existing {
+		if category.ID != database.InitialCategoryID() {
+			byName[strings.ToLower(category.Name)] = category.ID
+		}
+	}
+
+	ids := map[int32]int32{export.InitialCategoryID: database.InitialCategoryID()}
+	newCategories := []store.Category{}
+
+	for _, category := range export.Categories {
+		if category.ID == export.InitialCategoryID {
+			continue
+		}
+
+		if id, ok := byName[strings.ToLower(category.Name)]; ok {
+			ids[category.ID] = id
+			continue
+		}
+
+		created, err := q.CreateCategory(ctx, store.CreateCategoryParams{
+			UserID: userID,
+			Name:   category.Name,
+			Color:  category.Color,
+			Icon:   category.Icon,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[category.ID] = created.ID
+		newCategories = append(newCategories, created)
+	}
+
+	return ids, newCategories, nil
+}
+
+// exportReader reads a JSON export as a stream: everything before its
+// transactions at once, then the transactions one by one.
+type exportReader struct {
+	body    *bodyReader
+	dec     *json.Decoder
+	inArray bool
+}
+
+// bodyReader keeps the error of the reader it wraps, so that a body which
+// could not be read is not reported as a malformed export.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+func newExportReader(r io.Reader) *exportReader {
+	body := &bodyReader{r: r}
+	return &exportReader{body: body, dec: json.NewDecoder(body)}
+}
+
+// head reads every field of the export up to its transactions, which must
+// be the last field, and leaves the reader at the first of them.
+func (r *exportReader) head() (*gokeiExport, error) {
+	if !r.delim('{') {
+		return nil, r.malformed()
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for r.dec.More() {
+		token, err := r.dec.Token()
+		key, ok := token.(string)
+		if err != nil || !ok {
+			return nil, r.malformed()
+		}
+
+		if key == "transactions" {
+			token, err := r.dec.Token()
+			if err != nil {
+				return nil, r.malformed()
+			}
+			if token == nil {
+				continue
+			}
+			if token != json.Delim('[') {
+				return nil, r.malformed()
+			}
+			r.inArray = true
+			break
+		}
+
+		var value json.RawMessage
+		if err := r.dec.Decode(&value); err != nil {
+			return nil, r.malformed()
+		}
+		fields[key] = value
+	}
+
+	js, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var export gokeiExport
+	if err := json.Unmarshal(js, &export); err != nil {
+		return nil, r.malformed()
+	}
+
+	return &export, nil
+}
+
+// transactions calls fn with each transaction of the export in turn,
+// stopping at the first error, and then reads the end of the export.
+func (r *exportReader) transactions(fn func(transaction *exportTransaction) error) error {
+	if r.inArray {
+		for r.dec.More() {
+			var transaction exportTransaction
+			if err := r.dec.Decode(&transaction); err != nil {
+				return r.malformed()
+			}
+			if err := fn(&transaction); err != nil {
+				return err
+			}
+		}
+
+		if !r.delim(']') {
+			return r.malformed()
+		}
+		if r.dec.More() {
+			return restoreFileError("transactions must be the last field of the export")
+		}
+	}
+
+	if !r.delim('}') {
+		return r.malformed()
+	}
+
+	return nil
+}
+
+func (r *exportReader) delim(want json.Delim) bool {
+	token, err := r.dec.Token()
+	return err == nil && token == want
+}
+
+func (r *exportReader) malformed() error {
+	if r.body.err != nil {
+		return r.body.err
+	}
+	return restoreFileError("Must be a gokei JSON export")
+}
@@ -1,29 +1,52 @@
 package service
 
 import (
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database"
 )
 
 type Service struct {
-	Hello       *HelloService
-	Category    *CategoryService
-	Account     *AccountService
-	Transaction *TransactionService
-	User        *UserService
-	Token       *TokenService
-	Auth        *AuthService
+	Hello          *HelloService
+	Category       *CategoryService
+	Tag            *TagService
+	Account        *AccountService
+	Transaction    *TransactionService
+	Transfer       *TransferService
+	Reconciliation *ReconciliationService
+	Attachment     *AttachmentService
+	Recurring      *RecurringTransactionService
+	Forecast       *ForecastService
+	Import         *ImportService
+	Export         *ExportService
+	Calendar       *CalendarService
+	User           *UserService
+	Token          *TokenService
+	Auth           *AuthService
 }
 
-func New(db *database.DB) *Service {
+// New wires up the services. Files such as transaction attachments are kept
+// in blobs.
+func New(db *database.DB, blobs blob.Store) *Service {
 	tokenService := NewTokenService(db.Queries)
+	accountService := NewAccountService(db.Queries, db.Connection, blobs)
+	userService := NewUserService(db.Queries, db.Connection, blobs)
 
 	return &Service{
-		Hello:       NewHelloService(db.Queries),
-		Category:    NewCategoryService(db.Queries),
-		Account:     NewAccountService(db.Queries, db.Connection),
-		Transaction: NewTransactionService(db.Queries, db.Connection),
-		User:        NewUserService(db.Queries),
-		Token:       tokenService,
-		Auth:        NewAuthService(db.Queries, tokenService),
+		Hello:          NewHelloService(db.Queries),
+		Category:       NewCategoryService(db.Queries),
+		Tag:            NewTagService(db.Queries),
+		Account:        accountService,
+		Transaction:    NewTransactionService(db.Queries, db.Connection, blobs),
+		Transfer:       NewTransferService(db.Queries, db.Connection, blobs),
+		Reconciliation: NewReconciliationService(db.Queries, db.Connection, blobs),
+		Attachment:     NewAttachmentService(db.Queries, db.Connection, blobs),
+		Recurring:      NewRecurringTransactionService(db.Queries, db.Connection),
+		Forecast:       NewForecastService(db.Queries, accountService),
+		Import:         NewImportService(db.Queries, db.Connection),
+		Export:         NewExportService(db.Queries, db.Connection),
+		Calendar:       NewCalendarService(db.Queries, tokenService, userService),
+		User:           userService,
+		Token:          tokenService,
+		Auth:           NewAuthService(db.Queries, tokenService),
 	}
 }
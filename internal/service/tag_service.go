@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+var (
+	ErrDuplicateTagName = errors.New("duplicate tag name")
+)
+
+const maxTransactionTags = 20
+
+type TagService struct {
+	queries store.QuerierTx
+}
+
+func NewTagService(queries store.QuerierTx) *TagService {
+	return &TagService{
+		queries: queries,
+	}
+}
+
+func validateTagName(v *validator.Validator, key, name string) {
+	v.Check(validator.NonZero(name), key, "Must not be empty")
+	v.Check(validator.MaxLength(name, 50), key, "Must not be more than 50 bytes long")
+}
+
+// validateTags checks the tag names given for a transaction. Names are
+// compared after trimming spaces, the same way they are stored.
+func validateTags(v *validator.Validator, names []string) {
+	v.Check(len(names) <= maxTransactionTags, "tags", "Must not have more than 20 tags")
+	for _, name := range names {
+		validateTagName(v, "tags", strings.TrimSpace(name))
+	}
+}
+
+// setTags tags the transaction with names, creating the tags the user does
+// not have yet. Existing tags of the transaction are kept.
+func setTags(ctx context.Context, q store.Querier, userID, transactionID int32, names []string) ([]store.Tag, error) {
+	tags := []store.Tag{}
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag, err := q.UpsertTag(ctx, store.UpsertTagParams{
+			UserID: userID,
+			Name:   name,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = q.AddTransactionTag(ctx, store.AddTransactionTagParams{
+			TransactionID: transactionID,
+			TagID:         tag.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+type CreateTagParams struct {
+	Name string `json:"name"`
+}
+
+func (s *TagService) Create(userID int32, params *CreateTagParams) (*store.Tag, error) {
+	name := strings.TrimSpace(params.Name)
+
+	v := validator.New()
+	if validateTagName(v, "name", name); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	tag, err := s.queries.CreateTag(context.Background(), store.CreateTagParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		if database.IsUniqueContraintViolation(err) {
+			return nil, ErrDuplicateTagName
+		}
+		return nil, database.HandleForeignKeyError(err)
+	}
+
+	return &tag, nil
+}
+
+func (s *TagService) GetAll(userID int32) ([]store.Tag, error) {
+	tags, err := s.queries.GetUserTags(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []store.Tag{}
+	}
+
+	return tags, nil
+}
+
+func (s *TagService) GetByID(userID, tagID int32) (*store.Tag, error) {
+	if userID < 1 || tagID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	tag, err := s.queries.GetTagByID(context.Background(), store.GetTagByIDParams{
+		ID:     tagID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &tag, nil
+}
+
+type UpdateTagParams struct {
+	Name *string `json:"name"`
+}
+
+func (s *TagService) UpdateByID(userID, tagID int32, params *UpdateTagParams) (*store.Tag, error) {
+	tag, err := s.GetByID(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Name != nil {
+		tag.Name = strings.TrimSpace(*params.Name)
+	}
+
+	v := validator.New()
+	if validateTagName(v, "name", tag.Name); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	result, err := s.queries.UpdateTagByID(context.Background(), store.UpdateTagByIDParams{
+		Name:    tag.Name,
+		ID:      tag.ID,
+		UserID:  userID,
+		Version: tag.Version,
+	})
+	if err != nil {
+		if database.IsUniqueContraintViolation(err) {
+			return nil, ErrDuplicateTagName
+		}
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, database.ErrEditConflict
+	}
+
+	tag.Version++
+
+	return tag, nil
+}
+
+// DeleteByID deletes the tag and removes it from every transaction. The
+// transactions themselves are kept.
+func (s *TagService) DeleteByID(userID, tagID int32) error {
+	if userID < 1 || tagID < 1 {
+		return database.ErrRecordNotFound
+	}
+
+	result, err := s.queries.DeleteTagByID(context.Background(), store.DeleteTagByIDParams{
+		ID:     tagID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Report totals the user's transactions per tag between from and to, both
+// inclusive and optional. A transaction with several tags counts towards each
+// of them, so the totals of different tags can't be added together.
+func (s *TagService) Report(userID int32, from, to time.Time) ([]store.GetTagReportRow, error) {
+	v := validator.New()
+	if !from.IsZero() && !to.IsZero() {
+		v.Check(!to.Before(from), "date_to", "Must not be before date_from")
+	}
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	arg := store.GetTagReportParams{
+		UserID: userID,
+	}
+	if !from.IsZero() {
+		arg.DateFrom = sql.NullTime{Time: from, Valid: true}
+	}
+	if !to.IsZero() {
+		arg.DateTo = sql.NullTime{Time: to.AddDate(0, 0, 1), Valid: true}
+	}
+
+	report, err := s.queries.GetTagReport(context.Background(), arg)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		report = []store.GetTagReportRow{}
+	}
+
+	return report, nil
+}
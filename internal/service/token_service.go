@@ -12,6 +12,11 @@ import (
 	"github.com/Quak1/gokei/pkg/validator"
 )
 
+const (
+	ScopeAuthentication = "authentication"
+	ScopeCalendar       = "calendar"
+)
+
 type Token struct {
 	Plaintext string    `json:"token"`
 	Hash      []byte    `json:"-"`
@@ -38,7 +43,7 @@ func HashToken(token string) []byte {
 	return hash[:]
 }
 
-func (s *TokenService) New(userID int, ttl time.Duration) (*Token, error) {
+func (s *TokenService) New(userID int, ttl time.Duration, scope string) (*Token, error) {
 	token := &Token{
 		Expiry: time.Now().Add(ttl),
 	}
@@ -56,6 +61,7 @@ func (s *TokenService) New(userID int, ttl time.Duration) (*Token, error) {
 		Hash:   token.Hash,
 		UserID: int32(userID),
 		Expiry: token.Expiry,
+		Scope:  scope,
 	})
 	if err != nil {
 		return nil, database.HandleForeignKeyError(err)
@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/pkg/validator"
@@ -14,19 +16,23 @@ import (
 
 var (
 	ErrDeleteInitialTransaction       = errors.New("Can't delete initial transaction")
+	ErrReconciledTransaction          = errors.New("Can't change a reconciled transaction, unlock it first")
 	ErrRefundInitialTransaction       = errors.New("Can't refund initial transaction")
+	ErrRefundRefund                   = errors.New("Can't refund a refund")
 	ErrTransactionWithInitialCategory = errors.New("Can't create transaction with initial category")
 )
 
 type TransactionService struct {
 	queries store.QuerierTx
 	DB      *sql.DB
+	blobs   blob.Store
 }
 
-func NewTransactionService(queries store.QuerierTx, db *sql.DB) *TransactionService {
+func NewTransactionService(queries store.QuerierTx, db *sql.DB, blobs blob.Store) *TransactionService {
 	return &TransactionService{
 		queries: queries,
 		DB:      db,
+		blobs:   blobs,
 	}
 }
 
@@ -39,12 +45,159 @@ func validateTransaction(v *validator.Validator, transaction *store.Transaction)
 
 	v.Check(validator.NonZero(transaction.Title), "title", "Must be provided")
 
+	v.Check(validator.PermittedValue(transaction.Status, store.TransactionStatusPending, store.TransactionStatusCleared, store.TransactionStatusReconciled), "status", "Invalid status. Valid values are pending, cleared and reconciled")
+
 	// v.Check(validator.NonZero(transaction.Attachment), "attachment", "Must be provided")
 	// TODO extend validation
 
 	// v.Check(validator.NonZero(transaction.Note), "note", "Must be provided")
 }
 
+// validateStatusChange keeps clients from setting a transaction as reconciled
+// themselves, as the reconciled balance must only count transactions checked
+// against a statement.
+func validateStatusChange(v *validator.Validator, status store.TransactionStatus) {
+	v.Check(status != store.TransactionStatusReconciled, "status", "Can only be set by reconciling the account")
+}
+
+// TransactionDetails is a transaction along with the lines it is split into
+// and its tags. Splits is empty for transactions that only have their own
+// category.
+type TransactionDetails struct {
+	store.Transaction
+	Splits []store.TransactionSplit `json:"splits"`
+	Tags   []store.Tag              `json:"tags"`
+	// DuplicateOf lists the existing transactions a new transaction is a
+	// probable duplicate of. It is only filled in on creation.
+	DuplicateOf []int32 `json:"duplicate_of,omitempty"`
+	// TransferID is the transfer the transaction is a leg or the fee of.
+	TransferID *int32 `json:"transfer_id,omitempty"`
+	// Refund is how much of the transaction has been refunded, and RefundOf
+	// links a refund to the transaction it refunds. They are only filled in
+	// when a single transaction is read.
+	Refund   *RefundStatus            `json:"refund,omitempty"`
+	RefundOf *store.TransactionRefund `json:"refund_of,omitempty"`
+}
+
+func newTransactionDetails(transaction store.Transaction, splits []store.TransactionSplit, tags []store.Tag) *TransactionDetails {
+	if splits == nil {
+		splits = []store.TransactionSplit{}
+	}
+	if tags == nil {
+		tags = []store.Tag{}
+	}
+	return &TransactionDetails{Transaction: transaction, Splits: splits, Tags: tags}
+}
+
+type TransactionSplitParams struct {
+	CategoryID  int32  `json:"category_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Note        string `json:"note"`
+}
+
+func validateSplits(v *validator.Validator, amountCents int64, splits []TransactionSplitParams) {
+	if len(splits) == 0 {
+		return
+	}
+
+	v.Check(len(splits) >= 2, "splits", "Must have at least two lines")
+
+	var total int64
+	for _, split := range splits {
+		v.Check(validator.NonZero(split.CategoryID), "splits", "Every line must have a category_id")
+		v.Check(split.CategoryID != database.InitialCategoryID(), "splits", "Can't use the initial category")
+		v.Check(validator.NonZero(split.AmountCents), "splits", "Every line must have a non-zero amount_cents")
+		total += split.AmountCents
+	}
+
+	v.Check(total == amountCents, "splits", "Must add up to amount_cents")
+}
+
+func splitParams(splits []store.TransactionSplit) []TransactionSplitParams {
+	params := make([]TransactionSplitParams, len(splits))
+	for i, split := range splits {
+		params[i] = TransactionSplitParams{
+			CategoryID:  split.CategoryID,
+			AmountCents: split.AmountCents,
+			Note:        split.Note,
+		}
+	}
+	return params
+}
+
+func createSplits(ctx context.Context, q store.Querier, transactionID int32, splits []TransactionSplitParams) ([]store.TransactionSplit, error) {
+	created := make([]store.TransactionSplit, len(splits))
+	for i, split := range splits {
+		var err error
+		created[i], err = q.CreateTransactionSplit(ctx, store.CreateTransactionSplitParams{
+			TransactionID: transactionID,
+			CategoryID:    split.CategoryID,
+			AmountCents:   split.AmountCents,
+			Note:          split.Note,
+		})
+		if err != nil {
+			return nil, database.HandleForeignKeyError(err)
+		}
+	}
+	return created, nil
+}
+
+// withDetails loads the splits, tags and transfers of every transaction with
+// one query each.
+func withDetails(ctx context.Context, q store.Querier, transactions []store.Transaction) ([]*TransactionDetails, error) {
+	ids := make([]int32, len(transactions))
+	result := make([]*TransactionDetails, len(transactions))
+	byID := make(map[int32]*TransactionDetails, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+		result[i] = newTransactionDetails(transaction, nil, nil)
+		byID[transaction.ID] = result[i]
+	}
+
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	splits, err := q.GetTransactionSplitsForTransactions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, split := range splits {
+		t := byID[split.TransactionID]
+		t.Splits = append(t.Splits, split)
+	}
+
+	tags, err := q.GetTagsForTransactions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		t := byID[tag.TransactionID]
+		t.Tags = append(t.Tags, tag.Tag)
+	}
+
+	transfers, err := q.GetTransferIDsForTransactions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range transfers {
+		byID[transfer.TransactionID].TransferID = &transfer.TransferID
+	}
+
+	return result, nil
+}
+
+func transactionDetails(ctx context.Context, q store.Querier, transaction store.Transaction) (*TransactionDetails, error) {
+	details, err := withDetails(ctx, q, []store.Transaction{transaction})
+	if err != nil {
+		return nil, err
+	}
+	return details[0], nil
+}
+
 func (s *TransactionService) GetAll(userID int32) ([]*store.Transaction, error) {
 	data, err := s.queries.GetAllTransactions(context.Background(), userID)
 	if err != nil {
@@ -59,28 +212,239 @@ func (s *TransactionService) GetAll(userID int32) ([]*store.Transaction, error)
 	return transactions, nil
 }
 
-func (s *TransactionService) Create(userID int32, transactionParams *store.CreateTransactionParams) (*store.Transaction, error) {
-	if transactionParams.CategoryID == database.InitialCategoryID() {
-		return nil, ErrTransactionWithInitialCategory
+type ListTransactionsParams struct {
+	AccountIDs     []int32
+	CategoryIDs    []int32
+	TagIDs         []int32
+	DateFrom       time.Time
+	DateTo         time.Time
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Sign           string
+	Status         string
+	Sort           string
+	Order          string
+	Cursor         string
+	PageSize       int
+}
+
+// transactionCursor is the position after the last transaction of a page. It
+// holds the sort and order it was made for, as it means nothing under others.
+type transactionCursor struct {
+	Sort        string    `json:"sort"`
+	Order       string    `json:"order"`
+	ID          int32     `json:"id"`
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amount_cents"`
+}
+
+func validateListTransactions(v *validator.Validator, params *ListTransactionsParams) {
+	v.Check(params.PageSize > 0 && params.PageSize <= MaxPageSize, "page_size", fmt.Sprintf("Must be between 1 and %d", MaxPageSize))
+
+	v.Check(validator.PermittedValue(params.Sort, "date", "amount"), "sort", "Invalid sort. Valid values are date and amount")
+	v.Check(validator.PermittedValue(params.Order, "asc", "desc"), "order", "Invalid order. Valid values are asc and desc")
+	v.Check(validator.PermittedValue(params.Sign, "", "income", "expense"), "sign", "Invalid sign. Valid values are income and expense")
+	v.Check(validator.PermittedValue(store.TransactionStatus(params.Status), "", store.TransactionStatusPending, store.TransactionStatusCleared, store.TransactionStatusReconciled), "status", "Invalid status. Valid values are pending, cleared and reconciled")
+
+	if !params.DateFrom.IsZero() && !params.DateTo.IsZero() {
+		v.Check(!params.DateTo.Before(params.DateFrom), "date_to", "Must not be before date_from")
 	}
-	if transactionParams.CategoryID < 1 {
-		return nil, database.ErrRecordNotFound
+
+	if params.MinAmountCents != nil && params.MaxAmountCents != nil {
+		v.Check(*params.MaxAmountCents >= *params.MinAmountCents, "max_amount_cents", "Must not be less than min_amount_cents")
+	}
+}
+
+// List returns a page of the user's transactions matching params, ordered by
+// the sort field and then by ID so that rows with equal keys have a stable
+// position between pages.
+func (s *TransactionService) List(userID int32, params *ListTransactionsParams) ([]*TransactionDetails, *Pagination, error) {
+	v := validator.New()
+	if validateListTransactions(v, params); !v.Valid() {
+		return nil, nil, v.GetErrors()
 	}
 
-	transaction := &store.Transaction{
-		AccountID:   transactionParams.AccountID,
-		AmountCents: transactionParams.AmountCents,
-		CategoryID:  transactionParams.CategoryID,
-		Title:       transactionParams.Title,
-		Attachment:  transactionParams.Attachment,
-		Note:        transactionParams.Note,
+	arg := store.ListTransactionsParams{
+		UserID:      userID,
+		AccountIds:  params.AccountIDs,
+		CategoryIds: params.CategoryIDs,
+		TagIds:      params.TagIDs,
+		Sort:        params.Sort,
+		Descending:  params.Order == "desc",
+		PageSize:    int32(params.PageSize + 1),
+	}
+
+	if !params.DateFrom.IsZero() {
+		arg.DateFrom = sql.NullTime{Time: params.DateFrom, Valid: true}
+	}
+	if !params.DateTo.IsZero() {
+		// date_to includes the whole day.
+		arg.DateTo = sql.NullTime{Time: params.DateTo.AddDate(0, 0, 1), Valid: true}
+	}
+	if params.MinAmountCents != nil {
+		arg.MinAmountCents = sql.NullInt64{Int64: *params.MinAmountCents, Valid: true}
+	}
+	if params.MaxAmountCents != nil {
+		arg.MaxAmountCents = sql.NullInt64{Int64: *params.MaxAmountCents, Valid: true}
+	}
+
+	switch params.Sign {
+	case "income":
+		arg.Sign = sql.NullInt32{Int32: 1, Valid: true}
+	case "expense":
+		arg.Sign = sql.NullInt32{Int32: -1, Valid: true}
+	}
+	if params.Status != "" {
+		arg.Status = store.NullTransactionStatus{TransactionStatus: store.TransactionStatus(params.Status), Valid: true}
+	}
+
+	if params.Cursor != "" {
+		var cursor transactionCursor
+		if err := decodeCursor(params.Cursor, &cursor); err != nil {
+			v.AddError("cursor", "Invalid cursor")
+			return nil, nil, v.GetErrors()
+		}
+		if cursor.Sort != params.Sort || cursor.Order != params.Order {
+			v.AddError("cursor", "Must be used with the sort and order of the page it came from")
+			return nil, nil, v.GetErrors()
+		}
+
+		arg.CursorID = sql.NullInt32{Int32: cursor.ID, Valid: true}
+		arg.CursorDate = sql.NullTime{Time: cursor.Date, Valid: true}
+		arg.CursorAmountCents = sql.NullInt64{Int64: cursor.AmountCents, Valid: true}
+	}
+
+	ctx := context.Background()
+
+	data, err := s.queries.ListTransactions(ctx, arg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := &Pagination{
+		PageSize: params.PageSize,
+		HasMore:  len(data) > params.PageSize,
+	}
+	if pagination.HasMore {
+		data = data[:params.PageSize]
 	}
 
+	rows := make([]store.Transaction, len(data))
+	for i, v := range data {
+		rows[i] = v.Transaction
+	}
+
+	transactions, err := withDetails(ctx, s.queries, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pagination.HasMore {
+		last := transactions[len(transactions)-1]
+		pagination.NextCursor = encodeCursor(transactionCursor{
+			Sort:        params.Sort,
+			Order:       params.Order,
+			ID:          last.ID,
+			Date:        last.Date,
+			AmountCents: last.AmountCents,
+		})
+	}
+
+	return transactions, pagination, nil
+}
+
+// ListForAccount is List restricted to one of the user's accounts.
+func (s *TransactionService) ListForAccount(userID, accountID int32, params *ListTransactionsParams) ([]*TransactionDetails, *Pagination, error) {
+	_, err := s.queries.GetAccountByID(context.Background(), store.GetAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, database.ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	params.AccountIDs = []int32{accountID}
+
+	return s.List(userID, params)
+}
+
+type SearchTransactionsParams struct {
+	Query      string
+	AccountIDs []int32
+	DateFrom   time.Time
+	DateTo     time.Time
+	Limit      int
+}
+
+func validateSearchTransactions(v *validator.Validator, params *SearchTransactionsParams) {
+	v.Check(validator.NonZero(database.PrefixTSQuery(params.Query)), "q", "Must contain at least one word")
+	v.Check(validator.MaxLength(params.Query, 200), "q", "Must not be more than 200 bytes long")
+
+	v.Check(params.Limit > 0 && params.Limit <= MaxPageSize, "limit", fmt.Sprintf("Must be between 1 and %d", MaxPageSize))
+
+	if !params.DateFrom.IsZero() && !params.DateTo.IsZero() {
+		v.Check(!params.DateTo.Before(params.DateFrom), "date_to", "Must not be before date_from")
+	}
+}
+
+// Search returns the user's transactions whose title or note contain every
+// word of the query, matching words by prefix. The best matches come first,
+// with matches in the title ranked above matches in the note.
+func (s *TransactionService) Search(userID int32, params *SearchTransactionsParams) ([]*TransactionDetails, error) {
 	v := validator.New()
-	if validateTransaction(v, transaction); !v.Valid() {
+	if validateSearchTransactions(v, params); !v.Valid() {
 		return nil, v.GetErrors()
 	}
 
+	arg := store.SearchTransactionsParams{
+		Query:      database.PrefixTSQuery(params.Query),
+		UserID:     userID,
+		AccountIds: params.AccountIDs,
+		MaxResults: int32(params.Limit),
+	}
+	if !params.DateFrom.IsZero() {
+		arg.DateFrom = sql.NullTime{Time: params.DateFrom, Valid: true}
+	}
+	if !params.DateTo.IsZero() {
+		arg.DateTo = sql.NullTime{Time: params.DateTo.AddDate(0, 0, 1), Valid: true}
+	}
+
+	ctx := context.Background()
+
+	data, err := s.queries.SearchTransactions(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]store.Transaction, len(data))
+	for i, v := range data {
+		rows[i] = v.Transaction
+	}
+
+	return withDetails(ctx, s.queries, rows)
+}
+
+type CreateTransactionParams struct {
+	AccountID   int32                    `json:"account_id"`
+	AmountCents int64                    `json:"amount_cents"`
+	CategoryID  int32                    `json:"category_id"`
+	Title       string                   `json:"title"`
+	Attachment  string                   `json:"attachment"`
+	Note        string                   `json:"note"`
+	Splits      []TransactionSplitParams `json:"splits"`
+	Tags        []string                 `json:"tags"`
+
+	// Status defaults to cleared. Only a reconciliation sets a transaction as
+	// reconciled.
+	Status store.TransactionStatus `json:"status"`
+}
+
+func (s *TransactionService) Create(userID int32, params *CreateTransactionParams) (*TransactionDetails, error) {
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return nil, err
@@ -90,8 +454,75 @@ func (s *TransactionService) Create(userID int32, transactionParams *store.Creat
 	qtx := s.queries.WithTx(tx)
 	ctx := context.Background()
 
-	_, err = qtx.GetAccountByID(ctx, store.GetAccountByIDParams{
-		ID:     transactionParams.AccountID,
+	transaction, err := createTransaction(ctx, qtx, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates, err := findDuplicates(ctx, qtx, transaction.AccountID, []duplicateCheck{{
+		AmountCents: transaction.AmountCents,
+		Date:        transaction.Date,
+		Title:       transaction.Title,
+	}}, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, duplicate := range duplicates[0] {
+		transaction.DuplicateOf = append(transaction.DuplicateOf, duplicate.ID)
+	}
+
+	err = updateBalances(ctx, qtx, userID, transaction.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// createTransaction inserts a transaction along with its splits and tags. It
+// leaves the account balance to the caller, so that several changes can be
+// made before it is recomputed.
+func createTransaction(ctx context.Context, q store.Querier, userID int32, params *CreateTransactionParams) (*TransactionDetails, error) {
+	// A split transaction is filed under its first line unless told otherwise.
+	if params.CategoryID == 0 && len(params.Splits) > 0 {
+		params.CategoryID = params.Splits[0].CategoryID
+	}
+
+	if params.CategoryID == database.InitialCategoryID() {
+		return nil, ErrTransactionWithInitialCategory
+	}
+	if params.CategoryID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+	if params.Status == "" {
+		params.Status = store.TransactionStatusCleared
+	}
+
+	transaction := &store.Transaction{
+		AccountID:   params.AccountID,
+		AmountCents: params.AmountCents,
+		CategoryID:  params.CategoryID,
+		Title:       params.Title,
+		Attachment:  params.Attachment,
+		Note:        params.Note,
+		Status:      params.Status,
+	}
+
+	v := validator.New()
+	validateTransaction(v, transaction)
+	validateStatusChange(v, transaction.Status)
+	validateTags(v, params.Tags)
+	if validateSplits(v, transaction.AmountCents, params.Splits); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	_, err := q.GetAccountByID(ctx, store.GetAccountByIDParams{
+		ID:     params.AccountID,
 		UserID: userID,
 	})
 	if err != nil {
@@ -103,25 +534,51 @@ func (s *TransactionService) Create(userID int32, transactionParams *store.Creat
 		}
 	}
 
-	newTransaction, err := qtx.CreateTransaction(ctx, *transactionParams)
+	newTransaction, err := q.CreateTransaction(ctx, store.CreateTransactionParams{
+		AccountID:   transaction.AccountID,
+		AmountCents: transaction.AmountCents,
+		CategoryID:  transaction.CategoryID,
+		Title:       transaction.Title,
+		Attachment:  transaction.Attachment,
+		Note:        transaction.Note,
+		Status:      store.NullTransactionStatus{TransactionStatus: transaction.Status, Valid: true},
+	})
 	if err != nil {
 		return nil, database.HandleForeignKeyError(err)
 	}
 
-	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
-		ID:     transaction.AccountID,
-		UserID: userID,
-	})
+	splits, err := createSplits(ctx, q, newTransaction.ID, params.Splits)
 	if err != nil {
 		return nil, err
 	}
 
-	err = tx.Commit()
+	tags, err := setTags(ctx, q, userID, newTransaction.ID, params.Tags)
 	if err != nil {
 		return nil, err
 	}
 
-	return &newTransaction, nil
+	return newTransactionDetails(newTransaction, splits, tags), nil
+}
+
+// updateBalances recomputes the balance of each account once, however many
+// times it is listed.
+func updateBalances(ctx context.Context, q store.Querier, userID int32, accountIDs ...int32) error {
+	done := make(map[int32]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		if done[accountID] {
+			continue
+		}
+		done[accountID] = true
+
+		_, err := q.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
+			ID:     accountID,
+			UserID: userID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *TransactionService) GetAllTRansactionsForAccountID(accountID, userID int32) ([]*store.Transaction, error) {
@@ -145,12 +602,14 @@ func (s *TransactionService) GetAllTRansactionsForAccountID(accountID, userID in
 	return transactions, nil
 }
 
-func (s *TransactionService) GetByID(transactionID, userID int32) (*store.Transaction, error) {
+func (s *TransactionService) GetByID(transactionID, userID int32) (*TransactionDetails, error) {
 	if transactionID < 1 || userID < 1 {
 		return nil, database.ErrRecordNotFound
 	}
 
-	transaction, err := s.queries.GetTransactionByID(context.Background(), store.GetTransactionByIDParams{
+	ctx := context.Background()
+
+	transaction, err := s.queries.GetTransactionByID(ctx, store.GetTransactionByIDParams{
 		ID:     transactionID,
 		UserID: userID,
 	})
@@ -163,14 +622,32 @@ func (s *TransactionService) GetByID(transactionID, userID int32) (*store.Transa
 		}
 	}
 
-	return &transaction.Transaction, nil
-}
+	details, err := transactionDetails(ctx, s.queries, transaction.Transaction)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *TransactionService) DeleteByID(transactionID, userID int32) error {
-	if transactionID < 1 || userID < 1 {
-		return database.ErrRecordNotFound
+	if transaction.Transaction.CategoryID == database.InitialCategoryID() {
+		return details, nil
 	}
 
+	refundOf, err := s.queries.GetTransactionRefundOf(ctx, transactionID)
+	switch {
+	case err == nil:
+		details.RefundOf = &refundOf
+	case errors.Is(err, sql.ErrNoRows):
+		details.Refund, err = refundStatus(ctx, s.queries, transaction.Transaction)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	return details, nil
+}
+
+func (s *TransactionService) DeleteByID(transactionID, userID int32) error {
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return err
@@ -180,47 +657,73 @@ func (s *TransactionService) DeleteByID(transactionID, userID int32) error {
 	qtx := s.queries.WithTx(tx)
 	ctx := context.Background()
 
-	t, err := qtx.GetTransactionByID(ctx, store.GetTransactionByIDParams{
+	accountID, attachmentKeys, err := deleteTransaction(ctx, qtx, userID, transactionID)
+	if err != nil {
+		return err
+	}
+
+	err = updateBalances(ctx, qtx, userID, accountID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
+	return nil
+}
+
+// deleteTransaction deletes a transaction and returns the account it belonged
+// to, whose balance is left to the caller, along with the files of its
+// attachments, which are left to be removed once the deletion is committed.
+func deleteTransaction(ctx context.Context, q store.Querier, userID, transactionID int32) (int32, []string, error) {
+	if transactionID < 1 || userID < 1 {
+		return 0, nil, database.ErrRecordNotFound
+	}
+
+	t, err := q.GetTransactionByID(ctx, store.GetTransactionByIDParams{
 		ID:     transactionID,
 		UserID: userID,
 	})
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return database.ErrRecordNotFound
+			return 0, nil, database.ErrRecordNotFound
 		default:
-			return err
+			return 0, nil, err
 		}
 	}
 
-	transaction := t.Transaction
+	err = checkNotTransfer(ctx, q, transactionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
 	if t.Transaction.CategoryID == database.InitialCategoryID() {
-		return ErrDeleteInitialTransaction
+		return 0, nil, ErrDeleteInitialTransaction
+	}
+	if t.Transaction.Status == store.TransactionStatusReconciled {
+		return 0, nil, ErrReconciledTransaction
 	}
 
-	result, err := qtx.DeleteTransactionByID(ctx, store.DeleteTransactionByIDParams{
-		ID:     transactionID,
-		UserID: userID,
-	})
-	_, err = result.RowsAffected()
+	attachmentKeys, err := q.GetTransactionAttachmentKeys(ctx, transactionID)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
-	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
-		ID:     transaction.AccountID,
+	_, err = q.DeleteTransactionByID(ctx, store.DeleteTransactionByIDParams{
+		ID:     transactionID,
 		UserID: userID,
 	})
 	if err != nil {
-		return err
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
-	return nil
+	return t.Transaction.AccountID, attachmentKeys, nil
 }
 
 type UpdateTransactionParams struct {
@@ -231,13 +734,21 @@ type UpdateTransactionParams struct {
 	Date        *time.Time `json:"date"`
 	Attachment  *string    `json:"attachment"`
 	Note        *string    `json:"note"`
-}
 
-func (s *TransactionService) UpdateByID(transactionID, userID int32, updateParams *UpdateTransactionParams) (*store.Transaction, error) {
-	if transactionID < 1 || userID < 1 {
-		return nil, database.ErrRecordNotFound
-	}
+	// Status moves a transaction between pending and cleared. Only a
+	// reconciliation sets it as reconciled, and a reconciled transaction has
+	// to be unlocked before it can be changed.
+	Status *store.TransactionStatus `json:"status"`
+
+	// Splits replaces the lines of the transaction. An empty list removes
+	// them, leaving the transaction under its own category.
+	Splits *[]TransactionSplitParams `json:"splits"`
+
+	// Tags replaces the tags of the transaction. An empty list removes them.
+	Tags *[]string `json:"tags"`
+}
 
+func (s *TransactionService) UpdateByID(transactionID, userID int32, updateParams *UpdateTransactionParams) (*TransactionDetails, error) {
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return nil, err
@@ -247,19 +758,53 @@ func (s *TransactionService) UpdateByID(transactionID, userID int32, updateParam
 	qtx := s.queries.WithTx(tx)
 	ctx := context.Background()
 
-	t, err := qtx.GetTransactionByID(ctx, store.GetTransactionByIDParams{
+	transaction, oldAccountID, err := updateTransaction(ctx, qtx, userID, transactionID, updateParams)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateBalances(ctx, qtx, userID, oldAccountID, transaction.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// updateTransaction applies updateParams to a transaction and returns it along
+// with the account it belonged to before. Balances are left to the caller.
+func updateTransaction(ctx context.Context, q store.Querier, userID, transactionID int32, updateParams *UpdateTransactionParams) (*TransactionDetails, int32, error) {
+	if transactionID < 1 || userID < 1 {
+		return nil, 0, database.ErrRecordNotFound
+	}
+
+	t, err := q.GetTransactionByID(ctx, store.GetTransactionByIDParams{
 		ID:     transactionID,
 		UserID: userID,
 	})
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return nil, database.ErrRecordNotFound
+			return nil, 0, database.ErrRecordNotFound
 		default:
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
+	err = checkNotTransfer(ctx, q, transactionID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if t.Transaction.Status == store.TransactionStatusReconciled {
+		return nil, 0, ErrReconciledTransaction
+	}
+
 	transaction := t.Transaction
 	oldAccountID := transaction.AccountID
 
@@ -271,7 +816,7 @@ func (s *TransactionService) UpdateByID(transactionID, userID int32, updateParam
 	}
 	if updateParams.CategoryID != nil {
 		if *updateParams.CategoryID == database.InitialCategoryID() {
-			return nil, ErrTransactionWithInitialCategory
+			return nil, 0, ErrTransactionWithInitialCategory
 		}
 		transaction.CategoryID = *updateParams.CategoryID
 	}
@@ -287,26 +832,53 @@ func (s *TransactionService) UpdateByID(transactionID, userID int32, updateParam
 	if updateParams.Note != nil {
 		transaction.Note = *updateParams.Note
 	}
+	if updateParams.Status != nil {
+		transaction.Status = *updateParams.Status
+	}
+
+	var splits []TransactionSplitParams
+	if updateParams.Splits != nil {
+		splits = *updateParams.Splits
+	} else {
+		// Existing lines are kept, so they must still match the amount.
+		current, err := q.GetTransactionSplits(ctx, transaction.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		splits = splitParams(current)
+	}
 
 	v := validator.New()
-	if validateTransaction(v, &transaction); !v.Valid() {
-		return nil, v.GetErrors()
+	validateTransaction(v, &transaction)
+	validateStatusChange(v, transaction.Status)
+	if updateParams.Tags != nil {
+		validateTags(v, *updateParams.Tags)
+	}
+	validateSplits(v, transaction.AmountCents, splits)
+	if transaction.AmountCents != t.Transaction.AmountCents {
+		err = validateRefundAmounts(ctx, q, v, userID, transaction)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	if !v.Valid() {
+		return nil, 0, v.GetErrors()
 	}
 
-	_, err = qtx.GetAccountByID(ctx, store.GetAccountByIDParams{
+	_, err = q.GetAccountByID(ctx, store.GetAccountByIDParams{
 		ID:     transaction.AccountID,
 		UserID: userID,
 	})
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return nil, database.ErrRecordNotFound
+			return nil, 0, database.ErrRecordNotFound
 		default:
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
-	result, err := s.queries.UpdateTransactionById(ctx, store.UpdateTransactionByIdParams{
+	result, err := q.UpdateTransactionById(ctx, store.UpdateTransactionByIdParams{
 		ID:          transaction.ID,
 		Version:     transaction.Version,
 		AmountCents: transaction.AmountCents,
@@ -316,50 +888,256 @@ func (s *TransactionService) UpdateByID(transactionID, userID int32, updateParam
 		Date:        transaction.Date,
 		Attachment:  transaction.Attachment,
 		Note:        transaction.Note,
+		Status:      transaction.Status,
 		UserID:      userID,
 	})
 	if err != nil {
-		return nil, database.HandleForeignKeyError(err)
+		return nil, 0, database.HandleForeignKeyError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if rowsAffected == 0 {
-		return nil, database.ErrEditConflict
+		return nil, 0, database.ErrEditConflict
 	}
 
-	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
-		ID:     oldAccountID,
+	if updateParams.Splits != nil {
+		err = q.DeleteTransactionSplits(ctx, transaction.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		_, err = createSplits(ctx, q, transaction.ID, splits)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if updateParams.Tags != nil {
+		err = q.DeleteTransactionTags(ctx, transaction.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		_, err = setTags(ctx, q, userID, transaction.ID, *updateParams.Tags)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	details, err := transactionDetails(ctx, q, transaction)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return details, oldAccountID, nil
+}
+
+// UnlockByID takes a reconciled transaction back to cleared, so that it can be
+// changed or deleted again. Other transactions are returned as they are.
+func (s *TransactionService) UnlockByID(transactionID, userID int32) (*TransactionDetails, error) {
+	if transactionID < 1 || userID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	ctx := context.Background()
+
+	t, err := s.queries.GetTransactionByID(ctx, store.GetTransactionByIDParams{
+		ID:     transactionID,
 		UserID: userID,
 	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	transaction := t.Transaction
+	if transaction.Status == store.TransactionStatusReconciled {
+		transaction.Status = store.TransactionStatusCleared
+
+		// Both statuses count towards the cleared balance, so it stays as it is.
+		result, err := s.queries.UpdateTransactionById(ctx, store.UpdateTransactionByIdParams{
+			ID:          transaction.ID,
+			Version:     transaction.Version,
+			AmountCents: transaction.AmountCents,
+			AccountID:   transaction.AccountID,
+			CategoryID:  transaction.CategoryID,
+			Title:       transaction.Title,
+			Date:        transaction.Date,
+			Attachment:  transaction.Attachment,
+			Note:        transaction.Note,
+			Status:      transaction.Status,
+			UserID:      userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+
+		if rowsAffected == 0 {
+			return nil, database.ErrEditConflict
+		}
+		transaction.Version++
+	}
+
+	return transactionDetails(ctx, s.queries, transaction)
+}
+
+type RefundTransactionParams struct {
+	// AmountCents is how much to refund, as a positive amount. It defaults to
+	// what is left to refund.
+	AmountCents *int64  `json:"amount_cents"`
+	Reason      *string `json:"reason"`
+}
+
+const (
+	RefundStatusNone    = "none"
+	RefundStatusPartial = "partial"
+	RefundStatusFull    = "full"
+)
+
+// RefundStatus is how much of a transaction has been refunded. Amounts are
+// positive whatever the sign of the transaction.
+type RefundStatus struct {
+	Status          string                           `json:"status"`
+	RefundedCents   int64                            `json:"refunded_cents"`
+	RefundableCents int64                            `json:"refundable_cents"`
+	Refunds         []store.GetTransactionRefundsRow `json:"refunds"`
+}
+
+func refundStatus(ctx context.Context, q store.Querier, transaction store.Transaction) (*RefundStatus, error) {
+	refunds, err := q.GetTransactionRefunds(ctx, transaction.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
-		ID:     transaction.AccountID,
+	status := &RefundStatus{Refunds: orEmpty(refunds)}
+	for _, refund := range refunds {
+		status.RefundedCents -= refund.AmountCents
+	}
+
+	amount := transaction.AmountCents
+	if amount < 0 {
+		amount = -amount
+		status.RefundedCents = -status.RefundedCents
+	}
+	// Never below zero, should the refunds add up to more than the original.
+	status.RefundableCents = max(amount-status.RefundedCents, 0)
+
+	switch {
+	case status.RefundedCents <= 0:
+		status.Status = RefundStatusNone
+	case status.RefundableCents == 0:
+		status.Status = RefundStatusFull
+	default:
+		status.Status = RefundStatusPartial
+	}
+
+	return status, nil
+}
+
+// validateRefundAmounts checks the new amount of a refund, or of a refunded
+// transaction, against the other refunds of the same original: each must go
+// the other way from it, and they must not add up to more than it. The
+// original is locked, as RefundByID does, so that a refund made meanwhile
+// can't go past the new amount.
+func validateRefundAmounts(ctx context.Context, q store.Querier, v *validator.Validator, userID int32, transaction store.Transaction) error {
+	originalID := transaction.ID
+	refundOf, err := q.GetTransactionRefundOf(ctx, transaction.ID)
+	switch {
+	case err == nil:
+		originalID = refundOf.TransactionID
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	t, err := q.GetTransactionByIDForUpdate(ctx, store.GetTransactionByIDForUpdateParams{
+		ID:     originalID,
 		UserID: userID,
 	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+	original := t.Transaction
+	if original.ID == transaction.ID {
+		original = transaction
 	}
 
-	err = tx.Commit()
+	refunds, err := q.GetTransactionRefunds(ctx, original.ID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	// Amounts are compared positive, as in refundStatus.
+	sign := int64(1)
+	if original.AmountCents < 0 {
+		sign = -1
+	}
+
+	var refundedCents, otherCents int64
+	for _, refund := range refunds {
+		amountCents := refund.AmountCents
+		if refund.RefundTransactionID == transaction.ID {
+			amountCents = transaction.AmountCents
+		}
+
+		refunded := -sign * amountCents
+		if refunded <= 0 {
+			if original.ID == transaction.ID {
+				v.AddError("amount_cents", "Must not change sign, as the transaction has been refunded")
+			} else {
+				v.AddError("amount_cents", "Must go the other way from the refunded transaction")
+			}
+			return nil
+		}
+
+		refundedCents += refunded
+		if refund.RefundTransactionID != transaction.ID {
+			otherCents += refunded
+		}
+	}
+
+	if refundedCents > sign*original.AmountCents {
+		if original.ID == transaction.ID {
+			v.AddError("amount_cents", fmt.Sprintf("Must not be less than %d, the amount already refunded", refundedCents))
+		} else {
+			v.AddError("amount_cents", fmt.Sprintf("Must not refund more than %d, the amount left to refund", sign*original.AmountCents-otherCents))
+		}
 	}
 
-	return &transaction, nil
+	return nil
 }
 
-type RefundTransactionParams struct {
-	Reason *string `json:"reason"`
+func validateRefund(v *validator.Validator, params *RefundTransactionParams, amountCents, refundableCents int64) {
+	switch {
+	case refundableCents == 0:
+		v.AddError("amount_cents", "Nothing is left to refund")
+	case amountCents <= 0:
+		v.AddError("amount_cents", "Must be greater than zero")
+	case amountCents > refundableCents:
+		v.AddError("amount_cents", fmt.Sprintf("Must not be more than %d, the amount left to refund", refundableCents))
+	}
+
+	if params.Reason != nil {
+		v.Check(validator.MaxLength(*params.Reason, 500), "reason", "Must not be more than 500 bytes long")
+	}
 }
 
-func (s *TransactionService) RefundByID(transactionID, userID int32, params *RefundTransactionParams) (*store.Transaction, error) {
+// RefundByID creates a transaction that gives back all or part of another one
+// and links it to it. A transaction can be refunded several times, as long as
+// the refunds don't add up to more than the original.
+func (s *TransactionService) RefundByID(transactionID, userID int32, params *RefundTransactionParams) (*TransactionDetails, error) {
 	if transactionID < 1 || userID < 1 {
 		return nil, database.ErrRecordNotFound
 	}
@@ -373,7 +1151,9 @@ func (s *TransactionService) RefundByID(transactionID, userID int32, params *Ref
 	qtx := s.queries.WithTx(tx)
 	ctx := context.Background()
 
-	t, err := qtx.GetTransactionByID(ctx, store.GetTransactionByIDParams{
+	// The original is locked so that concurrent refunds can't both fit in
+	// what is left to refund.
+	t, err := qtx.GetTransactionByIDForUpdate(ctx, store.GetTransactionByIDForUpdateParams{
 		ID:     transactionID,
 		UserID: userID,
 	})
@@ -391,10 +1171,53 @@ func (s *TransactionService) RefundByID(transactionID, userID int32, params *Ref
 		return nil, ErrRefundInitialTransaction
 	}
 
+	_, err = qtx.GetTransactionRefundOf(ctx, transaction.ID)
+	if err == nil {
+		return nil, ErrRefundRefund
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	status, err := refundStatus(ctx, qtx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	amountCents := status.RefundableCents
+	if params.AmountCents != nil {
+		amountCents = *params.AmountCents
+	}
+
+	v := validator.New()
+	if validateRefund(v, params, amountCents, status.RefundableCents); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	// The refund goes the other way from the original.
+	refundCents := amountCents
+	if transaction.AmountCents > 0 {
+		refundCents = -amountCents
+	}
+
+	// The refund is split the same way as the original, so category totals
+	// cancel out line by line.
+	splits, err := qtx.GetTransactionSplits(ctx, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryID := transaction.CategoryID
+	refundSplits := prorateSplits(splits, transaction.AmountCents, refundCents)
+	if len(refundSplits) == 1 {
+		categoryID = refundSplits[0].CategoryID
+		refundSplits = nil
+	}
+
 	refundTransaction, err := qtx.CreateTransaction(ctx, store.CreateTransactionParams{
 		AccountID:   transaction.AccountID,
-		AmountCents: -transaction.AmountCents,
-		CategoryID:  transaction.CategoryID,
+		AmountCents: refundCents,
+		CategoryID:  categoryID,
 		Title:       fmt.Sprintf("[REFUND #%d] %s", transaction.ID, transaction.Title),
 		Attachment:  transaction.Attachment,
 		Note:        transaction.Note,
@@ -403,6 +1226,42 @@ func (s *TransactionService) RefundByID(transactionID, userID int32, params *Ref
 		return nil, err
 	}
 
+	createdSplits, err := createSplits(ctx, qtx, refundTransaction.ID, refundSplits)
+	if err != nil {
+		return nil, err
+	}
+
+	var reason string
+	if params.Reason != nil {
+		reason = *params.Reason
+	}
+
+	refund, err := qtx.CreateTransactionRefund(ctx, store.CreateTransactionRefundParams{
+		TransactionID:       transaction.ID,
+		RefundTransactionID: refundTransaction.ID,
+		Reason:              reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := qtx.GetTagsForTransactions(ctx, []int32{transaction.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	refundTags := make([]store.Tag, len(tags))
+	for i, tag := range tags {
+		refundTags[i] = tag.Tag
+		err = qtx.AddTransactionTag(ctx, store.AddTransactionTagParams{
+			TransactionID: refundTransaction.ID,
+			TagID:         tag.Tag.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	_, err = qtx.AutoUpdateBalance(ctx, store.AutoUpdateBalanceParams{
 		ID:     refundTransaction.AccountID,
 		UserID: userID,
@@ -416,5 +1275,173 @@ func (s *TransactionService) RefundByID(transactionID, userID int32, params *Ref
 		return nil, err
 	}
 
-	return &refundTransaction, nil
+	details := newTransactionDetails(refundTransaction, createdSplits, refundTags)
+	details.RefundOf = &refund
+
+	return details, nil
+}
+
+// prorateSplits scales the lines of a transaction to a refund of refundCents,
+// putting what rounding leaves over on the last line. Lines that round to
+// nothing are dropped.
+func prorateSplits(splits []store.TransactionSplit, amountCents, refundCents int64) []TransactionSplitParams {
+	lines := splitParams(splits)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var total int64
+	for i := range lines {
+		scaled := new(big.Int).Mul(big.NewInt(lines[i].AmountCents), big.NewInt(refundCents))
+		lines[i].AmountCents = scaled.Quo(scaled, big.NewInt(amountCents)).Int64()
+		total += lines[i].AmountCents
+	}
+	lines[len(lines)-1].AmountCents += refundCents - total
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if line.AmountCents != 0 {
+			kept = append(kept, line)
+		}
+	}
+
+	return kept
+}
+
+const MaxBulkOperations = 500
+
+// BulkOperation is one change of a bulk request. Op is create, update or
+// delete. Create carries the new transaction, while update and delete act on
+// the transaction with the given ID.
+type BulkOperation struct {
+	Op     string                   `json:"op"`
+	ID     int32                    `json:"id"`
+	Create *CreateTransactionParams `json:"create"`
+	Update *UpdateTransactionParams `json:"update"`
+}
+
+type BulkResult struct {
+	Op          string              `json:"op"`
+	ID          int32               `json:"id"`
+	Transaction *TransactionDetails `json:"transaction,omitempty"`
+}
+
+// BulkOperationError is the error of the operation that made a bulk request
+// fail.
+type BulkOperationError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkOperationError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *BulkOperationError) Unwrap() error {
+	return e.Err
+}
+
+func validateBulkOperations(v *validator.Validator, operations []BulkOperation) {
+	v.Check(len(operations) > 0, "operations", "Must be provided")
+	v.Check(len(operations) <= MaxBulkOperations, "operations", fmt.Sprintf("Must not have more than %d operations", MaxBulkOperations))
+
+	for i, operation := range operations {
+		key := fmt.Sprintf("operations[%d]", i)
+
+		switch operation.Op {
+		case "create":
+			v.Check(operation.Create != nil, key+".create", "Must be provided")
+		case "update":
+			v.Check(operation.ID > 0, key+".id", "Must be provided")
+			v.Check(operation.Update != nil, key+".update", "Must be provided")
+		case "delete":
+			v.Check(operation.ID > 0, key+".id", "Must be provided")
+		default:
+			v.AddError(key+".op", "Invalid op. Valid values are create, update and delete")
+		}
+	}
+}
+
+// Bulk applies operations in order inside a single database transaction, so
+// either all of them take effect or none does. Account balances are
+// recomputed once at the end. When an operation fails, the returned error is a
+// *BulkOperationError, except for validation errors, whose fields are prefixed
+// with the position of the operation.
+func (s *TransactionService) Bulk(userID int32, operations []BulkOperation) ([]BulkResult, error) {
+	v := validator.New()
+	if validateBulkOperations(v, operations); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	results := make([]BulkResult, len(operations))
+	var accountIDs []int32
+	var attachmentKeys []string
+
+	for i, operation := range operations {
+		result := BulkResult{Op: operation.Op, ID: operation.ID}
+
+		switch operation.Op {
+		case "create":
+			result.Transaction, err = createTransaction(ctx, qtx, userID, operation.Create)
+			if err == nil {
+				result.ID = result.Transaction.ID
+				accountIDs = append(accountIDs, result.Transaction.AccountID)
+			}
+		case "update":
+			var oldAccountID int32
+			result.Transaction, oldAccountID, err = updateTransaction(ctx, qtx, userID, operation.ID, operation.Update)
+			if err == nil {
+				accountIDs = append(accountIDs, oldAccountID, result.Transaction.AccountID)
+			}
+		case "delete":
+			var accountID int32
+			var keys []string
+			accountID, keys, err = deleteTransaction(ctx, qtx, userID, operation.ID)
+			if err == nil {
+				accountIDs = append(accountIDs, accountID)
+				attachmentKeys = append(attachmentKeys, keys...)
+			}
+		}
+		if err != nil {
+			return nil, bulkOperationError(i, err)
+		}
+
+		results[i] = result
+	}
+
+	err = updateBalances(ctx, qtx, userID, accountIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
+	return results, nil
+}
+
+func bulkOperationError(index int, err error) error {
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		prefixed := validator.NewValidationError()
+		for field, message := range validationErr.Errors {
+			prefixed.Add(fmt.Sprintf("operations[%d].%s", index, field), message)
+		}
+		return prefixed
+	}
+
+	return &BulkOperationError{Index: index, Err: err}
 }
@@ -0,0 +1,594 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Quak1/gokei/internal/blob"
+	"github.com/Quak1/gokei/internal/database"
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/pkg/validator"
+)
+
+var ErrTransferTransaction = errors.New("Can't change part of a transfer on its own, change the transfer instead")
+
+type TransferService struct {
+	queries store.QuerierTx
+	DB      *sql.DB
+	blobs   blob.Store
+}
+
+func NewTransferService(queries store.QuerierTx, db *sql.DB, blobs blob.Store) *TransferService {
+	return &TransferService{
+		queries: queries,
+		DB:      db,
+		blobs:   blobs,
+	}
+}
+
+// TransferDetails is a transfer along with the transactions it is made of.
+// The legs are filed under the initial category, so they count towards the
+// balances but not towards income or expenses, while the fee is spending like
+// any other.
+type TransferDetails struct {
+	store.Transfer
+	From store.Transaction  `json:"from"`
+	To   store.Transaction  `json:"to"`
+	Fee  *store.Transaction `json:"fee"`
+}
+
+type CreateTransferParams struct {
+	FromAccountID int32  `json:"from_account_id"`
+	ToAccountID   int32  `json:"to_account_id"`
+	AmountCents   int64  `json:"amount_cents"`
+	FeeCents      int64  `json:"fee_cents"`
+	FeeCategoryID int32  `json:"fee_category_id"`
+	Note          string `json:"note"`
+}
+
+func validateTransfer(v *validator.Validator, params *CreateTransferParams) {
+	v.Check(params.AmountCents > 0, "amount_cents", "Must be greater than zero")
+	v.Check(params.FromAccountID > 0, "from_account_id", "Must be provided")
+	v.Check(params.ToAccountID > 0, "to_account_id", "Must be provided")
+	v.Check(params.FromAccountID != params.ToAccountID, "to_account_id", "Must be different from from_account_id")
+
+	v.Check(params.FeeCents >= 0, "fee_cents", "Must not be negative")
+	if params.FeeCents > 0 {
+		v.Check(params.FeeCategoryID > 0, "fee_category_id", "Must be provided when there is a fee")
+		v.Check(params.FeeCategoryID != database.InitialCategoryID(), "fee_category_id", "Can't use the initial category")
+	}
+}
+
+func transferTitles(from, to store.Account) (string, string) {
+	title := fmt.Sprintf("[TRANSFER] FROM '%s' TO '%s'", from.Name, to.Name)
+	return title, fmt.Sprintf("[TRANSFER FEE] FROM '%s' TO '%s'", from.Name, to.Name)
+}
+
+func (s *TransferService) Create(userID int32, params *CreateTransferParams) (*TransferDetails, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	transfer, err := createTransfer(context.Background(), s.queries.WithTx(tx), userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// createTransfer writes both legs of a transfer, its fee if it has one and the
+// transfer linking them, and updates the balances of both accounts.
+func createTransfer(ctx context.Context, q store.Querier, userID int32, params *CreateTransferParams) (*TransferDetails, error) {
+	v := validator.New()
+	if validateTransfer(v, params); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	from, to, err := transferAccounts(ctx, q, userID, params.FromAccountID, params.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	title, feeTitle := transferTitles(from, to)
+
+	fromLeg, err := q.CreateTransaction(ctx, store.CreateTransactionParams{
+		AccountID:   from.ID,
+		AmountCents: -params.AmountCents,
+		CategoryID:  database.InitialCategoryID(),
+		Title:       title,
+		Note:        params.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	toLeg, err := q.CreateTransaction(ctx, store.CreateTransactionParams{
+		AccountID:   to.ID,
+		AmountCents: params.AmountCents,
+		CategoryID:  database.InitialCategoryID(),
+		Title:       title,
+		Note:        params.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := &TransferDetails{From: fromLeg, To: toLeg}
+
+	arg := store.CreateTransferParams{
+		FromTransactionID: fromLeg.ID,
+		ToTransactionID:   toLeg.ID,
+	}
+
+	if params.FeeCents > 0 {
+		fee, err := q.CreateTransaction(ctx, store.CreateTransactionParams{
+			AccountID:   from.ID,
+			AmountCents: -params.FeeCents,
+			CategoryID:  params.FeeCategoryID,
+			Title:       feeTitle,
+			Note:        params.Note,
+		})
+		if err != nil {
+			return nil, database.HandleForeignKeyError(err)
+		}
+		details.Fee = &fee
+		arg.FeeTransactionID = &fee.ID
+	}
+
+	details.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = updateBalances(ctx, q, userID, from.ID, to.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+func transferAccounts(ctx context.Context, q store.Querier, userID, fromAccountID, toAccountID int32) (store.Account, store.Account, error) {
+	var accounts [2]store.Account
+	for i, accountID := range []int32{fromAccountID, toAccountID} {
+		var err error
+		accounts[i], err = q.GetAccountByID(ctx, store.GetAccountByIDParams{
+			ID:     accountID,
+			UserID: userID,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return store.Account{}, store.Account{}, database.ErrRecordNotFound
+			default:
+				return store.Account{}, store.Account{}, err
+			}
+		}
+	}
+
+	return accounts[0], accounts[1], nil
+}
+
+// withLegs loads the transactions of every transfer with one query.
+func withLegs(ctx context.Context, q store.Querier, userID int32, transfers []store.Transfer) ([]*TransferDetails, error) {
+	result := make([]*TransferDetails, len(transfers))
+	if len(transfers) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int32, 0, len(transfers)*2)
+	for _, transfer := range transfers {
+		ids = append(ids, transfer.FromTransactionID, transfer.ToTransactionID)
+		if transfer.FeeTransactionID != nil {
+			ids = append(ids, *transfer.FeeTransactionID)
+		}
+	}
+
+	rows, err := q.GetTransactionsByIDs(ctx, store.GetTransactionsByIDsParams{
+		Ids:    ids,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int32]store.Transaction, len(rows))
+	for _, row := range rows {
+		byID[row.Transaction.ID] = row.Transaction
+	}
+
+	for i, transfer := range transfers {
+		result[i] = &TransferDetails{
+			Transfer: transfer,
+			From:     byID[transfer.FromTransactionID],
+			To:       byID[transfer.ToTransactionID],
+		}
+		if transfer.FeeTransactionID != nil {
+			fee := byID[*transfer.FeeTransactionID]
+			result[i].Fee = &fee
+		}
+	}
+
+	return result, nil
+}
+
+func getTransfer(ctx context.Context, q store.Querier, userID, transferID int32) (*TransferDetails, error) {
+	if transferID < 1 || userID < 1 {
+		return nil, database.ErrRecordNotFound
+	}
+
+	transfer, err := q.GetTransferByID(ctx, store.GetTransferByIDParams{
+		ID:     transferID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, database.ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	details, err := withLegs(ctx, q, userID, []store.Transfer{transfer})
+	if err != nil {
+		return nil, err
+	}
+
+	return details[0], nil
+}
+
+func (s *TransferService) GetByID(userID, transferID int32) (*TransferDetails, error) {
+	return getTransfer(context.Background(), s.queries, userID, transferID)
+}
+
+type ListTransfersParams struct {
+	AccountID int32
+	DateFrom  time.Time
+	DateTo    time.Time
+}
+
+// List returns the user's transfers, newest first. AccountID keeps the
+// transfers from or to that account, and the dates are both inclusive and
+// optional.
+func (s *TransferService) List(userID int32, params *ListTransfersParams) ([]*TransferDetails, error) {
+	v := validator.New()
+	if !params.DateFrom.IsZero() && !params.DateTo.IsZero() {
+		v.Check(!params.DateTo.Before(params.DateFrom), "date_to", "Must not be before date_from")
+	}
+	if !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	arg := store.ListTransfersParams{UserID: userID}
+	if params.AccountID != 0 {
+		arg.AccountID = sql.NullInt32{Int32: params.AccountID, Valid: true}
+	}
+	if !params.DateFrom.IsZero() {
+		arg.DateFrom = sql.NullTime{Time: params.DateFrom, Valid: true}
+	}
+	if !params.DateTo.IsZero() {
+		arg.DateTo = sql.NullTime{Time: params.DateTo.AddDate(0, 0, 1), Valid: true}
+	}
+
+	ctx := context.Background()
+
+	transfers, err := s.queries.ListTransfers(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return withLegs(ctx, s.queries, userID, transfers)
+}
+
+type UpdateTransferParams struct {
+	FromAccountID *int32     `json:"from_account_id"`
+	ToAccountID   *int32     `json:"to_account_id"`
+	AmountCents   *int64     `json:"amount_cents"`
+	FeeCents      *int64     `json:"fee_cents"`
+	FeeCategoryID *int32     `json:"fee_category_id"`
+	Date          *time.Time `json:"date"`
+	Note          *string    `json:"note"`
+}
+
+// UpdateByID changes a transfer as a whole: both legs are kept in step, and
+// the fee is added, changed or removed along with them. A fee of zero removes
+// it.
+func (s *TransferService) UpdateByID(userID, transferID int32, updateParams *UpdateTransferParams) (*TransferDetails, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	transfer, err := getTransfer(ctx, qtx, userID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkTransferNotReconciled(transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	params := CreateTransferParams{
+		FromAccountID: transfer.From.AccountID,
+		ToAccountID:   transfer.To.AccountID,
+		AmountCents:   transfer.To.AmountCents,
+		Note:          transfer.From.Note,
+	}
+	if transfer.Fee != nil {
+		params.FeeCents = -transfer.Fee.AmountCents
+		params.FeeCategoryID = transfer.Fee.CategoryID
+	}
+	date := transfer.From.Date
+
+	if updateParams.FromAccountID != nil {
+		params.FromAccountID = *updateParams.FromAccountID
+	}
+	if updateParams.ToAccountID != nil {
+		params.ToAccountID = *updateParams.ToAccountID
+	}
+	if updateParams.AmountCents != nil {
+		params.AmountCents = *updateParams.AmountCents
+	}
+	if updateParams.FeeCents != nil {
+		params.FeeCents = *updateParams.FeeCents
+	}
+	if updateParams.FeeCategoryID != nil {
+		params.FeeCategoryID = *updateParams.FeeCategoryID
+	}
+	if updateParams.Date != nil {
+		date = *updateParams.Date
+	}
+	if updateParams.Note != nil {
+		params.Note = *updateParams.Note
+	}
+
+	v := validator.New()
+	if validateTransfer(v, &params); !v.Valid() {
+		return nil, v.GetErrors()
+	}
+
+	from, to, err := transferAccounts(ctx, qtx, userID, params.FromAccountID, params.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	title, feeTitle := transferTitles(from, to)
+
+	legs := []struct {
+		transaction store.Transaction
+		accountID   int32
+		amountCents int64
+	}{
+		{transfer.From, from.ID, -params.AmountCents},
+		{transfer.To, to.ID, params.AmountCents},
+	}
+	for _, leg := range legs {
+		leg.transaction.AccountID = leg.accountID
+		leg.transaction.AmountCents = leg.amountCents
+		leg.transaction.Title = title
+		leg.transaction.Date = date
+		leg.transaction.Note = params.Note
+
+		err = writeTransaction(ctx, qtx, userID, leg.transaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var attachmentKeys []string
+
+	switch {
+	case params.FeeCents > 0 && transfer.Fee != nil:
+		fee := *transfer.Fee
+		fee.AccountID = from.ID
+		fee.AmountCents = -params.FeeCents
+		fee.CategoryID = params.FeeCategoryID
+		fee.Title = feeTitle
+		fee.Date = date
+		fee.Note = params.Note
+
+		err = writeTransaction(ctx, qtx, userID, fee)
+		if err != nil {
+			return nil, err
+		}
+
+	case params.FeeCents > 0:
+		fee, err := qtx.CreateTransactionWithDate(ctx, store.CreateTransactionWithDateParams{
+			AccountID:   from.ID,
+			AmountCents: -params.FeeCents,
+			CategoryID:  params.FeeCategoryID,
+			Title:       feeTitle,
+			Note:        params.Note,
+			Date:        date,
+		})
+		if err != nil {
+			return nil, database.HandleForeignKeyError(err)
+		}
+
+		err = qtx.SetTransferFee(ctx, store.SetTransferFeeParams{
+			ID:               transfer.ID,
+			FeeTransactionID: &fee.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case transfer.Fee != nil:
+		// Removing the fee transaction clears it from the transfer.
+		attachmentKeys, err = deleteTransactionsUnchecked(ctx, qtx, userID, transfer.Fee.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = updateBalances(ctx, qtx, userID, transfer.From.AccountID, transfer.To.AccountID, from.ID, to.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := getTransfer(ctx, qtx, userID, transfer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
+	return updated, nil
+}
+
+// writeTransaction saves a transaction as it is, without the checks of
+// updateTransaction, failing with an edit conflict if it was changed in the
+// meantime.
+func writeTransaction(ctx context.Context, q store.Querier, userID int32, transaction store.Transaction) error {
+	result, err := q.UpdateTransactionById(ctx, store.UpdateTransactionByIdParams{
+		ID:          transaction.ID,
+		Version:     transaction.Version,
+		AmountCents: transaction.AmountCents,
+		AccountID:   transaction.AccountID,
+		CategoryID:  transaction.CategoryID,
+		Title:       transaction.Title,
+		Date:        transaction.Date,
+		Attachment:  transaction.Attachment,
+		Note:        transaction.Note,
+		Status:      transaction.Status,
+		UserID:      userID,
+	})
+	if err != nil {
+		return database.HandleForeignKeyError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return database.ErrEditConflict
+	}
+
+	return nil
+}
+
+// DeleteByID deletes both legs of a transfer and its fee, which deletes the
+// transfer along with them.
+func (s *TransferService) DeleteByID(userID, transferID int32) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	transfer, err := getTransfer(ctx, qtx, userID, transferID)
+	if err != nil {
+		return err
+	}
+
+	err = checkTransferNotReconciled(transfer)
+	if err != nil {
+		return err
+	}
+
+	ids := []int32{transfer.From.ID, transfer.To.ID}
+	if transfer.Fee != nil {
+		ids = append(ids, transfer.Fee.ID)
+	}
+
+	attachmentKeys, err := deleteTransactionsUnchecked(ctx, qtx, userID, ids...)
+	if err != nil {
+		return err
+	}
+
+	err = updateBalances(ctx, qtx, userID, transfer.From.AccountID, transfer.To.AccountID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
+	return nil
+}
+
+// deleteTransactionsUnchecked deletes transactions bypassing the checks that
+// keep them from being deleted on their own, such as the legs of a transfer or
+// the adjustment of a reconciliation, and returns the files of their
+// attachments.
+func deleteTransactionsUnchecked(ctx context.Context, q store.Querier, userID int32, transactionIDs ...int32) ([]string, error) {
+	var attachmentKeys []string
+	for _, transactionID := range transactionIDs {
+		keys, err := q.GetTransactionAttachmentKeys(ctx, transactionID)
+		if err != nil {
+			return nil, err
+		}
+		attachmentKeys = append(attachmentKeys, keys...)
+
+		_, err = q.DeleteTransactionByID(ctx, store.DeleteTransactionByIDParams{
+			ID:     transactionID,
+			UserID: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return attachmentKeys, nil
+}
+
+// checkTransferNotReconciled fails with ErrReconciledTransaction if any of
+// the transactions of a transfer is reconciled.
+func checkTransferNotReconciled(transfer *TransferDetails) error {
+	transactions := []store.Transaction{transfer.From, transfer.To}
+	if transfer.Fee != nil {
+		transactions = append(transactions, *transfer.Fee)
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Status == store.TransactionStatusReconciled {
+			return ErrReconciledTransaction
+		}
+	}
+	return nil
+}
+
+// checkNotTransfer fails with ErrTransferTransaction if the transaction is a
+// leg or the fee of a transfer.
+func checkNotTransfer(ctx context.Context, q store.Querier, transactionID int32) error {
+	_, err := q.GetTransferByTransactionID(ctx, transactionID)
+	switch {
+	case err == nil:
+		return ErrTransferTransaction
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return err
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/Quak1/gokei/internal/blob"
 	"github.com/Quak1/gokei/internal/database"
 	"github.com/Quak1/gokei/internal/database/store"
 	"github.com/Quak1/gokei/pkg/utils"
@@ -42,11 +43,15 @@ func validateUser(v *validator.Validator, user *InputUser) {
 
 type UserService struct {
 	queries store.QuerierTx
+	DB      *sql.DB
+	blobs   blob.Store
 }
 
-func NewUserService(queries store.QuerierTx) *UserService {
+func NewUserService(queries store.QuerierTx, db *sql.DB, blobs blob.Store) *UserService {
 	return &UserService{
 		queries: queries,
+		DB:      db,
+		blobs:   blobs,
 	}
 }
 
@@ -107,7 +112,23 @@ func (s *UserService) DeleteByID(id int32) error {
 		return database.ErrRecordNotFound
 	}
 
-	result, err := s.queries.DeleteUserById(context.Background(), id)
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+	ctx := context.Background()
+
+	// The attachments go along with the user's transactions, but their files
+	// have to be removed once the rows are gone.
+	attachmentKeys, err := qtx.GetUserAttachmentKeys(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := qtx.DeleteUserById(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -121,6 +142,13 @@ func (s *UserService) DeleteByID(id int32) error {
 		return database.ErrRecordNotFound
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	removeBlobs(s.blobs, attachmentKeys)
+
 	return nil
 }
 
@@ -189,10 +217,11 @@ func (s *UserService) UpdateByID(id int32, updateParams *UpdateUserParams) (*sto
 	return &user, nil
 }
 
-func (s *UserService) GetForToken(token string) (*store.GetUserFromTokenRow, error) {
+func (s *UserService) GetForToken(scope, token string) (*store.GetUserFromTokenRow, error) {
 	user, err := s.queries.GetUserFromToken(context.Background(), store.GetUserFromTokenParams{
 		Hash:   HashToken(token),
 		Expiry: time.Now(),
+		Scope:  scope,
 	})
 	if err != nil {
 		switch {
@@ -0,0 +1,27 @@
+package testutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Quak1/gokei/internal/database/store"
+	"github.com/Quak1/gokei/internal/service"
+)
+
+func CreateTestRecurringTransaction(t *testing.T, svc *service.RecurringTransactionService, userID, accountID, categoryID int32) *store.RecurringTransaction {
+	t.Helper()
+
+	transaction, err := svc.Create(userID, &service.CreateRecurringTransactionParams{
+		AccountID:   accountID,
+		AmountCents: -1500,
+		CategoryID:  categoryID,
+		Title:       "Test Recurring Transaction",
+		Frequency:   store.RecurrenceFrequencyMonthly,
+		StartDate:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test recurring transaction: %v", err)
+	}
+
+	return transaction
+}
@@ -10,7 +10,7 @@ import (
 func CreateTestTransaction(t *testing.T, svc *service.TransactionService, userID, accountID, categoryID int32) *store.Transaction {
 	t.Helper()
 
-	transaction, err := svc.Create(userID, &store.CreateTransactionParams{
+	transaction, err := svc.Create(userID, &service.CreateTransactionParams{
 		Title:       "Test Transaction",
 		AccountID:   accountID,
 		AmountCents: 100000,
@@ -20,5 +20,5 @@ func CreateTestTransaction(t *testing.T, svc *service.TransactionService, userID
 		t.Fatalf("failed to create test transaction: %v", err)
 	}
 
-	return transaction
+	return &transaction.Transaction
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -46,3 +47,41 @@ func CreateGetRequest(t *testing.T, route string, user *store.User) *http.Reques
 
 	return req
 }
+
+// CreateUploadRequest builds a multipart/form-data request with file in a
+// "file" part and options, when not nil, as JSON in an "options" part.
+func CreateUploadRequest(t *testing.T, route string, file string, options any, user *store.User) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if options != nil {
+		encoded, err := json.Marshal(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.WriteField("options", string(encoded)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", "statement")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(part, file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, route, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req = appcontext.SetContextUser(req, &store.GetUserFromTokenRow{
+		ID:       user.ID,
+		Username: user.Username,
+	})
+
+	return req
+}
@@ -33,6 +33,14 @@ func ServerErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	ErrorResponse(w, r, http.StatusInternalServerError, message)
 }
 
+// AbortResponse logs err and aborts a response whose body is already partly
+// written, so that the client sees it cut short rather than an error appended
+// to it.
+func AbortResponse(r *http.Request, err error) {
+	logError(r, err)
+	panic(http.ErrAbortHandler)
+}
+
 func NotFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "The resource you're looking for doesn't exist."
 	ErrorResponse(w, r, http.StatusNotFound, message)
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE recurring_transaction_exceptions (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+    recurring_transaction_id INT NOT NULL REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+    occurrence_date DATE NOT NULL,
+
+    skip BOOLEAN NOT NULL DEFAULT false,
+    new_date DATE,
+    amount_cents INT,
+
+    UNIQUE(recurring_transaction_id, occurrence_date),
+    CONSTRAINT valid_exception CHECK (skip <> (new_date IS NOT NULL OR amount_cents IS NOT NULL))
+);
+
+-- +goose Down
+DROP TABLE recurring_transaction_exceptions;
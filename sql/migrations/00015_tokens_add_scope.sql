@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE tokens
+ADD scope TEXT NOT NULL DEFAULT 'authentication';
+
+-- +goose Down
+ALTER TABLE tokens
+DROP COLUMN scope;
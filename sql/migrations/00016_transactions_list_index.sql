@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX idx_transactions_account_date ON transactions(account_id, date, id);
+
+-- +goose Down
+DROP INDEX idx_transactions_account_date;
@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE INDEX idx_transactions_search ON transactions
+USING GIN (to_tsvector('english', title || ' ' || note));
+
+-- +goose Down
+DROP INDEX idx_transactions_search;
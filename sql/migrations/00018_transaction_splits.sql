@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE transaction_splits (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+  transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  category_id INT NOT NULL REFERENCES categories(id),
+  amount_cents BIGINT NOT NULL,
+  note TEXT NOT NULL
+);
+
+CREATE INDEX idx_transaction_splits_transaction ON transaction_splits(transaction_id);
+CREATE INDEX idx_transaction_splits_category ON transaction_splits(category_id);
+
+-- +goose Down
+DROP TABLE transaction_splits;
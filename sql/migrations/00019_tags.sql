@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE tags (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  version INT NOT NULL DEFAULT 1,
+
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+
+  UNIQUE(user_id, name)
+);
+
+CREATE TABLE transaction_tags (
+  transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+
+  PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX idx_transaction_tags_tag ON transaction_tags(tag_id);
+
+-- +goose Down
+DROP TABLE transaction_tags;
+DROP TABLE tags;
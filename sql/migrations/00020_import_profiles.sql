@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE import_profiles (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  version INT NOT NULL DEFAULT 1,
+
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+
+  delimiter TEXT NOT NULL DEFAULT ',',
+  has_header BOOLEAN NOT NULL DEFAULT true,
+  skip_rows INT NOT NULL DEFAULT 0,
+  date_column TEXT NOT NULL,
+  date_format TEXT NOT NULL,
+  amount_column TEXT NOT NULL DEFAULT '',
+  debit_column TEXT NOT NULL DEFAULT '',
+  credit_column TEXT NOT NULL DEFAULT '',
+  title_column TEXT NOT NULL,
+  note_column TEXT NOT NULL DEFAULT '',
+  decimal_separator TEXT NOT NULL DEFAULT '.',
+  sign_convention TEXT NOT NULL DEFAULT 'signed',
+
+  UNIQUE(user_id, name)
+);
+
+-- +goose Down
+DROP TABLE import_profiles;
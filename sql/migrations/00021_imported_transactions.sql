@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE imported_transactions (
+  transaction_id INT PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+  account_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  external_id TEXT NOT NULL,
+
+  UNIQUE(account_id, external_id)
+);
+
+-- +goose Down
+DROP TABLE imported_transactions;
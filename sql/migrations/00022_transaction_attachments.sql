@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE transaction_attachments (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+  transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  filename TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  storage_key TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_transaction_attachments_transaction ON transaction_attachments(transaction_id);
+
+-- +goose Down
+DROP TABLE transaction_attachments;
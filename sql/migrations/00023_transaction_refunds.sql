@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE transaction_refunds (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+  transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  refund_transaction_id INT NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+  reason TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_transaction_refunds_transaction ON transaction_refunds(transaction_id);
+
+-- Refunds made before they were linked only carry the original in their title.
+INSERT INTO transaction_refunds (transaction_id, refund_transaction_id)
+SELECT original.id, refund.id
+FROM transactions refund
+INNER JOIN transactions original
+  ON refund.title = '[REFUND #' || original.id || '] ' || original.title
+ AND refund.account_id = original.account_id
+ORDER BY refund.id;
+
+-- +goose Down
+DROP TABLE transaction_refunds;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE transfers (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+  from_transaction_id INT NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+  to_transaction_id INT NOT NULL UNIQUE REFERENCES transactions(id) ON DELETE CASCADE,
+  fee_transaction_id INT UNIQUE REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+-- Transfers made before they were linked are two rows with the same title,
+-- the sending one created right before the receiving one.
+INSERT INTO transfers (from_transaction_id, to_transaction_id)
+SELECT from_leg.id, to_leg.id
+FROM transactions from_leg
+INNER JOIN transactions to_leg
+  ON to_leg.id = from_leg.id + 1
+ AND to_leg.title = from_leg.title
+ AND to_leg.amount_cents = -from_leg.amount_cents
+WHERE from_leg.title LIKE '[TRANSFER] FROM %'
+  AND from_leg.amount_cents < 0
+ORDER BY from_leg.id;
+
+-- +goose Down
+DROP TABLE transfers;
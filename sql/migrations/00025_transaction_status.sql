@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TYPE transaction_status AS ENUM ('pending', 'cleared', 'reconciled');
+
+ALTER TABLE transactions
+ADD status transaction_status NOT NULL DEFAULT 'cleared';
+
+CREATE INDEX idx_transactions_account_status ON transactions(account_id, status);
+
+ALTER TABLE accounts
+ADD cleared_balance_cents BIGINT NOT NULL DEFAULT 0;
+
+-- Every existing transaction is cleared, so the cleared balance starts out
+-- as the full balance.
+UPDATE accounts
+SET cleared_balance_cents = balance_cents;
+
+-- +goose Down
+ALTER TABLE accounts
+DROP COLUMN cleared_balance_cents;
+
+DROP INDEX idx_transactions_account_status;
+
+ALTER TABLE transactions
+DROP COLUMN status;
+
+DROP TYPE transaction_status;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE reconciliations (
+  id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+
+  account_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+  statement_date TIMESTAMP NOT NULL,
+  statement_balance_cents BIGINT NOT NULL,
+  -- reconciled_balance_cents is what the reconciled transactions added up to
+  -- before the adjustment, which makes up the difference when there is one.
+  reconciled_balance_cents BIGINT NOT NULL,
+  difference_cents BIGINT NOT NULL,
+  adjustment_transaction_id INT UNIQUE REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_reconciliations_account ON reconciliations(account_id, statement_date);
+
+CREATE TABLE reconciliation_transactions (
+  reconciliation_id INT NOT NULL REFERENCES reconciliations(id) ON DELETE CASCADE,
+  transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+
+  PRIMARY KEY (reconciliation_id, transaction_id)
+);
+
+CREATE INDEX idx_reconciliation_transactions_transaction ON reconciliation_transactions(transaction_id);
+
+-- +goose Down
+DROP TABLE reconciliation_transactions;
+DROP TABLE reconciliations;
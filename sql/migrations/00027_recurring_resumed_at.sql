@@ -0,0 +1,9 @@
+-- +goose Up
+-- resumed_at is when a paused rule was last made active again. Occurrences
+-- scheduled before it fell in the pause and are never caught up.
+ALTER TABLE recurring_transactions
+ADD resumed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE recurring_transactions
+DROP COLUMN resumed_at;
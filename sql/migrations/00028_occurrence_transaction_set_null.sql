@@ -0,0 +1,18 @@
+-- +goose Up
+-- An occurrence outlives its transaction, so that a rule doesn't post again
+-- a date whose transaction was deleted.
+ALTER TABLE recurring_transaction_occurrences
+ALTER COLUMN transaction_id DROP NOT NULL,
+DROP CONSTRAINT recurring_transaction_occurrences_transaction_id_fkey,
+ADD CONSTRAINT recurring_transaction_occurrences_transaction_id_fkey
+  FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL;
+
+-- +goose Down
+DELETE FROM recurring_transaction_occurrences
+WHERE transaction_id IS NULL;
+
+ALTER TABLE recurring_transaction_occurrences
+ALTER COLUMN transaction_id SET NOT NULL,
+DROP CONSTRAINT recurring_transaction_occurrences_transaction_id_fkey,
+ADD CONSTRAINT recurring_transaction_occurrences_transaction_id_fkey
+  FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE;
@@ -1,6 +1,6 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (type, name, user_id, balance_cents) 
-VALUES ($1, $2, $3, $4)
+INSERT INTO accounts (type, name, user_id, balance_cents, cleared_balance_cents) 
+VALUES ($1, $2, $3, $4, $4)
 RETURNING *;
 
 -- name: GetAllAccounts :many
@@ -16,10 +16,21 @@ SET balance_cents = balance_cents + $1, updated_at = NOW()
 WHERE id = $2 AND user_id = $3
 RETURNING balance_cents;
 
+-- name: UpdateClearedBalance :one
+UPDATE accounts
+SET cleared_balance_cents = cleared_balance_cents + $1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+RETURNING cleared_balance_cents;
+
 -- name: GetAccountByID :one
 SELECT * FROM accounts
 WHERE id = $1 AND user_id = $2;
 
+-- name: GetAccountByIDForUpdate :one
+SELECT * FROM accounts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
 -- name: DeleteAccountById :execresult
 DELETE FROM accounts
 WHERE id = $1 AND user_id = $2;
@@ -38,13 +49,17 @@ WHERE id = $3 AND user_id = $4 AND version = $5;
 
 -- name: AutoUpdateBalance :execrows
 WITH new_balance AS (
-  SELECT accounts.id, SUM(transactions.amount_cents) AS balance
+  SELECT accounts.id,
+    SUM(transactions.amount_cents) AS balance,
+    SUM(transactions.amount_cents) FILTER (WHERE transactions.status <> 'pending') AS cleared_balance
   FROM accounts
   LEFT JOIN transactions ON transactions.account_id = accounts.id
   WHERE accounts.id = $1 AND accounts.user_id = $2
   GROUP BY accounts.id
 )
 UPDATE accounts
-SET balance_cents = new_balance.balance, updated_at = NOW()
+SET balance_cents = new_balance.balance,
+    cleared_balance_cents = COALESCE(new_balance.cleared_balance, 0),
+    updated_at = NOW()
 FROM new_balance
 WHERE accounts.id = $1 AND accounts.user_id = $2;
@@ -23,3 +23,32 @@ WHERE id = $1 AND user_id = $2;
 UPDATE categories
 SET name = $1, color = $2, icon = $3, version = version + 1, updated_at = NOW()
 WHERE id = $4 AND user_id = $5 AND version = $6;
+
+-- name: GetCategoryReport :many
+WITH lines AS (
+  SELECT transactions.category_id, transactions.amount_cents FROM transactions
+  INNER JOIN accounts ON transactions.account_id = accounts.id
+  WHERE accounts.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(date_from)::timestamp IS NULL OR transactions.date >= sqlc.narg(date_from)::timestamp)
+    AND (sqlc.narg(date_to)::timestamp IS NULL OR transactions.date < sqlc.narg(date_to)::timestamp)
+    AND NOT EXISTS (
+      SELECT 1 FROM transaction_splits WHERE transaction_splits.transaction_id = transactions.id
+    )
+  UNION ALL
+  SELECT transaction_splits.category_id, transaction_splits.amount_cents FROM transaction_splits
+  INNER JOIN transactions ON transaction_splits.transaction_id = transactions.id
+  INNER JOIN accounts ON transactions.account_id = accounts.id
+  WHERE accounts.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(date_from)::timestamp IS NULL OR transactions.date >= sqlc.narg(date_from)::timestamp)
+    AND (sqlc.narg(date_to)::timestamp IS NULL OR transactions.date < sqlc.narg(date_to)::timestamp)
+)
+SELECT categories.id AS category_id, categories.name,
+  COUNT(*) AS count,
+  COALESCE(SUM(lines.amount_cents) FILTER (WHERE lines.amount_cents > 0), 0)::bigint AS income_cents,
+  COALESCE(SUM(lines.amount_cents) FILTER (WHERE lines.amount_cents < 0), 0)::bigint AS expense_cents,
+  SUM(lines.amount_cents)::bigint AS total_cents
+FROM lines
+INNER JOIN categories ON lines.category_id = categories.id
+WHERE categories.id <> sqlc.arg(initial_category_id)
+GROUP BY categories.id
+ORDER BY categories.name, categories.id;
@@ -0,0 +1,27 @@
+-- name: CreateImportProfile :one
+INSERT INTO import_profiles (
+  user_id, name, delimiter, has_header, skip_rows, date_column, date_format, amount_column,
+  debit_column, credit_column, title_column, note_column, decimal_separator, sign_convention
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *;
+
+-- name: GetImportProfiles :many
+SELECT * FROM import_profiles
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetImportProfileByID :one
+SELECT * FROM import_profiles
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateImportProfileByID :execresult
+UPDATE import_profiles
+SET name = $1, delimiter = $2, has_header = $3, skip_rows = $4, date_column = $5, date_format = $6,
+  amount_column = $7, debit_column = $8, credit_column = $9, title_column = $10, note_column = $11,
+  decimal_separator = $12, sign_convention = $13, version = version + 1, updated_at = NOW()
+WHERE id = $14 AND user_id = $15 AND version = $16;
+
+-- name: DeleteImportProfileByID :execresult
+DELETE FROM import_profiles
+WHERE id = $1 AND user_id = $2;
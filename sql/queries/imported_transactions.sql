@@ -0,0 +1,8 @@
+-- name: CreateImportedTransaction :exec
+INSERT INTO imported_transactions (transaction_id, account_id, external_id)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetImportedExternalIDs :many
+SELECT external_id FROM imported_transactions
+WHERE account_id = $1 AND external_id = ANY(sqlc.arg(external_ids)::text[]);
@@ -0,0 +1,63 @@
+-- name: CreateReconciliation :one
+INSERT INTO reconciliations (account_id, statement_date, statement_balance_cents, reconciled_balance_cents, difference_cents, adjustment_transaction_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: AddReconciliationTransactions :exec
+INSERT INTO reconciliation_transactions (reconciliation_id, transaction_id)
+SELECT sqlc.arg(reconciliation_id), unnest(sqlc.arg(transaction_ids)::int[]);
+
+-- name: GetReconciliationByID :one
+SELECT reconciliations.* FROM reconciliations
+INNER JOIN accounts ON reconciliations.account_id = accounts.id
+WHERE reconciliations.id = $1 AND reconciliations.account_id = $2 AND accounts.user_id = $3;
+
+-- name: GetReconciliationTransactionIDs :many
+SELECT transaction_id FROM reconciliation_transactions
+WHERE reconciliation_id = $1
+ORDER BY transaction_id;
+
+-- name: ListReconciliations :many
+SELECT reconciliations.* FROM reconciliations
+INNER JOIN accounts ON reconciliations.account_id = accounts.id
+WHERE reconciliations.account_id = $1 AND accounts.user_id = $2
+ORDER BY reconciliations.statement_date DESC, reconciliations.id DESC;
+
+-- name: GetReconciledBalance :one
+SELECT COALESCE(SUM(amount_cents), 0)::bigint AS balance FROM transactions
+WHERE account_id = $1 AND status = 'reconciled';
+
+-- name: ReconcileTransactions :execrows
+UPDATE transactions
+SET status = 'reconciled',
+    version = version + 1,
+    updated_at = NOW()
+WHERE account_id = sqlc.arg(account_id)
+  AND id = ANY(sqlc.arg(ids)::int[])
+  AND status = 'cleared';
+
+-- name: GetUserReconciliations :many
+SELECT reconciliations.* FROM reconciliations
+INNER JOIN accounts ON reconciliations.account_id = accounts.id
+WHERE accounts.user_id = $1
+ORDER BY reconciliations.id;
+
+-- name: GetUserReconciliationTransactions :many
+SELECT reconciliation_transactions.* FROM reconciliation_transactions
+INNER JOIN reconciliations ON reconciliation_transactions.reconciliation_id = reconciliations.id
+INNER JOIN accounts ON reconciliations.account_id = accounts.id
+WHERE accounts.user_id = $1
+ORDER BY reconciliation_transactions.reconciliation_id, reconciliation_transactions.transaction_id;
+
+-- name: DeleteReconciliation :exec
+DELETE FROM reconciliations
+WHERE id = $1;
+
+-- name: UnreconcileTransactions :exec
+UPDATE transactions
+SET status = 'cleared',
+    version = version + 1,
+    updated_at = NOW()
+WHERE account_id = sqlc.arg(account_id)
+  AND id = ANY(sqlc.arg(ids)::int[])
+  AND status = 'reconciled';
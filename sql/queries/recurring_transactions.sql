@@ -12,8 +12,9 @@ INSERT INTO recurring_transactions (
     day_month,
     day_week,
     max_occurrences,
-    is_active
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    is_active,
+    resumed_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING *;
 
 -- name: GetUserRecurringTransactions :many
@@ -31,8 +32,13 @@ SELECT rt.* FROM recurring_transactions rt
 INNER JOIN accounts ON rt.account_id = accounts.id
 WHERE accounts.user_id = $1 
   AND rt.is_active = true
-  AND rt.start_date <= $2
-  AND (rt.end_date IS NULL OR rt.end_date >= $2);
+  AND rt.start_date <= sqlc.arg(now)::timestamp
+  AND (rt.end_date IS NULL OR rt.end_date >= sqlc.arg(ended_after)::timestamp);
+
+-- name: LockRecurringTransaction :one
+SELECT * FROM recurring_transactions
+WHERE id = $1
+FOR UPDATE SKIP LOCKED;
 
 -- name: UpdateRecurringTransaction :execresult
 UPDATE recurring_transactions
@@ -47,13 +53,14 @@ SET amount_cents = $1,
     day_week = $9,
     max_occurrences = $10,
     is_active = $11,
-    version = version + 1,
+    resumed_at = $12,
+    version = recurring_transactions.version + 1,
     updated_at = NOW()
 FROM accounts
 WHERE recurring_transactions.account_id = accounts.id
-  AND recurring_transactions.id = $12
-  AND accounts.user_id = $13
-  AND recurring_transactions.version = $14;
+  AND recurring_transactions.id = $13
+  AND accounts.user_id = $14
+  AND recurring_transactions.version = $15;
 
 -- name: DeleteRecurringTransaction :execresult
 DELETE FROM recurring_transactions
@@ -79,7 +86,32 @@ SELECT * FROM recurring_transaction_occurrences
 WHERE recurring_transaction_id = $1 AND occurrence_date = $2;
 
 -- name: GetLastOccurrence :one
-SELECT * FROM recurring_transaction_occurrences
-WHERE recurring_transaction_id = $1
-ORDER BY occurrence_date DESC
+SELECT o.* FROM recurring_transaction_occurrences o
+WHERE o.recurring_transaction_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM recurring_transaction_exceptions e
+    WHERE e.recurring_transaction_id = o.recurring_transaction_id
+      AND e.occurrence_date = o.occurrence_date
+      AND e.new_date IS NOT NULL
+  )
+ORDER BY o.occurrence_date DESC
 LIMIT 1;
+
+-- name: CreateRecurringTransactionException :one
+INSERT INTO recurring_transaction_exceptions (
+    recurring_transaction_id,
+    occurrence_date,
+    skip,
+    new_date,
+    amount_cents
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetRecurringTransactionExceptions :many
+SELECT * FROM recurring_transaction_exceptions
+WHERE recurring_transaction_id = $1
+ORDER BY occurrence_date;
+
+-- name: DeleteRecurringTransactionException :execresult
+DELETE FROM recurring_transaction_exceptions
+WHERE id = $1 AND recurring_transaction_id = $2;
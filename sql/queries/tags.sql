@@ -0,0 +1,62 @@
+-- name: CreateTag :one
+INSERT INTO tags (user_id, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: UpsertTag :one
+INSERT INTO tags (user_id, name)
+VALUES ($1, $2)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: GetUserTags :many
+SELECT * FROM tags
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetTagByID :one
+SELECT * FROM tags
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateTagByID :execresult
+UPDATE tags
+SET name = $1, version = version + 1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3 AND version = $4;
+
+-- name: DeleteTagByID :execresult
+DELETE FROM tags
+WHERE id = $1 AND user_id = $2;
+
+-- name: AddTransactionTag :exec
+INSERT INTO transaction_tags (transaction_id, tag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTransactionTags :exec
+DELETE FROM transaction_tags
+WHERE transaction_id = $1;
+
+-- name: GetTagsForTransactions :many
+SELECT transaction_tags.transaction_id, sqlc.embed(tags) FROM transaction_tags
+INNER JOIN tags ON transaction_tags.tag_id = tags.id
+WHERE transaction_tags.transaction_id = ANY(sqlc.arg(transaction_ids)::int[])
+ORDER BY transaction_tags.transaction_id, tags.name;
+
+-- name: GetTagReport :many
+SELECT tags.id AS tag_id, tags.name,
+  COUNT(*) AS count,
+  COALESCE(SUM(transactions.amount_cents) FILTER (WHERE transactions.amount_cents > 0), 0)::bigint AS income_cents,
+  COALESCE(SUM(transactions.amount_cents) FILTER (WHERE transactions.amount_cents < 0), 0)::bigint AS expense_cents,
+  SUM(transactions.amount_cents)::bigint AS total_cents
+FROM tags
+INNER JOIN transaction_tags ON transaction_tags.tag_id = tags.id
+INNER JOIN transactions ON transaction_tags.transaction_id = transactions.id
+WHERE tags.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(date_from)::timestamp IS NULL OR transactions.date >= sqlc.narg(date_from)::timestamp)
+  AND (sqlc.narg(date_to)::timestamp IS NULL OR transactions.date < sqlc.narg(date_to)::timestamp)
+  AND NOT EXISTS (
+    SELECT 1 FROM transfers
+    WHERE transactions.id IN (transfers.from_transaction_id, transfers.to_transaction_id)
+  )
+GROUP BY tags.id
+ORDER BY tags.name, tags.id;
@@ -1,4 +1,4 @@
 -- name: CreateToken :one
-INSERT INTO tokens (hash, user_id, expiry)
-VALUES ($1, $2, $3)
+INSERT INTO tokens (hash, user_id, expiry, scope)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
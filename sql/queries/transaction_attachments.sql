@@ -0,0 +1,34 @@
+-- name: CreateTransactionAttachment :one
+INSERT INTO transaction_attachments (transaction_id, filename, content_type, size_bytes, storage_key)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTransactionAttachments :many
+SELECT * FROM transaction_attachments
+WHERE transaction_id = $1
+ORDER BY id;
+
+-- name: GetTransactionAttachmentByID :one
+SELECT * FROM transaction_attachments
+WHERE id = $1 AND transaction_id = $2;
+
+-- name: DeleteTransactionAttachment :one
+DELETE FROM transaction_attachments
+WHERE id = $1 AND transaction_id = $2
+RETURNING storage_key;
+
+-- name: GetTransactionAttachmentKeys :many
+SELECT storage_key FROM transaction_attachments
+WHERE transaction_id = $1;
+
+-- name: GetAccountAttachmentKeys :many
+SELECT transaction_attachments.storage_key FROM transaction_attachments
+INNER JOIN transactions ON transaction_attachments.transaction_id = transactions.id
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.id = $1 AND accounts.user_id = $2;
+
+-- name: GetUserAttachmentKeys :many
+SELECT transaction_attachments.storage_key FROM transaction_attachments
+INNER JOIN transactions ON transaction_attachments.transaction_id = transactions.id
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = $1;
@@ -0,0 +1,23 @@
+-- name: CreateTransactionRefund :one
+INSERT INTO transaction_refunds (transaction_id, refund_transaction_id, reason)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTransactionRefunds :many
+SELECT transaction_refunds.id, transaction_refunds.refund_transaction_id, transaction_refunds.reason,
+       transactions.amount_cents, transactions.date
+FROM transaction_refunds
+INNER JOIN transactions ON transaction_refunds.refund_transaction_id = transactions.id
+WHERE transaction_refunds.transaction_id = $1
+ORDER BY transaction_refunds.id;
+
+-- name: GetTransactionRefundOf :one
+SELECT * FROM transaction_refunds
+WHERE refund_transaction_id = $1;
+
+-- name: GetUserTransactionRefunds :many
+SELECT transaction_refunds.* FROM transaction_refunds
+INNER JOIN transactions ON transaction_refunds.transaction_id = transactions.id
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = $1
+ORDER BY transaction_refunds.id;
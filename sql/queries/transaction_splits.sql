@@ -0,0 +1,18 @@
+-- name: CreateTransactionSplit :one
+INSERT INTO transaction_splits (transaction_id, category_id, amount_cents, note)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTransactionSplits :many
+SELECT * FROM transaction_splits
+WHERE transaction_id = $1
+ORDER BY id;
+
+-- name: GetTransactionSplitsForTransactions :many
+SELECT * FROM transaction_splits
+WHERE transaction_id = ANY(sqlc.arg(transaction_ids)::int[])
+ORDER BY transaction_id, id;
+
+-- name: DeleteTransactionSplits :exec
+DELETE FROM transaction_splits
+WHERE transaction_id = $1;
@@ -1,6 +1,11 @@
 -- name: CreateTransaction :one
-INSERT INTO transactions (account_id, amount_cents, category_id, title, attachment, note)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO transactions (account_id, amount_cents, category_id, title, attachment, note, status)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE(sqlc.narg(status)::transaction_status, 'cleared'))
+RETURNING *;
+
+-- name: CreateTransactionWithDate :one
+INSERT INTO transactions (account_id, amount_cents, category_id, title, attachment, note, date, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(sqlc.narg(status)::transaction_status, 'cleared'))
 RETURNING *;
 
 -- name: GetAllTransactions :many
@@ -8,6 +13,61 @@ SELECT sqlc.embed(transactions) FROM transactions
 INNER JOIN accounts ON transactions.account_id = accounts.id
 WHERE accounts.user_id = $1;
 
+-- name: ListTransactions :many
+SELECT sqlc.embed(transactions) FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = sqlc.arg(user_id)
+  AND (coalesce(cardinality(sqlc.arg(account_ids)::int[]), 0) = 0 OR transactions.account_id = ANY(sqlc.arg(account_ids)::int[]))
+  AND (coalesce(cardinality(sqlc.arg(category_ids)::int[]), 0) = 0 OR transactions.category_id = ANY(sqlc.arg(category_ids)::int[]))
+  AND (coalesce(cardinality(sqlc.arg(tag_ids)::int[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM transaction_tags
+    WHERE transaction_tags.transaction_id = transactions.id
+      AND transaction_tags.tag_id = ANY(sqlc.arg(tag_ids)::int[])
+  ))
+  AND (sqlc.narg(date_from)::timestamp IS NULL OR transactions.date >= sqlc.narg(date_from)::timestamp)
+  AND (sqlc.narg(date_to)::timestamp IS NULL OR transactions.date < sqlc.narg(date_to)::timestamp)
+  AND (sqlc.narg(min_amount_cents)::bigint IS NULL OR transactions.amount_cents >= sqlc.narg(min_amount_cents)::bigint)
+  AND (sqlc.narg(max_amount_cents)::bigint IS NULL OR transactions.amount_cents <= sqlc.narg(max_amount_cents)::bigint)
+  AND (sqlc.narg(sign)::int IS NULL OR (sign(transactions.amount_cents) = sqlc.narg(sign)::int AND NOT EXISTS (
+    SELECT 1 FROM transfers
+    WHERE transactions.id IN (transfers.from_transaction_id, transfers.to_transaction_id)
+  )))
+  AND (sqlc.narg(status)::transaction_status IS NULL OR transactions.status = sqlc.narg(status)::transaction_status)
+  AND (sqlc.narg(cursor_id)::int IS NULL OR CASE
+    WHEN sqlc.arg(sort)::text = 'amount' AND sqlc.arg(descending)::bool
+      THEN (transactions.amount_cents, transactions.id) < (sqlc.narg(cursor_amount_cents)::bigint, sqlc.narg(cursor_id)::int)
+    WHEN sqlc.arg(sort)::text = 'amount'
+      THEN (transactions.amount_cents, transactions.id) > (sqlc.narg(cursor_amount_cents)::bigint, sqlc.narg(cursor_id)::int)
+    WHEN sqlc.arg(descending)::bool
+      THEN (transactions.date, transactions.id) < (sqlc.narg(cursor_date)::timestamp, sqlc.narg(cursor_id)::int)
+    ELSE (transactions.date, transactions.id) > (sqlc.narg(cursor_date)::timestamp, sqlc.narg(cursor_id)::int)
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg(sort)::text = 'amount' AND NOT sqlc.arg(descending)::bool THEN transactions.amount_cents END ASC,
+  CASE WHEN sqlc.arg(sort)::text = 'amount' AND sqlc.arg(descending)::bool THEN transactions.amount_cents END DESC,
+  CASE WHEN sqlc.arg(sort)::text <> 'amount' AND NOT sqlc.arg(descending)::bool THEN transactions.date END ASC,
+  CASE WHEN sqlc.arg(sort)::text <> 'amount' AND sqlc.arg(descending)::bool THEN transactions.date END DESC,
+  CASE WHEN NOT sqlc.arg(descending)::bool THEN transactions.id END ASC,
+  CASE WHEN sqlc.arg(descending)::bool THEN transactions.id END DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: SearchTransactions :many
+SELECT sqlc.embed(transactions),
+  ts_rank(
+    setweight(to_tsvector('english', transactions.title), 'A') ||
+    setweight(to_tsvector('english', transactions.note), 'B'),
+    to_tsquery('english', sqlc.arg(query))
+  )::real AS rank
+FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = sqlc.arg(user_id)
+  AND to_tsvector('english', transactions.title || ' ' || transactions.note) @@ to_tsquery('english', sqlc.arg(query))
+  AND (coalesce(cardinality(sqlc.arg(account_ids)::int[]), 0) = 0 OR transactions.account_id = ANY(sqlc.arg(account_ids)::int[]))
+  AND (sqlc.narg(date_from)::timestamp IS NULL OR transactions.date >= sqlc.narg(date_from)::timestamp)
+  AND (sqlc.narg(date_to)::timestamp IS NULL OR transactions.date < sqlc.narg(date_to)::timestamp)
+ORDER BY rank DESC, transactions.date DESC, transactions.id DESC
+LIMIT sqlc.arg(max_results);
+
 -- name: GetTransactionsByAccountID :many
 SELECT sqlc.embed(transactions) FROM transactions
 INNER JOIN accounts ON transactions.account_id = accounts.id
@@ -18,6 +78,17 @@ SELECT sqlc.embed(transactions) FROM transactions
 INNER JOIN accounts ON transactions.account_id = accounts.id
 WHERE transactions.id = $1 AND accounts.user_id = $2;
 
+-- name: GetTransactionByIDForUpdate :one
+SELECT sqlc.embed(transactions) FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE transactions.id = $1 AND accounts.user_id = $2
+FOR UPDATE OF transactions;
+
+-- name: GetTransactionsByIDs :many
+SELECT sqlc.embed(transactions) FROM transactions
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE transactions.id = ANY(sqlc.arg(ids)::int[]) AND accounts.user_id = sqlc.arg(user_id);
+
 -- name: DeleteTransactionByID :execresult
 DELETE FROM transactions
 USING accounts
@@ -34,10 +105,19 @@ SET amount_cents = $1,
     date = $5,
     attachment = $6,
     note = $7,
+    status = $8,
     version = transactions.version + 1,
     updated_at = NOW()
 FROM accounts
 WHERE transactions.account_id = accounts.id
-  AND transactions.id = $8
-  AND accounts.user_id = $9
-  AND transactions.version = $10;
+  AND transactions.id = $9
+  AND accounts.user_id = $10
+  AND transactions.version = $11;
+
+-- name: GetDuplicateCandidates :many
+SELECT * FROM transactions
+WHERE account_id = sqlc.arg(account_id)
+  AND date >= sqlc.arg(date_from)::timestamp
+  AND date <= sqlc.arg(date_to)::timestamp
+  AND amount_cents = ANY(sqlc.arg(amounts)::bigint[])
+ORDER BY date, id;
@@ -0,0 +1,51 @@
+-- name: CreateTransfer :one
+INSERT INTO transfers (from_transaction_id, to_transaction_id, fee_transaction_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTransferByID :one
+SELECT transfers.* FROM transfers
+INNER JOIN transactions ON transfers.from_transaction_id = transactions.id
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE transfers.id = $1 AND accounts.user_id = $2;
+
+-- name: GetTransferByTransactionID :one
+SELECT * FROM transfers
+WHERE from_transaction_id = sqlc.arg(transaction_id)
+   OR to_transaction_id = sqlc.arg(transaction_id)
+   OR fee_transaction_id = sqlc.arg(transaction_id);
+
+-- name: GetTransferIDsForTransactions :many
+SELECT transactions.id AS transaction_id, transfers.id AS transfer_id FROM transfers
+INNER JOIN transactions ON transactions.id IN (transfers.from_transaction_id, transfers.to_transaction_id, transfers.fee_transaction_id)
+WHERE transactions.id = ANY(sqlc.arg(transaction_ids)::int[]);
+
+-- name: GetTransferAccountNames :many
+SELECT leg.id AS transaction_id, accounts.name AS account_name FROM transfers
+INNER JOIN transactions leg ON leg.id IN (transfers.from_transaction_id, transfers.to_transaction_id)
+INNER JOIN transactions other_leg ON other_leg.id IN (transfers.from_transaction_id, transfers.to_transaction_id) AND other_leg.id <> leg.id
+INNER JOIN accounts ON other_leg.account_id = accounts.id
+WHERE leg.account_id = $1 AND accounts.user_id = $2;
+
+-- name: ListTransfers :many
+SELECT transfers.* FROM transfers
+INNER JOIN transactions from_leg ON transfers.from_transaction_id = from_leg.id
+INNER JOIN transactions to_leg ON transfers.to_transaction_id = to_leg.id
+INNER JOIN accounts ON from_leg.account_id = accounts.id
+WHERE accounts.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(account_id)::int IS NULL OR sqlc.narg(account_id)::int IN (from_leg.account_id, to_leg.account_id))
+  AND (sqlc.narg(date_from)::timestamp IS NULL OR from_leg.date >= sqlc.narg(date_from)::timestamp)
+  AND (sqlc.narg(date_to)::timestamp IS NULL OR from_leg.date < sqlc.narg(date_to)::timestamp)
+ORDER BY from_leg.date DESC, transfers.id DESC;
+
+-- name: SetTransferFee :exec
+UPDATE transfers
+SET fee_transaction_id = $2
+WHERE id = $1;
+
+-- name: GetUserTransfers :many
+SELECT transfers.* FROM transfers
+INNER JOIN transactions ON transfers.from_transaction_id = transactions.id
+INNER JOIN accounts ON transactions.account_id = accounts.id
+WHERE accounts.user_id = $1
+ORDER BY transfers.id;
@@ -20,7 +20,8 @@ FROM users
 INNER JOIN tokens
 ON users.id = tokens.user_id
 WHERE tokens.hash = $1
-AND tokens.expiry > $2;
+AND tokens.expiry > $2
+AND tokens.scope = $3;
 
 -- name: DeleteUserById :execresult
 DELETE FROM users